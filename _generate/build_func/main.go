@@ -120,14 +120,11 @@ func main() {
 	data.PackageName = packageName
 
 	data.ImportsArr = make([]string, 0)
-	data.ImportsArr = append(data.ImportsArr, "errors")
-	data.ImportsArr = append(data.ImportsArr, "fmt")
 	data.ImportsArr = append(data.ImportsArr, "github.com/voluminor/lightweigit-loader")
-	data.ImportsArr = append(data.ImportsArr, "github.com/voluminor/lightweigit-loader/target")
 
 	data.Dirs = dirs
 	for _, dir := range dirs {
-		data.ImportsArr = append(data.ImportsArr, "github.com/voluminor/lightweigit-loader/"+dir)
+		data.ImportsArr = append(data.ImportsArr, "_ github.com/voluminor/lightweigit-loader/"+dir)
 	}
 
 	os.MkdirAll(filepath.Join("target", packageName), 0777)
@@ -24,6 +24,9 @@ const (
 
 	fRelease  = "func_release.go"
 	ptRelease = "ReleaseLatest() (lightweigit.ProviderReleaseInterface, error)"
+
+	fBranch  = "func_branch.go"
+	ptBranch = "BranchDefault() (lightweigit.ProviderBranchInterface, error)"
 )
 
 var (
@@ -105,7 +108,15 @@ type TemplateObj struct {
 	Path           string
 	ImportsArr     []string
 
-	Mods []string
+	Mods       []string
+	BranchMods []BranchModObj
+}
+
+// BranchModObj is a branch mod byte: ModBranchMin plus the index of the
+// provider directory, whether or not its neighbours have branches.
+type BranchModObj struct {
+	Name   string
+	Offset int
 }
 
 // //
@@ -134,6 +145,18 @@ func main() {
 		}
 	}
 
+	data.BranchMods = make([]BranchModObj, 0)
+	for i, dir := range dirs {
+		ok, err := fileContains(filepath.Join(dir, fBranch), ptBranch)
+		if err != nil || !ok {
+			continue
+		}
+		data.BranchMods = append(data.BranchMods, BranchModObj{
+			Name:   strings.Title(dir) + "Branch",
+			Offset: i,
+		})
+	}
+
 	err = dep.WriteFileFromTemplate(filepath.Join("target", fileName), template_text, data)
 	if err != nil {
 		log.Println("An error when trying to save a generated file:", err)
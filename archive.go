@@ -0,0 +1,54 @@
+package lightweigit
+
+import (
+	"net/url"
+)
+
+// // // // // // // // // // // // // // // //
+
+// ArchiveFormatType is a source archive format.
+type ArchiveFormatType byte
+
+const (
+	ArchiveZIP ArchiveFormatType = iota
+	ArchiveTarGz
+	ArchiveTarBz2
+)
+
+func (f ArchiveFormatType) String() string {
+	switch f {
+	case ArchiveZIP:
+		return "zip"
+	case ArchiveTarGz:
+		return "tar.gz"
+	case ArchiveTarBz2:
+		return "tar.bz2"
+	}
+	return "unknown"
+}
+
+// Ext is the file extension of the format, with its leading dot.
+func (f ArchiveFormatType) Ext() string {
+	return "." + f.String()
+}
+
+// ParseArchiveFormat is the inverse of ArchiveFormatType.String; "tgz" and
+// "tbz2" are accepted as well.
+func ParseArchiveFormat(s string) (ArchiveFormatType, bool) {
+	switch s {
+	case "zip":
+		return ArchiveZIP, true
+	case "tar.gz", "tgz":
+		return ArchiveTarGz, true
+	case "tar.bz2", "tbz2":
+		return ArchiveTarBz2, true
+	}
+	return 0, false
+}
+
+// ProviderArchiveInterface is implemented by providers that serve source
+// archives of any ref: a branch, a tag or a commit SHA. Formats the host
+// does not produce give ErrUnsupported.
+type ProviderArchiveInterface interface {
+	Archive(ref string, format ArchiveFormatType) (*url.URL, error)
+}
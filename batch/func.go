@@ -0,0 +1,203 @@
+package batch
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+// URLs makes items of repository URLs.
+func URLs(raw ...string) []ItemObj {
+	items := make([]ItemObj, len(raw))
+	for i, u := range raw {
+		items[i] = ItemObj{URL: u}
+	}
+	return items
+}
+
+// Providers makes items of providers already parsed.
+func Providers(list ...lightweigit.ProviderInterface) []ItemObj {
+	items := make([]ItemObj, len(list))
+	for i, p := range list {
+		items[i] = ItemObj{Provider: p}
+	}
+	return items
+}
+
+// //
+
+// Do parses every item that is a URL and runs fn on it, spread over the
+// hosts within the limits of opts. The results are in input order, one
+// per item; a failed item does not stop the others. Once ctx is done, the
+// items not yet started fail with its error.
+func Do[T any](ctx context.Context, opts OptionsObj, items []ItemObj, fn func(context.Context, lightweigit.ProviderInterface) (T, error)) []ResultObj[T] {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	opts = opts.withDefaults()
+
+	results := make([]ResultObj[T], len(items))
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	finish := func(i int, r ResultObj[T]) {
+		results[i] = r
+		if opts.OnProgress == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		opts.OnProgress(ProgressObj{Done: done, Total: len(items), Index: i, URL: r.URL, Err: r.Err})
+	}
+
+	// Items are queued per host, in input order, and each host gets at
+	// most PerHost workers, so a busy or paused host holds up only its own
+	// items. The shared slots cap the total.
+	queues := make(map[string][]int)
+	var hosts []string
+	for i, it := range items {
+		if it.Provider == nil && strings.TrimSpace(it.URL) == "" {
+			finish(i, ResultObj[T]{Index: i, Err: ErrEmptyItem})
+			continue
+		}
+		h := hostOf(it)
+		if _, ok := queues[h]; !ok {
+			hosts = append(hosts, h)
+		}
+		queues[h] = append(queues[h], i)
+	}
+
+	slots := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for _, name := range hosts {
+		queue := make(chan int, len(queues[name]))
+		for _, i := range queues[name] {
+			queue <- i
+		}
+		close(queue)
+
+		h := &hostObj{}
+		workers := opts.PerHost
+		if n := len(queues[name]); n < workers {
+			workers = n
+		}
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				for i := range queue {
+					finish(i, lookup(ctx, opts, h, slots, i, items[i], fn))
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	return results
+}
+
+// ReleaseLatest runs ReleaseLatest on every item.
+func ReleaseLatest(ctx context.Context, opts OptionsObj, items []ItemObj) []ResultObj[lightweigit.ProviderReleaseInterface] {
+	return Do(ctx, opts, items, func(_ context.Context, p lightweigit.ProviderInterface) (lightweigit.ProviderReleaseInterface, error) {
+		return p.ReleaseLatest()
+	})
+}
+
+// TagLatest runs TagLatest on every item.
+func TagLatest(ctx context.Context, opts OptionsObj, items []ItemObj) []ResultObj[lightweigit.ProviderTagInterface] {
+	return Do(ctx, opts, items, func(_ context.Context, p lightweigit.ProviderInterface) (lightweigit.ProviderTagInterface, error) {
+		return p.TagLatest()
+	})
+}
+
+// // // //
+
+// lookup parses and queries one item, trying again after the host's
+// pause while the answer is a rate limit.
+func lookup[T any](ctx context.Context, opts OptionsObj, h *hostObj, slots chan struct{}, i int, it ItemObj, fn func(context.Context, lightweigit.ProviderInterface) (T, error)) ResultObj[T] {
+	r := ResultObj[T]{Index: i, URL: it.URL, Provider: it.Provider}
+	if r.URL == "" && r.Provider != nil {
+		if u := r.Provider.URL(); u != nil {
+			r.URL = u.String()
+		}
+	}
+
+	for try := 0; ; try++ {
+		if err := h.wait(ctx, opts.Interval); err != nil {
+			r.Err = err
+			return r
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			r.Err = ctx.Err()
+			return r
+		}
+
+		var err error
+		if r.Provider == nil {
+			r.Provider, err = parse(opts, it.URL)
+		}
+		if err == nil {
+			r.Value, err = fn(ctx, r.Provider)
+		}
+		<-slots
+
+		r.Err = err
+		if !lightweigit.IsRateLimit(err) {
+			h.clear()
+			return r
+		}
+		if try >= opts.Retries {
+			return r
+		}
+		h.hit(opts)
+	}
+}
+
+func parse(opts OptionsObj, raw string) (lightweigit.ProviderInterface, error) {
+	if opts.Offline {
+		return global.ParseOffline(raw)
+	}
+	return global.Parse(raw)
+}
+
+func hostOf(it ItemObj) string {
+	if it.Provider != nil {
+		return lightweigit.NormalizeHost(lightweigit.HostOf(it.Provider.Domain()))
+	}
+	return lightweigit.NormalizeHost(lightweigit.HostOf(it.URL))
+}
+
+func (opts OptionsObj) withDefaults() OptionsObj {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	if opts.PerHost <= 0 {
+		opts.PerHost = defaultPerHost
+	}
+	if opts.PerHost > opts.Concurrency {
+		opts.PerHost = opts.Concurrency
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	} else if opts.Retries == 0 {
+		opts.Retries = defaultRetries
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBackoff
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = defaultMaxBackoff
+		if opts.MaxBackoff < opts.Backoff {
+			opts.MaxBackoff = opts.Backoff
+		}
+	}
+	return opts
+}
@@ -0,0 +1,60 @@
+package batch
+
+import (
+	"context"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+// wait blocks until the host is neither paused nor, with interval set,
+// started a lookup less than interval ago, and books the start.
+func (h *hostObj) wait(ctx context.Context, interval time.Duration) error {
+	for {
+		h.mu.Lock()
+		now := time.Now()
+		at := h.next
+		if h.until.After(at) {
+			at = h.until
+		}
+		if !at.After(now) {
+			h.next = now.Add(interval)
+			h.mu.Unlock()
+			return ctx.Err()
+		}
+		h.mu.Unlock()
+
+		t := time.NewTimer(at.Sub(now))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// hit pauses the host after a rate-limit answer. Lookups that were in
+// flight when the pause began report the same limit; they do not extend
+// it.
+func (h *hostObj) hit(opts OptionsObj) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	if now.Before(h.until) {
+		return
+	}
+	if h.delay == 0 {
+		h.delay = opts.Backoff
+	} else if h.delay *= 2; h.delay > opts.MaxBackoff {
+		h.delay = opts.MaxBackoff
+	}
+	h.until = now.Add(h.delay)
+}
+
+// clear resets the backoff once the host answers again.
+func (h *hostObj) clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.delay = 0
+}
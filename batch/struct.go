@@ -0,0 +1,75 @@
+package batch
+
+import (
+	"sync"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// ItemObj is one repository to look up, by URL or as a provider already
+// parsed. Provider wins when both are set.
+type ItemObj struct {
+	URL      string
+	Provider lightweigit.ProviderInterface
+}
+
+// ResultObj is the outcome of one item. Index is the item's position in
+// the input; Provider is nil when the URL could not be parsed.
+type ResultObj[T any] struct {
+	Index    int
+	URL      string
+	Provider lightweigit.ProviderInterface
+	Value    T
+	Err      error
+}
+
+// ProgressObj is passed to OnProgress each time an item is finished.
+type ProgressObj struct {
+	Done  int
+	Total int
+
+	Index int
+	URL   string
+	Err   error
+}
+
+// OptionsObj configures a batch. Zero fields take the defaults in
+// values.go.
+type OptionsObj struct {
+	// Concurrency caps the lookups in flight over all hosts, PerHost
+	// those against one host.
+	Concurrency int
+	PerHost     int
+
+	// Interval is the least time between two lookups starting against the
+	// same host, for a steady request rate below the host's limit.
+	Interval time.Duration
+
+	// Retries is how often a rate-limited lookup is tried again. A
+	// rate-limit answer pauses the whole host, from Backoff doubling up
+	// to MaxBackoff, since the limit is per account rather than per
+	// repository. A negative Retries gives up at the first rate limit.
+	Retries    int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Offline parses URLs with global.ParseOffline instead of global.Parse.
+	Offline bool
+
+	// OnProgress is called after each item, one call at a time.
+	OnProgress func(ProgressObj)
+}
+
+// //
+
+// hostObj is what the lookups against one host share: the start time of
+// the next one and the rate-limit pause.
+type hostObj struct {
+	mu    sync.Mutex
+	next  time.Time
+	until time.Time
+	delay time.Duration
+}
@@ -0,0 +1,28 @@
+package batch
+
+import (
+	"errors"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+const (
+	// defaultConcurrency caps the lookups in flight over all hosts.
+	defaultConcurrency = 16
+
+	// defaultPerHost caps the lookups in flight against one host.
+	defaultPerHost = 4
+
+	// defaultRetries is how often a rate-limited lookup is tried again.
+	defaultRetries = 3
+
+	// defaultBackoff is the first pause of a rate-limited host; it doubles
+	// up to defaultMaxBackoff while the host keeps refusing.
+	defaultBackoff    = 15 * time.Second
+	defaultMaxBackoff = 5 * time.Minute
+)
+
+var (
+	ErrEmptyItem = errors.New("item without URL or provider")
+)
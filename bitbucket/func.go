@@ -1,6 +1,7 @@
 package bitbucket
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -23,6 +24,66 @@ func (obj *Obj) getJSONAny(u string, out any) error {
 	return obj.getJSON(u, out)
 }
 
+// pageObj is one page of a Bitbucket collection.
+type pageObj[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+// pageLen is the pagelen to ask for when at most limit items are wanted.
+func pageLen(limit int) int {
+	if limit > 0 && limit < 50 {
+		return limit
+	}
+	return 50
+}
+
+// streamCursor walks the collection at u page by page, following the
+// `next` cursor, and hands at most limit items (0: all) to emit.
+//
+// No adaptive page shrink here: Bitbucket paginates via opaque `next` cursor
+// URLs with pagelen baked in, so a mid-stream window remap is not possible.
+// The 50 default plus the 8 MiB GetJSON cap keeps pages within bounds.
+func streamCursor[T any](ctx context.Context, obj *Obj, u string, limit int, emit func(T) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sent := 0
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var pr pageObj[T]
+		if err := obj.getJSONAny(u, &pr); err != nil {
+			return err
+		}
+		if len(pr.Values) == 0 {
+			return nil
+		}
+
+		for _, li := range pr.Values {
+			if limit > 0 && sent >= limit {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if err := emit(li); err != nil {
+				return err
+			}
+			sent++
+		}
+
+		if pr.Next == "" {
+			return nil
+		}
+		u = pr.Next
+	}
+}
+
 // //
 
 func (obj *Obj) Type() string {
@@ -45,3 +106,35 @@ func (obj *Obj) URL() *url.URL {
 		"",
 	)
 }
+
+// Identity folds the name to lower case: Bitbucket matches owners and
+// repositories case-insensitively.
+func (obj *Obj) Identity() lightweigit.IdentityObj {
+	return lightweigit.IdentityObj{
+		Family: "bitbucket",
+		Host:   obj.Domain(),
+		Name:   strings.ToLower(obj.name),
+	}
+}
+
+func (obj *Obj) CloneHTTPS() string {
+	return "https://bitbucket.org/" + obj.name + ".git"
+}
+
+func (obj *Obj) CloneSSH() string {
+	return "git@bitbucket.org:" + obj.name + ".git"
+}
+
+// //
+
+func (obj *Obj) IssueURL(n int) string {
+	return lightweigit.AddURL(obj.URL(), fmt.Sprintf("/issues/%d", n), "").String()
+}
+
+func (obj *Obj) UserURL(name string) string {
+	return lightweigit.BuildURL("https", "bitbucket.org", name, "").String()
+}
+
+func (obj *Obj) FileURL(ref, path string) string {
+	return lightweigit.AddURL(obj.URL(), "/src/"+ref+"/"+strings.TrimPrefix(path, "/"), "").String()
+}
@@ -128,3 +128,43 @@ func UnmarshalRelease(data []byte) (lightweigit.ProviderReleaseInterface, error)
 
 	return release, nil
 }
+
+// // // //
+
+type byteBranchObj struct {
+	Obj    byteObj
+	Name   string
+	Commit string
+}
+
+//
+
+func (br *BranchObj) Marshal() []byte {
+	dataObj := byteBranchObj{
+		Obj: byteObj{
+			Name: br.Provider.name,
+		},
+		Name:   br.name,
+		Commit: br.commit,
+	}
+	return lightweigit.Marshal(br.Mod(), dataObj)
+}
+
+func UnmarshalBranch(data []byte) (lightweigit.ProviderBranchInterface, error) {
+	dataObj := new(byteBranchObj)
+	mod, err := lightweigit.Unmarshal(data, dataObj)
+	if err != nil {
+		return nil, err
+	}
+	if mod != target.ModBitbucketBranch {
+		return nil, lightweigit.ErrModTag
+	}
+
+	return &BranchObj{
+		Provider: &Obj{
+			name: dataObj.Obj.Name,
+		},
+		name:   dataObj.Name,
+		commit: dataObj.Commit,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package bitbucket
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// archiveURL is the archive link of ref. The ref goes in unescaped and
+// BuildURL escapes it once; slashes of branches such as release/1.0 stay.
+func (obj *Obj) archiveURL(ref string, format lightweigit.ArchiveFormatType) *url.URL {
+	return lightweigit.BuildURL(
+		"https",
+		"bitbucket.org",
+		fmt.Sprintf("%s/get/%s%s", obj.name, ref, format.Ext()),
+		"",
+	)
+}
+
+// Archive links the source of ref, a branch, tag or commit SHA, as zip,
+// tar.gz or tar.bz2.
+func (obj *Obj) Archive(ref string, format lightweigit.ArchiveFormatType) (*url.URL, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty ref")
+	}
+	switch format {
+	case lightweigit.ArchiveZIP, lightweigit.ArchiveTarGz, lightweigit.ArchiveTarBz2:
+	default:
+		return nil, fmt.Errorf("%s archives: %w", format, lightweigit.ErrUnsupported)
+	}
+	return obj.archiveURL(ref, format), nil
+}
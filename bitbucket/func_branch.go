@@ -0,0 +1,91 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func (br *BranchObj) Mod() target.ModType {
+	return target.ModBitbucketBranch
+}
+
+func (br *BranchObj) String() string {
+	return br.name
+}
+
+func (br *BranchObj) Commit() string {
+	return br.commit
+}
+
+func (br *BranchObj) URL() *url.URL {
+	return lightweigit.AddURL(
+		br.Provider.URL(),
+		"/src/"+url.PathEscape(br.name)+"/",
+		"",
+	)
+}
+
+func (br *BranchObj) ZIP() *url.URL {
+	return br.Provider.archiveURL(br.name, lightweigit.ArchiveZIP)
+}
+
+func (br *BranchObj) TAR() *url.URL {
+	return br.Provider.archiveURL(br.name, lightweigit.ArchiveTarGz)
+}
+
+// // // //
+
+type branchItemObj struct {
+	Name   string `json:"name"`
+	Target struct {
+		Hash string `json:"hash"`
+	} `json:"target"`
+}
+
+func (obj *Obj) branch(li branchItemObj) *BranchObj {
+	return &BranchObj{
+		Provider: obj,
+		name:     li.Name,
+		commit:   li.Target.Hash,
+	}
+}
+
+// //
+
+// BranchDefault reads the main branch name from the repository, then its
+// head.
+func (obj *Obj) BranchDefault() (lightweigit.ProviderBranchInterface, error) {
+	info, err := obj.RepositoryInfo()
+	if err != nil {
+		return nil, err
+	}
+	if info.DefaultBranch == "" {
+		return nil, lightweigit.ErrNotFound
+	}
+	return obj.BranchFind(info.DefaultBranch)
+}
+
+func (obj *Obj) BranchFind(findBranch string) (lightweigit.ProviderBranchInterface, error) {
+	var li branchItemObj
+	if err := obj.getJSON(fmt.Sprintf("refs/branches/%s", url.PathEscape(findBranch)), &li); err != nil {
+		return nil, err
+	}
+	if li.Name == "" {
+		li.Name = findBranch
+	}
+	return obj.branch(li), nil
+}
+
+// BranchesStream lists the most recently committed branches first.
+func (obj *Obj) BranchesStream(ctx context.Context, out chan lightweigit.ProviderBranchInterface, limit int) error {
+	u := fmt.Sprintf("refs/branches?pagelen=%d&sort=-target.date", pageLen(limit))
+	return streamCursor(ctx, obj, u, limit, func(li branchItemObj) error {
+		return lightweigit.Send[lightweigit.ProviderBranchInterface](ctx, out, obj.branch(li))
+	})
+}
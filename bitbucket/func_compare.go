@@ -0,0 +1,101 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type commitItemObj struct {
+	Hash    string `json:"hash"`
+	Message string `json:"message"`
+	Date    string `json:"date"`
+	Author  struct {
+		Raw string `json:"raw"`
+	} `json:"author"`
+}
+
+type diffstatItemObj struct {
+	Status       string `json:"status"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+	Old          *struct {
+		Path string `json:"path"`
+	} `json:"old"`
+	New *struct {
+		Path string `json:"path"`
+	} `json:"new"`
+}
+
+// splitAuthor splits a raw "Name <email>" author.
+func splitAuthor(raw string) (string, string) {
+	name, rest, ok := strings.Cut(raw, "<")
+	if !ok {
+		return strings.TrimSpace(raw), ""
+	}
+	return strings.TrimSpace(name), strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(rest), ">"))
+}
+
+// //
+
+// Compare lists commits/head?exclude=base and diffstat/head..base, both
+// through the cursor pagination.
+func (obj *Obj) Compare(base, head string) (*lightweigit.CompareObj, error) {
+	base, head = strings.TrimSpace(base), strings.TrimSpace(head)
+	if base == "" || head == "" {
+		return nil, errors.New("empty ref")
+	}
+	ctx := context.Background()
+	res := &lightweigit.CompareObj{Base: base, Head: head}
+
+	u := fmt.Sprintf("commits/%s?exclude=%s&pagelen=%d", url.PathEscape(head), url.QueryEscape(base), pageLen(0))
+	err := streamCursor(ctx, obj, u, 0, func(li commitItemObj) error {
+		name, email := splitAuthor(li.Author.Raw)
+		res.Commits = append(res.Commits, lightweigit.CommitObj{
+			SHA:     li.Hash,
+			Author:  name,
+			Email:   email,
+			Message: li.Message,
+			Date:    lightweigit.ParseTime(li.Date),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Bitbucket lists the newest commit first.
+	for i, j := 0, len(res.Commits)-1; i < j; i, j = i+1, j-1 {
+		res.Commits[i], res.Commits[j] = res.Commits[j], res.Commits[i]
+	}
+
+	u = fmt.Sprintf("diffstat/%s..%s?pagelen=%d", url.PathEscape(head), url.PathEscape(base), pageLen(0))
+	err = streamCursor(ctx, obj, u, 0, func(li diffstatItemObj) error {
+		f := lightweigit.ChangedFileObj{
+			Status:    lightweigit.FileStatus(li.Status),
+			Additions: li.LinesAdded,
+			Deletions: li.LinesRemoved,
+		}
+		if li.New != nil {
+			f.Path = li.New.Path
+		}
+		if li.Old != nil {
+			if f.Path == "" {
+				f.Path = li.Old.Path
+			} else if li.Old.Path != f.Path {
+				f.OldPath = li.Old.Path
+			}
+		}
+		res.Files = append(res.Files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,36 @@
+package bitbucket
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// File reads path at ref through the src endpoint. Bitbucket answers a
+// directory path with a JSON listing; that is not told apart here.
+func (obj *Obj) File(ref, path string) (*lightweigit.FileObj, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty ref")
+	}
+	p, err := lightweigit.CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/src/%s/%s", obj.name, url.PathEscape(ref), lightweigit.EscapePath(p))
+	b, err := lightweigit.GetRaw(obj, u)
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", p, ref, err)
+	}
+	return lightweigit.NewFile(ref, p, b), nil
+}
+
+func (tag *TagObj) File(path string) (*lightweigit.FileObj, error) {
+	return tag.Provider.File(tag.name, path)
+}
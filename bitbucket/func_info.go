@@ -0,0 +1,51 @@
+package bitbucket
+
+import (
+	"fmt"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type repoItemObj struct {
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+	UpdatedOn   string `json:"updated_on"`
+	MainBranch  *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Parent *struct {
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	} `json:"parent"`
+}
+
+// //
+
+// RepositoryInfo reads the repository. Bitbucket has no archiving, stars
+// or license detection; Pushed is the last update.
+func (obj *Obj) RepositoryInfo() (*lightweigit.RepositoryInfoObj, error) {
+	var li repoItemObj
+	if err := lightweigit.GetJSON(obj, fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s", obj.name), &li); err != nil {
+		return nil, err
+	}
+
+	info := &lightweigit.RepositoryInfoObj{
+		Name:        li.FullName,
+		Description: li.Description,
+		Visibility:  lightweigit.Visibility(li.IsPrivate, false),
+		Pushed:      lightweigit.ParseTime(li.UpdatedOn),
+	}
+	if li.MainBranch != nil {
+		info.DefaultBranch = li.MainBranch.Name
+	}
+	if li.Parent != nil {
+		info.Parent = li.Parent.Links.HTML.Href
+	}
+	return info, nil
+}
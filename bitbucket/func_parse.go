@@ -6,6 +6,8 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
 )
 
 // // // // // // // // // // // // // // // //
@@ -101,3 +103,38 @@ func Parse(raw string) (*Obj, error) {
 
 	return &Obj{name: workspace + "/" + repo}, nil
 }
+
+// ParseOffline is Parse; this provider never needs the network to resolve.
+func ParseOffline(raw string) (*Obj, error) {
+	return Parse(raw)
+}
+
+// Ref reads the branch, commit, file or tree raw points at, relative to
+// this repository. Source pages name a ref without saying whether it is a
+// branch, a tag or a commit.
+func (obj *Obj) Ref(raw string) lightweigit.RefObj {
+	tail := lightweigit.PathAfter(raw, obj.name)
+	if len(tail) < 2 {
+		return lightweigit.RefObj{}
+	}
+
+	switch tail[0] {
+	case "src", "raw":
+		return lightweigit.RefAt(lightweigit.RefFile, lightweigit.RefTree, tail[1:])
+	case "commits":
+		if len(tail) >= 3 && tail[1] == "tag" {
+			return lightweigit.RefObj{Kind: lightweigit.RefTag, Name: tail[2]}
+		}
+		return lightweigit.RefAt(lightweigit.RefCommit, lightweigit.RefCommit, tail[1:2])
+	case "branch":
+		return lightweigit.RefObj{Kind: lightweigit.RefBranch, Name: strings.Join(tail[1:], "/")}
+	case "get":
+		name := tail[len(tail)-1]
+		for _, ext := range []string{".tar.bz2", ".tar.gz", ".zip"} {
+			if strings.HasSuffix(name, ext) {
+				return lightweigit.RefObj{Kind: lightweigit.RefTree, Name: strings.TrimSuffix(name, ext)}
+			}
+		}
+	}
+	return lightweigit.RefObj{}
+}
@@ -0,0 +1,27 @@
+package bitbucket
+
+import (
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func init() {
+	lightweigit.RegisterBuiltin(lightweigit.ProviderEntryObj{
+		Name:     "bitbucket",
+		Priority: 100,
+
+		Match: func(host string) bool {
+			return host == "bitbucket.org" || host == "www.bitbucket.org"
+		},
+
+		TagMod:           target.ModBitbucketTag,
+		ReleaseMod:       target.ModBitbucketRelease,
+		UnmarshalTag:     UnmarshalTag,
+		UnmarshalRelease: UnmarshalRelease,
+
+		BranchMod:       target.ModBitbucketBranch,
+		UnmarshalBranch: UnmarshalBranch,
+	}, Parse, ParseOffline, nil)
+}
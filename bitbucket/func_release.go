@@ -196,56 +196,18 @@ func (obj *Obj) ReleaseFind(findRelease string) (lightweigit.ProviderReleaseInte
 	return obj.buildRelease(t.String(), assets), nil
 }
 
-// No adaptive page shrink here: Bitbucket paginates via opaque `next` cursor
-// URLs with pagelen baked in, so a mid-stream window remap is not possible.
-// The 50 default plus the 8 MiB GetJSON cap keeps pages within bounds.
 func (obj *Obj) ReleasesStream(ctx context.Context, out chan lightweigit.ProviderReleaseInterface, limit int) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	perPage := 50
-	if limit > 0 && limit < perPage {
-		perPage = limit
-	}
-
 	assets, err := obj.listDownloads(ctx, 0)
 	if err != nil {
 		assets = nil
 	}
 
-	u := fmt.Sprintf("refs/tags?pagelen=%d&sort=-target.date", perPage)
-	sent := 0
-	for {
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-
-		var tr tagsRespObj
-		if err := obj.getJSONAny(u, &tr); err != nil {
-			return err
-		}
-		if len(tr.Values) == 0 {
-			return nil
-		}
-
-		for _, li := range tr.Values {
-			if limit > 0 && sent >= limit {
-				return nil
-			}
-			if ctx.Err() != nil {
-				return ctx.Err()
-			}
-
-			if err := lightweigit.Send[lightweigit.ProviderReleaseInterface](ctx, out, obj.buildRelease(li.Name, assets)); err != nil {
-				return err
-			}
-			sent++
-		}
-
-		if tr.Next == "" {
-			return nil
-		}
-		u = tr.Next
-	}
+	u := fmt.Sprintf("refs/tags?pagelen=%d&sort=-target.date", pageLen(limit))
+	return streamCursor(ctx, obj, u, limit, func(li tagItemObj) error {
+		return lightweigit.Send[lightweigit.ProviderReleaseInterface](ctx, out, obj.buildRelease(li.Name, assets))
+	})
 }
@@ -28,21 +28,11 @@ func (tag *TagObj) URL() *url.URL {
 }
 
 func (tag *TagObj) ZIP() *url.URL {
-	return lightweigit.BuildURL(
-		"https",
-		"bitbucket.org",
-		fmt.Sprintf("%s/get/%s.zip", tag.Provider.name, url.PathEscape(tag.name)),
-		"",
-	)
+	return tag.Provider.archiveURL(tag.name, lightweigit.ArchiveZIP)
 }
 
 func (tag *TagObj) TAR() *url.URL {
-	return lightweigit.BuildURL(
-		"https",
-		"bitbucket.org",
-		fmt.Sprintf("%s/get/%s.tar.gz", tag.Provider.name, url.PathEscape(tag.name)),
-		"",
-	)
+	return tag.Provider.archiveURL(tag.name, lightweigit.ArchiveTarGz)
 }
 
 // // // //
@@ -100,55 +90,12 @@ func (obj *Obj) TagFind(findTag string) (lightweigit.ProviderTagInterface, error
 	}, nil
 }
 
-// No adaptive page shrink here: Bitbucket paginates via opaque `next` cursor
-// URLs with pagelen baked in, so a mid-stream window remap is not possible.
-// The 50 default plus the 8 MiB GetJSON cap keeps pages within bounds.
 func (obj *Obj) TagsStream(ctx context.Context, out chan lightweigit.ProviderTagInterface, limit int) error {
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
-	perPage := 50
-	if limit > 0 && limit < perPage {
-		perPage = limit
-	}
-
-	u := fmt.Sprintf("refs/tags?pagelen=%d&sort=-target.date", perPage)
-	sent := 0
-	for {
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-
-		var tr tagsRespObj
-		if err := obj.getJSONAny(u, &tr); err != nil {
-			return err
-		}
-		if len(tr.Values) == 0 {
-			return nil
-		}
-
-		for _, li := range tr.Values {
-			if limit > 0 && sent >= limit {
-				return nil
-			}
-
-			if ctx.Err() != nil {
-				return ctx.Err()
-			}
-
-			if err := lightweigit.Send[lightweigit.ProviderTagInterface](ctx, out, &TagObj{
-				Provider: obj,
-				name:     li.Name,
-			}); err != nil {
-				return err
-			}
-			sent++
-		}
-
-		if tr.Next == "" {
-			return nil
-		}
-		u = tr.Next
-	}
+	u := fmt.Sprintf("refs/tags?pagelen=%d&sort=-target.date", pageLen(limit))
+	return streamCursor(ctx, obj, u, limit, func(li tagItemObj) error {
+		return lightweigit.Send[lightweigit.ProviderTagInterface](ctx, out, &TagObj{
+			Provider: obj,
+			name:     li.Name,
+		})
+	})
 }
@@ -0,0 +1,31 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// WebhookRelease reads the "new" ref of a repo:push change. Bitbucket has
+// no releases: every tag is one, as in ReleasesStream, and a pushed tag
+// carries no downloads yet.
+func (obj *Obj) WebhookRelease(payload []byte) (lightweigit.ProviderReleaseInterface, error) {
+	var ref struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(payload, &ref); err != nil {
+		return nil, err
+	}
+	if ref.Type != "tag" || ref.Name == "" {
+		return nil, errors.New("webhook ref is not a tag")
+	}
+	return obj.buildRelease(ref.Name, nil), nil
+}
+
+func (obj *Obj) WebhookTag(name string) lightweigit.ProviderTagInterface {
+	return &TagObj{Provider: obj, name: name}
+}
@@ -17,6 +17,13 @@ type TagObj struct {
 	name     string
 }
 
+// BranchObj is a branch and the head commit it had when it was listed.
+type BranchObj struct {
+	Provider *Obj
+	name     string
+	commit   string
+}
+
 type ReleaseAssetObj struct {
 	download    url.URL
 	contentType string
@@ -0,0 +1,209 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+func entryOf(rel lightweigit.ProviderReleaseInterface) EntryObj {
+	e := EntryObj{
+		Name:       strings.TrimSpace(rel.Name()),
+		Body:       rel.BodyMD(),
+		Prerelease: rel.IsPrerelease(),
+	}
+	if tag := rel.Tag(); tag != nil {
+		e.Version = tag.String()
+	}
+	if e.Version == "" {
+		e.Version = e.Name
+	}
+	if u := rel.URL(); u != nil {
+		e.URL = u.String()
+	}
+	return e
+}
+
+// collect reads the releases of p in the provider's order. With OrderDate
+// it stops once from has been seen.
+func collect(ctx context.Context, p lightweigit.ProviderInterface, from string, order OrderType) ([]EntryObj, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := make(chan lightweigit.ProviderReleaseInterface)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- p.ReleasesStream(streamCtx, out, 0)
+		close(out)
+	}()
+
+	var list []EntryObj
+	stopped := false
+	for rel := range out {
+		if stopped {
+			continue
+		}
+		e := entryOf(rel)
+		list = append(list, e)
+		if order == OrderDate && e.Version == from {
+			stopped = true
+			cancel()
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := <-errc; err != nil && !(stopped && errors.Is(err, context.Canceled)) {
+		return nil, err
+	}
+	return list, nil
+}
+
+// //
+
+// Build collects the releases of p after from, up to and including to, and
+// returns them as one changelog. from and to are tag names; neither needs a
+// release of its own with OrderSemver.
+func Build(ctx context.Context, p lightweigit.ProviderInterface, from, to string, opts OptionsObj) (*ChangelogObj, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if p == nil {
+		return nil, errors.New("changelog: nil provider")
+	}
+
+	list, err := collect(ctx, p, strings.TrimSpace(from), opts.Order)
+	if err != nil {
+		return nil, err
+	}
+	return FromEntries(list, from, to, opts)
+}
+
+// FromReleases is Build over releases already at hand, in the provider's
+// order (newest first).
+func FromReleases(releases []lightweigit.ProviderReleaseInterface, from, to string, opts OptionsObj) (*ChangelogObj, error) {
+	list := make([]EntryObj, 0, len(releases))
+	for _, rel := range releases {
+		list = append(list, entryOf(rel))
+	}
+	return FromEntries(list, from, to, opts)
+}
+
+// FromEntries selects and orders the entries between from and to as Build
+// does; list is newest first.
+func FromEntries(list []EntryObj, from, to string, opts OptionsObj) (*ChangelogObj, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" {
+		return nil, errors.New("changelog: empty version")
+	}
+
+	var (
+		picked []EntryObj
+		err    error
+	)
+	switch opts.Order {
+	case OrderSemver:
+		picked, err = bySemver(list, from, to, opts)
+	case OrderDate:
+		picked, err = byDate(list, from, to, opts)
+	default:
+		err = fmt.Errorf("changelog: unknown order %d", opts.Order)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Ascending {
+		for i, j := 0, len(picked)-1; i < j; i, j = i+1, j-1 {
+			picked[i], picked[j] = picked[j], picked[i]
+		}
+	}
+	return &ChangelogObj{
+		From:      from,
+		To:        to,
+		Entries:   picked,
+		normalize: opts.Normalize,
+	}, nil
+}
+
+func bySemver(list []EntryObj, from, to string, opts OptionsObj) ([]EntryObj, error) {
+	lo, ok := lightweigit.ParseVersion(from)
+	if !ok {
+		return nil, fmt.Errorf("changelog: %q is not a version", from)
+	}
+	hi, ok := lightweigit.ParseVersion(to)
+	if !ok {
+		return nil, fmt.Errorf("changelog: %q is not a version", to)
+	}
+	if lo.Compare(hi) > 0 {
+		lo, hi = hi, lo
+	}
+
+	type itemObj struct {
+		e EntryObj
+		v lightweigit.VersionObj
+	}
+	var items []itemObj
+	for _, e := range list {
+		v, ok := lightweigit.ParseVersion(e.Version)
+		if !ok || v.Compare(lo) <= 0 {
+			continue
+		}
+		if c := v.Compare(hi); c > 0 || (c == 0 && opts.ExcludeTo) {
+			continue
+		}
+		if opts.SkipPrereleases && (e.Prerelease || v.Prerelease()) {
+			continue
+		}
+		items = append(items, itemObj{e, v})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].v.Compare(items[j].v) > 0
+	})
+	picked := make([]EntryObj, 0, len(items))
+	for _, it := range items {
+		picked = append(picked, it.e)
+	}
+	return picked, nil
+}
+
+func byDate(list []EntryObj, from, to string, opts OptionsObj) ([]EntryObj, error) {
+	hi, lo := -1, -1
+	for i, e := range list {
+		switch e.Version {
+		case to:
+			hi = i
+		case from:
+			lo = i
+		}
+	}
+	if hi < 0 {
+		return nil, fmt.Errorf("changelog: no release %q: %w", to, lightweigit.ErrNotFound)
+	}
+	if lo < 0 {
+		return nil, fmt.Errorf("changelog: no release %q: %w", from, lightweigit.ErrNotFound)
+	}
+	if lo < hi {
+		lo, hi = hi, lo
+	}
+	if opts.ExcludeTo {
+		hi++
+	}
+
+	var picked []EntryObj
+	for _, e := range list[hi:lo] {
+		if opts.SkipPrereleases && e.Prerelease {
+			continue
+		}
+		picked = append(picked, e)
+	}
+	return picked, nil
+}
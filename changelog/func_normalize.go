@@ -0,0 +1,171 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+// sectionOrder is the Keep a Changelog order, with breaking changes ahead
+// and everything that fits none of them last.
+var sectionOrder = []string{
+	"Breaking changes",
+	"Added",
+	"Changed",
+	"Deprecated",
+	"Removed",
+	"Fixed",
+	"Security",
+	"Other",
+}
+
+// headingWords maps words found in release note headings to sections;
+// the first match in list order wins.
+var headingWords = []struct {
+	word, section string
+}{
+	{"contributor", "Other"},
+	{"breaking", "Breaking changes"},
+	{"security", "Security"},
+	{"deprecat", "Deprecated"},
+	{"remov", "Removed"},
+	{"fix", "Fixed"},
+	{"bug", "Fixed"},
+	{"feat", "Added"},
+	{"added", "Added"},
+	{"new", "Added"},
+	{"change", "Changed"},
+	{"improv", "Changed"},
+	{"enhanc", "Changed"},
+	{"perf", "Changed"},
+	{"refactor", "Changed"},
+	{"doc", "Other"},
+	{"chore", "Other"},
+	{"maint", "Other"},
+	{"depend", "Other"},
+	{"other", "Other"},
+	{"misc", "Other"},
+}
+
+// commitTypes maps Conventional Commits types to sections.
+var commitTypes = map[string]string{
+	"feat":      "Added",
+	"fix":       "Fixed",
+	"perf":      "Changed",
+	"refactor":  "Changed",
+	"revert":    "Changed",
+	"security":  "Security",
+	"deprecate": "Deprecated",
+	"remove":    "Removed",
+	"docs":      "Other",
+	"chore":     "Other",
+	"build":     "Other",
+	"ci":        "Other",
+	"test":      "Other",
+	"style":     "Other",
+}
+
+var (
+	headingRe = regexp.MustCompile(`^ {0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	commitRe  = regexp.MustCompile(`^[-*+]\s+([A-Za-z]+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+)
+
+// //
+
+func headingSection(text string) string {
+	t := strings.ToLower(text)
+	for _, hw := range headingWords {
+		if strings.Contains(t, hw.word) {
+			return hw.section
+		}
+	}
+	return ""
+}
+
+// isFence reports whether line opens or closes a fenced code block.
+func isFence(line string) bool {
+	t := strings.TrimLeft(line, " ")
+	return len(line)-len(t) < 4 && (strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~"))
+}
+
+// normalize regroups a release body under the sectionOrder headings at
+// level 3. Headings naming a known section start it; bullets written as
+// Conventional Commits ("- fix(api)!: msg") move to the section of their
+// type, along with their indented continuation lines. Anything else stays
+// where it was: ahead of the sections, or inside the section it was under.
+func normalize(body string) string {
+	var (
+		preamble []string
+		sections = make(map[string][]string)
+		cur      string
+		follow   string
+		inFence  bool
+	)
+	add := func(section, line string) {
+		if section == "" {
+			preamble = append(preamble, line)
+			return
+		}
+		sections[section] = append(sections[section], line)
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		if inFence || isFence(line) {
+			if isFence(line) {
+				inFence = !inFence
+			}
+			add(cur, line)
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			follow = ""
+			cur = headingSection(m[2])
+			if cur == "" {
+				preamble = append(preamble, line)
+			}
+			continue
+		}
+
+		if m := commitRe.FindStringSubmatch(line); m != nil {
+			section, ok := commitTypes[strings.ToLower(m[1])]
+			if ok {
+				if m[3] == "!" || strings.HasPrefix(m[4], "BREAKING") {
+					section = "Breaking changes"
+				}
+				item := "- " + m[4]
+				if m[2] != "" {
+					item = "- **" + m[2] + ":** " + m[4]
+				}
+				sections[section] = append(sections[section], item)
+				follow = section
+				continue
+			}
+		}
+
+		if follow != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			sections[follow] = append(sections[follow], line)
+			continue
+		}
+		follow = ""
+		add(cur, line)
+	}
+
+	var b strings.Builder
+	if p := strings.TrimSpace(strings.Join(preamble, "\n")); p != "" {
+		b.WriteString(p)
+		b.WriteString("\n")
+	}
+	for _, s := range sectionOrder {
+		text := strings.TrimSpace(strings.Join(sections[s], "\n"))
+		if text == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("### " + s + "\n\n" + text + "\n")
+	}
+	return b.String()
+}
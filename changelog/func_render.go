@@ -0,0 +1,167 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+var (
+	imageRe  = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkRe   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	strongRe = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	codeRe   = regexp.MustCompile("`([^`]+)`")
+	bulletRe = regexp.MustCompile(`^(\s*)[*+]\s+`)
+)
+
+// body is the entry's body as it goes into the changelog.
+func (c *ChangelogObj) body(e EntryObj) string {
+	if c.normalize {
+		return normalize(e.Body)
+	}
+	return strings.TrimSpace(strings.ReplaceAll(e.Body, "\r\n", "\n"))
+}
+
+// title is the entry heading without markup: the version, the release name
+// when it says more, and a pre-release mark.
+func (e EntryObj) title() string {
+	t := e.Version
+	if e.Name != "" && e.Name != e.Version {
+		t += " - " + e.Name
+	}
+	if e.Prerelease {
+		t += " (pre-release)"
+	}
+	return t
+}
+
+// demote shifts the headings of md so that the shallowest is at level floor,
+// keeping them under the entry heading.
+func demote(md string, floor int) string {
+	lines := strings.Split(md, "\n")
+
+	top := 7
+	inFence := false
+	for _, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil && !inFence && len(m[1]) < top {
+			top = len(m[1])
+		}
+	}
+	if top >= floor {
+		return md
+	}
+
+	shift := floor - top
+	inFence = false
+	for i, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil && !inFence {
+			level := len(m[1]) + shift
+			if level > 6 {
+				level = 6
+			}
+			lines[i] = strings.Repeat("#", level) + " " + m[2]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plain strips Markdown down to text: headings lose their marks and are
+// underlined, links keep their target in parentheses, code blocks are
+// indented.
+func plain(md string) string {
+	var out []string
+	inFence := false
+	for _, line := range strings.Split(md, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			out = append(out, "    "+line)
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			text := inline(m[2])
+			out = append(out, text, strings.Repeat("-", len([]rune(text))))
+			continue
+		}
+		out = append(out, inline(bulletRe.ReplaceAllString(line, "$1- ")))
+	}
+	return strings.Join(out, "\n")
+}
+
+func inline(s string) string {
+	s = imageRe.ReplaceAllString(s, "$1")
+	s = linkRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := linkRe.FindStringSubmatch(m)
+		if sub[1] == sub[2] {
+			return sub[1]
+		}
+		return sub[1] + " (" + sub[2] + ")"
+	})
+	s = strongRe.ReplaceAllString(s, "$2")
+	return codeRe.ReplaceAllString(s, "$1")
+}
+
+// //
+
+// Markdown renders one "## version" section per entry, linked to the
+// release, with the body's headings nested below it.
+func (c *ChangelogObj) Markdown() string {
+	var b strings.Builder
+	for i, e := range c.Entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		head := e.Version
+		if e.URL != "" {
+			head = "[" + e.Version + "](" + e.URL + ")"
+		}
+		if e.Name != "" && e.Name != e.Version {
+			head += " - " + e.Name
+		}
+		if e.Prerelease {
+			head += " (pre-release)"
+		}
+		b.WriteString("## " + head + "\n\n")
+
+		body := c.body(e)
+		if body == "" {
+			body = "_No release notes._"
+		}
+		b.WriteString(strings.TrimSpace(demote(body, 3)) + "\n")
+	}
+	return b.String()
+}
+
+// Text renders the changelog for terminals and plain-text mail: titles
+// underlined with "=", Markdown markup removed.
+func (c *ChangelogObj) Text() string {
+	var b strings.Builder
+	for i, e := range c.Entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		t := e.title()
+		b.WriteString(t + "\n" + strings.Repeat("=", len([]rune(t))) + "\n\n")
+
+		body := c.body(e)
+		if body == "" {
+			body = "No release notes."
+		}
+		b.WriteString(strings.TrimSpace(plain(body)) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package changelog
+
+// // // // // // // // // // // // // // // //
+
+// OrderType decides which releases lie between two versions.
+type OrderType byte
+
+const (
+	// OrderSemver compares tag names as semantic versions; releases whose
+	// tags do not parse are left out.
+	OrderSemver OrderType = iota
+
+	// OrderDate takes the releases the provider lists between the two,
+	// as providers list them newest first.
+	OrderDate
+)
+
+// OptionsObj tunes Build. The zero value orders by semver, newest first,
+// and keeps the bodies as they are.
+type OptionsObj struct {
+	Order OrderType
+
+	// Ascending lists the oldest release first.
+	Ascending bool
+
+	// ExcludeTo leaves out the release of the upper version itself.
+	ExcludeTo bool
+
+	// SkipPrereleases leaves out releases flagged as pre-releases, and
+	// with OrderSemver tags with pre-release identifiers.
+	SkipPrereleases bool
+
+	// Normalize regroups each body under Keep a Changelog headings and
+	// files Conventional Commits bullets ("- feat(api): ...") under them.
+	Normalize bool
+}
+
+// EntryObj is the part of one release that goes into the changelog.
+type EntryObj struct {
+	Version    string
+	Name       string
+	URL        string
+	Body       string
+	Prerelease bool
+}
+
+// ChangelogObj is the collected changelog; Markdown and Text render it.
+type ChangelogObj struct {
+	From    string
+	To      string
+	Entries []EntryObj
+
+	normalize bool
+}
+
+// sectionObj is one normalized section of a body.
+type sectionObj struct {
+	title string
+	lines []string
+}
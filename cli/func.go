@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+var commands = []commandObj{
+	{"latest", "[-tag] URL", "print the latest release, or tag", cmdLatest},
+	{"find", "[-tag] URL NAME", "print the release, or tag, called NAME", cmdFind},
+	{"tags", "[-limit N] URL", "list tags, newest first", cmdTags},
+	{"releases", "[-limit N] URL", "list releases, newest first", cmdReleases},
+	{"assets", "URL [TAG]", "list the assets of a release, the latest by default", cmdAssets},
+	{"download", "[-asset PATTERN] [-format F] [-dir DIR] URL [TAG]", "download release assets, or the source archive", cmdDownload},
+	{"archive-url", "[-format F] URL [REF]", "print the source archive URL of a ref, the latest tag by default", cmdArchiveURL},
+}
+
+// Run runs the command line args, without the program name, and returns
+// the exit code. It replaces lightweigit.HttpClient while it runs and puts
+// the previous one back when it returns.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return ExitUsage
+		}
+		return ExitOK
+	}
+	if args[0] == "version" || args[0] == "-version" || args[0] == "--version" {
+		fmt.Fprintln(stdout, target.Name, target.Version)
+		return ExitOK
+	}
+
+	var cmd *commandObj
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "lightweigit: unknown command %q\n\n", args[0])
+		usage(stderr)
+		return ExitUsage
+	}
+
+	env := &envObj{ctx: ctx, stdout: stdout, stderr: stderr}
+	fs := flag.NewFlagSet("lightweigit "+cmd.name, flag.ContinueOnError)
+	fs.BoolVar(&env.json, "json", false, "print JSON instead of a table")
+	fs.DurationVar(&env.timeout, "timeout", defaultTimeout, "timeout of each API request")
+	fs.StringVar(&env.proxy, "proxy", "", "HTTP proxy URL (default from HTTP_PROXY / HTTPS_PROXY)")
+
+	// The flag package would report parse errors itself; they are printed
+	// below with the others instead.
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	printUsage := func() {
+		fmt.Fprintf(stderr, "usage: lightweigit %s %s\n\n%s.\n\nflags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.SetOutput(stderr)
+		fs.PrintDefaults()
+	}
+
+	err := cmd.run(env, fs, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		printUsage()
+		return ExitOK
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "lightweigit:", err)
+		if errors.Is(err, ErrUsage) {
+			fmt.Fprintln(stderr)
+			printUsage()
+		}
+	}
+	return ExitCode(err)
+}
+
+// ExitCode maps err to the exit code Run returns for it.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrUsage):
+		return ExitUsage
+	case lightweigit.IsRateLimit(err):
+		return ExitRateLimited
+	case errors.Is(err, lightweigit.ErrForbidden):
+		return ExitForbidden
+	case errors.Is(err, lightweigit.ErrNotFound):
+		return ExitNotFound
+	}
+	return ExitError
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: lightweigit COMMAND [flags] URL [ARG]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nEvery command takes -json, -timeout and -proxy; flags go before the URL.\n")
+	fmt.Fprintf(w, "Exit codes: %d not found, %d forbidden, %d rate limited, %d usage, %d other errors.\n",
+		ExitNotFound, ExitForbidden, ExitRateLimited, ExitUsage, ExitError)
+}
+
+// // // //
+
+// parse reads the flags and checks that between min and max positional
+// arguments are left.
+func (env *envObj) parse(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%v: %w", err, ErrUsage)
+	}
+	rest := fs.Args()
+	if len(rest) < min || len(rest) > max {
+		return nil, fmt.Errorf("wrong number of arguments: %w", ErrUsage)
+	}
+	return rest, nil
+}
+
+// open installs the HTTP client and parses the repository URL.
+func (env *envObj) open(raw string) (lightweigit.ProviderInterface, func(), error) {
+	restore, err := env.client()
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := global.Parse(raw)
+	if err != nil {
+		restore()
+		return nil, nil, err
+	}
+	return p, restore, nil
+}
+
+// client swaps in a copy of lightweigit.HttpClient with the timeout and
+// proxy of the flags, and returns the function that puts it back.
+func (env *envObj) client() (func(), error) {
+	old := lightweigit.HttpClient
+	c := *old
+	c.Timeout = env.timeout
+
+	if env.proxy != "" {
+		u, err := url.Parse(env.proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("proxy %q: not a URL: %w", env.proxy, ErrUsage)
+		}
+		base, ok := c.Transport.(*http.Transport)
+		if c.Transport == nil {
+			base, ok = http.DefaultTransport.(*http.Transport)
+		}
+		if !ok {
+			return nil, fmt.Errorf("proxy: lightweigit.HttpClient has a custom transport")
+		}
+		t := base.Clone()
+		t.Proxy = http.ProxyURL(u)
+		c.Transport = t
+	}
+
+	lightweigit.HttpClient = &c
+	return func() { lightweigit.HttpClient = old }, nil
+}
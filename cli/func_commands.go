@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+func cmdLatest(env *envObj, fs *flag.FlagSet, args []string) error {
+	tag := fs.Bool("tag", false, "print the latest tag instead of the latest release")
+	rest, err := env.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	p, restore, err := env.open(rest[0])
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	if *tag {
+		t, err := p.TagLatest()
+		if err != nil {
+			return fmt.Errorf("%s: latest tag: %w", p, err)
+		}
+		return env.printTag(tagView(t))
+	}
+	rel, err := p.ReleaseLatest()
+	if err != nil {
+		return fmt.Errorf("%s: latest release: %w", p, err)
+	}
+	return env.printRelease(releaseView(rel))
+}
+
+func cmdFind(env *envObj, fs *flag.FlagSet, args []string) error {
+	tag := fs.Bool("tag", false, "find a tag instead of a release")
+	rest, err := env.parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	p, restore, err := env.open(rest[0])
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	if *tag {
+		t, err := p.TagFind(rest[1])
+		if err != nil {
+			return fmt.Errorf("%s: tag %q: %w", p, rest[1], err)
+		}
+		return env.printTag(tagView(t))
+	}
+	rel, err := p.ReleaseFind(rest[1])
+	if err != nil {
+		return fmt.Errorf("%s: release %q: %w", p, rest[1], err)
+	}
+	return env.printRelease(releaseView(rel))
+}
+
+func cmdTags(env *envObj, fs *flag.FlagSet, args []string) error {
+	limit := fs.Int("limit", defaultLimit, "how many tags to list; 0 lists all")
+	rest, err := env.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	p, restore, err := env.open(rest[0])
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	list, err := collect(env.ctx, p.TagsStream, *limit)
+	if err != nil {
+		return fmt.Errorf("%s: tags: %w", p, err)
+	}
+	views := make([]tagViewObj, 0, len(list))
+	for _, t := range list {
+		views = append(views, tagView(t))
+	}
+	return env.printTags(views)
+}
+
+func cmdReleases(env *envObj, fs *flag.FlagSet, args []string) error {
+	limit := fs.Int("limit", defaultLimit, "how many releases to list; 0 lists all")
+	rest, err := env.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	p, restore, err := env.open(rest[0])
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	list, err := collect(env.ctx, p.ReleasesStream, *limit)
+	if err != nil {
+		return fmt.Errorf("%s: releases: %w", p, err)
+	}
+	views := make([]releaseViewObj, 0, len(list))
+	for _, rel := range list {
+		views = append(views, releaseView(rel))
+	}
+	return env.printReleases(views)
+}
+
+func cmdAssets(env *envObj, fs *flag.FlagSet, args []string) error {
+	rest, err := env.parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	p, restore, err := env.open(rest[0])
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	rel, err := release(p, rest[1:])
+	if err != nil {
+		return err
+	}
+	return env.printAssets(releaseView(rel).Assets)
+}
+
+func cmdDownload(env *envObj, fs *flag.FlagSet, args []string) error {
+	pattern := fs.String("asset", "", "download the release assets whose names match this glob, e.g. '*linux_amd64*'")
+	format := fs.String("format", "zip", "source archive format: zip, tar.gz or tar.bz2")
+	dir := fs.String("dir", ".", "directory to save to")
+	rest, err := env.parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	f, ok := lightweigit.ParseArchiveFormat(*format)
+	if !ok {
+		return fmt.Errorf("archive format %q: %w", *format, ErrUsage)
+	}
+	if *pattern != "" {
+		if _, err := path.Match(*pattern, ""); err != nil {
+			return fmt.Errorf("asset pattern %q: %v: %w", *pattern, err, ErrUsage)
+		}
+	}
+	p, restore, err := env.open(rest[0])
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	var saved []downloadViewObj
+	if *pattern == "" {
+		ref, u, err := archive(p, rest[1:], f)
+		if err != nil {
+			return err
+		}
+		name := path.Base(p.String()) + "-" + strings.ReplaceAll(ref, "/", "-") + f.Ext()
+		n, err := fetch(env.ctx, u, filepath.Join(*dir, name))
+		if err != nil {
+			return fmt.Errorf("%s: archive of %s: %w", p, ref, err)
+		}
+		saved = append(saved, downloadViewObj{Name: name, Path: filepath.Join(*dir, name), Size: n})
+	} else {
+		rel, err := release(p, rest[1:])
+		if err != nil {
+			return err
+		}
+		for _, a := range rel.Assets() {
+			if ok, _ := path.Match(*pattern, a.Name()); !ok {
+				continue
+			}
+			name := path.Base(a.Name())
+			n, err := fetch(env.ctx, a.URL(), filepath.Join(*dir, name))
+			if err != nil {
+				return fmt.Errorf("%s: asset %s: %w", p, a.Name(), err)
+			}
+			saved = append(saved, downloadViewObj{Name: name, Path: filepath.Join(*dir, name), Size: n})
+		}
+		if len(saved) == 0 {
+			return fmt.Errorf("%s: no asset of %s matches %q: %w", p, rel.Tag(), *pattern, lightweigit.ErrNotFound)
+		}
+	}
+	return env.printDownloads(saved)
+}
+
+func cmdArchiveURL(env *envObj, fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", "zip", "archive format: zip, tar.gz or tar.bz2")
+	rest, err := env.parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	f, ok := lightweigit.ParseArchiveFormat(*format)
+	if !ok {
+		return fmt.Errorf("archive format %q: %w", *format, ErrUsage)
+	}
+	p, restore, err := env.open(rest[0])
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	ref, u, err := archive(p, rest[1:], f)
+	if err != nil {
+		return err
+	}
+	return env.printArchive(archiveViewObj{Ref: ref, Format: f.String(), URL: u.String()})
+}
+
+// // // //
+
+// collect reads up to limit items of a stream; 0 reads them all.
+func collect[T any](ctx context.Context, stream func(context.Context, chan T, int) error, limit int) ([]T, error) {
+	out := make(chan T)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- stream(ctx, out, limit)
+		close(out)
+	}()
+
+	var list []T
+	for v := range out {
+		list = append(list, v)
+	}
+	return list, <-errc
+}
+
+// release is the release named in args, or the latest one.
+func release(p lightweigit.ProviderInterface, args []string) (lightweigit.ProviderReleaseInterface, error) {
+	if len(args) == 0 {
+		rel, err := p.ReleaseLatest()
+		if err != nil {
+			return nil, fmt.Errorf("%s: latest release: %w", p, err)
+		}
+		return rel, nil
+	}
+	rel, err := p.ReleaseFind(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s: release %q: %w", p, args[0], err)
+	}
+	return rel, nil
+}
+
+// archive is the source archive URL of the ref named in args, or of the
+// latest tag. Providers without lightweigit.ProviderArchiveInterface give
+// the archives of their tags only.
+func archive(p lightweigit.ProviderInterface, args []string, f lightweigit.ArchiveFormatType) (string, *url.URL, error) {
+	var ref string
+	if len(args) == 0 {
+		t, err := p.TagLatest()
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: latest tag: %w", p, err)
+		}
+		ref = t.String()
+	} else {
+		ref = args[0]
+	}
+
+	if a, ok := p.(lightweigit.ProviderArchiveInterface); ok {
+		u, err := a.Archive(ref, f)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %s archive of %s: %w", p, f, ref, err)
+		}
+		return ref, u, nil
+	}
+
+	t, err := p.TagFind(ref)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: tag %q: %w", p, ref, err)
+	}
+	var u *url.URL
+	switch f {
+	case lightweigit.ArchiveZIP:
+		u = t.ZIP()
+	case lightweigit.ArchiveTarGz:
+		u = t.TAR()
+	}
+	if u == nil {
+		return "", nil, fmt.Errorf("%s: %s archive of %s: %w", p, f, ref, lightweigit.ErrUnsupported)
+	}
+	return ref, u, nil
+}
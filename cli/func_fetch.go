@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// fetch downloads u into dst with lightweigit.Download and returns its
+// size. The file appears under dst only once it is complete.
+func fetch(ctx context.Context, u *url.URL, dst string) (int64, error) {
+	body, err := lightweigit.Download(ctx, u)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".lightweigit-*")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(tmp, body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), dst)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+func tagView(t lightweigit.ProviderTagInterface) tagViewObj {
+	return tagViewObj{Name: t.String(), URL: urlString(t.URL()), ZIP: urlString(t.ZIP()), TAR: urlString(t.TAR())}
+}
+
+func releaseView(rel lightweigit.ProviderReleaseInterface) releaseViewObj {
+	v := releaseViewObj{
+		Name:       rel.Name(),
+		URL:        urlString(rel.URL()),
+		Prerelease: rel.IsPrerelease(),
+		ZIP:        urlString(rel.ZIP()),
+		TAR:        urlString(rel.TAR()),
+		Assets:     []assetViewObj{},
+	}
+	if t := rel.Tag(); t != nil {
+		v.Tag = t.String()
+	}
+	if rt, ok := rel.(lightweigit.ReleaseTimeInterface); ok && !rt.Time().IsZero() {
+		ts := rt.Time().UTC()
+		v.Published = &ts
+	}
+	for _, a := range rel.Assets() {
+		v.Assets = append(v.Assets, assetViewObj{Name: a.Name(), URL: urlString(a.URL()), ContentType: a.ContentType(), Size: a.Size()})
+	}
+	return v
+}
+
+func urlString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+// //
+
+// printTag and printRelease write a JSON object where the list functions
+// write an array; as tables they look the same.
+
+func (env *envObj) printTag(v tagViewObj) error {
+	if env.json {
+		return env.printJSON(v)
+	}
+	return env.printTags([]tagViewObj{v})
+}
+
+func (env *envObj) printRelease(v releaseViewObj) error {
+	if env.json {
+		return env.printJSON(v)
+	}
+	return env.printReleases([]releaseViewObj{v})
+}
+
+func (env *envObj) printTags(list []tagViewObj) error {
+	if env.json {
+		return env.printJSON(list)
+	}
+	return env.printTable([]string{"TAG", "URL", "ZIP"}, len(list), func(i int) []string {
+		return []string{list[i].Name, list[i].URL, list[i].ZIP}
+	})
+}
+
+func (env *envObj) printReleases(list []releaseViewObj) error {
+	if env.json {
+		return env.printJSON(list)
+	}
+	return env.printTable([]string{"TAG", "NAME", "PUBLISHED", "PRERELEASE", "ASSETS", "URL"}, len(list), func(i int) []string {
+		r := list[i]
+		published := "-"
+		if r.Published != nil {
+			published = r.Published.Format(time.RFC3339)
+		}
+		pre := "no"
+		if r.Prerelease {
+			pre = "yes"
+		}
+		return []string{r.Tag, r.Name, published, pre, strconv.Itoa(len(r.Assets)), r.URL}
+	})
+}
+
+func (env *envObj) printAssets(list []assetViewObj) error {
+	if env.json {
+		return env.printJSON(list)
+	}
+	return env.printTable([]string{"NAME", "SIZE", "TYPE", "URL"}, len(list), func(i int) []string {
+		a := list[i]
+		return []string{a.Name, strconv.FormatUint(uint64(a.Size), 10), a.ContentType, a.URL}
+	})
+}
+
+func (env *envObj) printDownloads(list []downloadViewObj) error {
+	if env.json {
+		return env.printJSON(list)
+	}
+	return env.printTable([]string{"NAME", "SIZE", "PATH"}, len(list), func(i int) []string {
+		return []string{list[i].Name, strconv.FormatInt(list[i].Size, 10), list[i].Path}
+	})
+}
+
+// printArchive prints the bare URL in table mode, for use in scripts.
+func (env *envObj) printArchive(v archiveViewObj) error {
+	if env.json {
+		return env.printJSON(v)
+	}
+	_, err := fmt.Fprintln(env.stdout, v.URL)
+	return err
+}
+
+func (env *envObj) printJSON(v any) error {
+	enc := json.NewEncoder(env.stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+func (env *envObj) printTable(header []string, n int, row func(i int) []string) error {
+	w := tabwriter.NewWriter(env.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for i := 0; i < n; i++ {
+		cells := row(i)
+		for j, c := range cells {
+			if c == "" {
+				cells[j] = "-"
+			}
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
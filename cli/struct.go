@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"io"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+// commandObj is one subcommand. Run gets the arguments after the command
+// name and registers its own flags on fs next to the common ones.
+type commandObj struct {
+	name    string
+	args    string
+	summary string
+	run     func(env *envObj, fs *flag.FlagSet, args []string) error
+}
+
+// envObj is the state one invocation shares between its commands.
+type envObj struct {
+	ctx    context.Context
+	stdout io.Writer
+	stderr io.Writer
+
+	json    bool
+	timeout time.Duration
+	proxy   string
+}
+
+// //
+
+type tagViewObj struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+	ZIP  string `json:"zip,omitempty"`
+	TAR  string `json:"tar,omitempty"`
+}
+
+type assetViewObj struct {
+	Name        string `json:"name"`
+	URL         string `json:"url,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        uint32 `json:"size"`
+}
+
+type releaseViewObj struct {
+	Name       string         `json:"name"`
+	Tag        string         `json:"tag"`
+	URL        string         `json:"url,omitempty"`
+	Prerelease bool           `json:"prerelease"`
+	Published  *time.Time     `json:"published,omitempty"`
+	ZIP        string         `json:"zip,omitempty"`
+	TAR        string         `json:"tar,omitempty"`
+	Assets     []assetViewObj `json:"assets"`
+}
+
+type archiveViewObj struct {
+	Ref    string `json:"ref"`
+	Format string `json:"format"`
+	URL    string `json:"url"`
+}
+
+type downloadViewObj struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
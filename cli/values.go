@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"errors"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+// Exit codes of Run. Scripts tell the failures apart by these rather than
+// by the message on stderr.
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitNotFound    = 3
+	ExitForbidden   = 4
+	ExitRateLimited = 5
+)
+
+const (
+	// defaultTimeout bounds each API request.
+	defaultTimeout = 10 * time.Second
+
+	// defaultLimit is how many tags or releases the list commands print.
+	defaultLimit = 30
+)
+
+var (
+	ErrUsage = errors.New("invalid usage")
+)
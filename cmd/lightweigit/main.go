@@ -0,0 +1,26 @@
+// Command lightweigit looks up the tags, releases and archives of a
+// repository on any supported forge:
+//
+//	lightweigit latest https://github.com/OWNER/REPO
+//	lightweigit releases -json -limit 5 https://gitlab.com/GROUP/REPO
+//	lightweigit download -asset '*linux_amd64.tar.gz' https://github.com/OWNER/REPO v1.2.3
+//
+// Run it without arguments for the list of commands.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/voluminor/lightweigit-loader/cli"
+)
+
+// // // // // // // // // // // // // // // //
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,106 @@
+package lightweigit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+// CommitObj is one commit of a comparison.
+type CommitObj struct {
+	SHA     string
+	Author  string
+	Email   string
+	Message string
+	Date    time.Time
+}
+
+// Title is the first line of the commit message.
+func (c *CommitObj) Title() string {
+	title, _, _ := strings.Cut(c.Message, "\n")
+	return strings.TrimSpace(title)
+}
+
+// ChangedFileObj is one file changed between the two sides of a
+// comparison. Status is "added", "modified", "removed", "renamed" or
+// "copied"; OldPath is set for renames and copies.
+type ChangedFileObj struct {
+	Path      string
+	OldPath   string
+	Status    string
+	Additions int
+	Deletions int
+}
+
+// CompareObj is what changed from Base to Head: the commits reachable from
+// Head but not from Base, oldest first, and the files they changed.
+type CompareObj struct {
+	Base    string
+	Head    string
+	Commits []CommitObj
+	Files   []ChangedFileObj
+}
+
+// ProviderCompareInterface is implemented by providers that compare two
+// refs, usually tags.
+type ProviderCompareInterface interface {
+	Compare(base, head string) (*CompareObj, error)
+}
+
+// //
+
+// CompareTags compares two tags of p. Both must come from p's provider
+// family (the same Mod); the order of the arguments is base, head.
+func CompareTags(p ProviderInterface, base, head ProviderTagInterface) (*CompareObj, error) {
+	c, ok := p.(ProviderCompareInterface)
+	if !ok {
+		return nil, fmt.Errorf("%s compare: %w", p.Type(), ErrUnsupported)
+	}
+	if base == nil || head == nil {
+		return nil, errors.New("compare: nil tag")
+	}
+	if base.Mod() != head.Mod() {
+		return nil, fmt.Errorf("compare: tags of different providers (%s, %s)", base.Mod(), head.Mod())
+	}
+	return c.Compare(base.String(), head.String())
+}
+
+// DiffStat counts the added and removed lines of a unified diff. Only
+// hunk lines count, so "---" / "+++" file headers are skipped.
+func DiffStat(diff string) (int, int) {
+	add, del := 0, 0
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case strings.HasPrefix(line, "diff "):
+			inHunk = false
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			add++
+		case strings.HasPrefix(line, "-"):
+			del++
+		}
+	}
+	return add, del
+}
+
+// FileStatus maps the forges' file status words onto the ChangedFileObj
+// ones.
+func FileStatus(s string) string {
+	switch strings.ToLower(s) {
+	case "added", "add", "new":
+		return "added"
+	case "removed", "deleted", "delete", "remove":
+		return "removed"
+	case "renamed", "rename":
+		return "renamed"
+	case "copied", "copy":
+		return "copied"
+	}
+	return "modified"
+}
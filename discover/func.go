@@ -0,0 +1,111 @@
+package discover
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/local"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+// Name is the remote name, e.g. "origin".
+func (obj *RemoteObj) Name() string {
+	return obj.name
+}
+
+// Raw is the URL as written in the git config.
+func (obj *RemoteObj) Raw() string {
+	return obj.raw
+}
+
+// URL is Raw after insteadOf rewrites and ssh aliases.
+func (obj *RemoteObj) URL() string {
+	return obj.url
+}
+
+// GitDir is the git directory of the checkout the remote was read from.
+func (obj *RemoteObj) GitDir() string {
+	return obj.gitDir
+}
+
+func (obj *RemoteObj) String() string {
+	return obj.name + " " + obj.url
+}
+
+// // // //
+
+// GitDir is local.GitDir: the git directory of the checkout containing dir.
+func GitDir(dir string) (string, error) {
+	return local.GitDir(dir)
+}
+
+// Remote finds the checkout containing dir and reads one of its remotes:
+// name, or "origin", or the only remote when name is "" and there is no
+// origin. The URL goes through url.<base>.insteadOf from the repository
+// and user git configs and through Host aliases in ~/.ssh/config.
+func Remote(dir, name string) (*RemoteObj, error) {
+	gitDir, err := local.GitDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c, err := loadConfig(gitDir)
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := c.subsections("remote", "url")
+	if name == "" {
+		switch {
+		case contains(remotes, "origin"):
+			name = "origin"
+		case len(remotes) == 1:
+			name = remotes[0]
+		case len(remotes) == 0:
+			return nil, fmt.Errorf("%s: no remotes configured: %w", gitDir, lightweigit.ErrNotFound)
+		default:
+			sort.Strings(remotes)
+			return nil, fmt.Errorf("%s: no origin and several remotes (%s): pick one", gitDir, strings.Join(remotes, ", "))
+		}
+	}
+
+	// With several url values git fetches from the first one.
+	urls := c.value["remote."+name+".url"]
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%s: remote %q: %w", gitDir, name, lightweigit.ErrNotFound)
+	}
+	raw := urls[0]
+
+	u, err := resolveSSHAlias(c.rewriteURL(raw))
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteObj{name: name, raw: raw, url: u, gitDir: gitDir}, nil
+}
+
+// Parse hands the remote of the checkout containing dir to global.Parse;
+// see Remote for how the remote is chosen.
+func Parse(dir, name string) (lightweigit.ProviderInterface, *RemoteObj, error) {
+	r, err := Remote(dir, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := global.Parse(r.url)
+	if err != nil {
+		return nil, r, fmt.Errorf("remote %s: %w", r.name, err)
+	}
+	return p, r, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
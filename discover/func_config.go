@@ -0,0 +1,234 @@
+package discover
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader/local"
+)
+
+// // // // // // // // // // // // // // // //
+
+// maxIncludeDepth matches git's own limit on nested include.path.
+const maxIncludeDepth = 10
+
+func (c *configObj) add(key, value string) {
+	if c.value == nil {
+		c.value = make(map[string][]string)
+	}
+	if _, ok := c.value[key]; !ok {
+		c.keys = append(c.keys, key)
+	}
+	c.value[key] = append(c.value[key], value)
+}
+
+// subsections lists the subsections of section that have key, in file
+// order.
+func (c *configObj) subsections(section, key string) []string {
+	var out []string
+	for _, k := range c.keys {
+		if len(k) <= len(section)+len(key)+2 || !strings.HasPrefix(k, section+".") || !strings.HasSuffix(k, "."+key) {
+			continue
+		}
+		out = append(out, k[len(section)+1:len(k)-len(key)-1])
+	}
+	return out
+}
+
+// // // //
+
+// readFile parses the git config file at p into c, following
+// include.path. A missing file is not an error.
+func (c *configObj) readFile(p string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("%s: include nesting too deep", p)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	var (
+		section string
+		lineNo  int
+		pending string
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lineNo++
+		line := pending + sc.Text()
+		pending = ""
+
+		// A backslash at the end of a line continues the value.
+		if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			pending = strings.TrimSuffix(line, "\\")
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.LastIndex(line, "]")
+			if end < 0 {
+				return fmt.Errorf("%s:%d: bad section header", p, lineNo)
+			}
+			section, err = parseSection(line[1:end])
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", p, lineNo, err)
+			}
+			rest := strings.TrimSpace(line[end+1:])
+			if rest == "" || rest[0] == '#' || rest[0] == ';' {
+				continue
+			}
+			line = rest
+		}
+		if section == "" {
+			return fmt.Errorf("%s:%d: key outside of a section", p, lineNo)
+		}
+
+		key, value := line, "true"
+		if i := strings.Index(line, "="); i >= 0 {
+			key, value = strings.TrimSpace(line[:i]), parseValue(line[i+1:])
+		}
+		full := section + "." + strings.ToLower(key)
+		c.add(full, value)
+
+		if full == "include.path" {
+			inc := expandHome(value)
+			if !filepath.IsAbs(inc) {
+				inc = filepath.Join(filepath.Dir(p), inc)
+			}
+			if err := c.readFile(inc, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return sc.Err()
+}
+
+// parseSection turns `remote "origin"` into "remote.origin" and the legacy
+// `remote.origin` into the same.
+func parseSection(h string) (string, error) {
+	h = strings.TrimSpace(h)
+	if i := strings.IndexAny(h, " \t"); i >= 0 {
+		name := strings.ToLower(h[:i])
+		sub := strings.TrimSpace(h[i:])
+		if len(sub) < 2 || sub[0] != '"' || sub[len(sub)-1] != '"' {
+			return "", fmt.Errorf("bad subsection in [%s]", h)
+		}
+		sub = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(sub[1 : len(sub)-1])
+		return name + "." + sub, nil
+	}
+	if h == "" {
+		return "", errors.New("empty section name")
+	}
+	if i := strings.Index(h, "."); i >= 0 {
+		return strings.ToLower(h[:i]) + "." + strings.ToLower(h[i+1:]), nil
+	}
+	return strings.ToLower(h), nil
+}
+
+// parseValue handles quotes, escapes and trailing comments of a value.
+func parseValue(s string) string {
+	var (
+		b      strings.Builder
+		quoted bool
+		space  string
+	)
+	s = strings.TrimSpace(s)
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '"':
+			quoted = !quoted
+			continue
+		case ch == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				ch = '\n'
+			case 't':
+				ch = '\t'
+			case 'b':
+				ch = '\b'
+			default:
+				ch = s[i]
+			}
+		case !quoted && (ch == '#' || ch == ';'):
+			return b.String()
+		case !quoted && (ch == ' ' || ch == '\t'):
+			// Inner runs of blanks survive, trailing ones do not.
+			space += string(ch)
+			continue
+		}
+		b.WriteString(space)
+		space = ""
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, p[1:])
+		}
+	}
+	return p
+}
+
+// // // //
+
+// loadConfig reads the configs git consults for remotes and URL rewrites,
+// lowest precedence first: $XDG_CONFIG_HOME/git/config, ~/.gitconfig and
+// the repository's config.
+func loadConfig(gitDir string) (*configObj, error) {
+	c := new(configObj)
+
+	var files []string
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		files = append(files, filepath.Join(xdg, "git", "config"))
+	} else if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".config", "git", "config"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".gitconfig"))
+	}
+	files = append(files, filepath.Join(local.CommonDir(gitDir), "config"))
+
+	for _, p := range files {
+		if err := c.readFile(p, 0); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// rewriteURL applies the longest matching url.<base>.insteadOf, as git
+// does; pushInsteadOf is left alone since only fetching matters here.
+func (c *configObj) rewriteURL(raw string) string {
+	var best, bestFrom string
+	for _, base := range c.subsections("url", "insteadof") {
+		for _, from := range c.value["url."+base+".insteadof"] {
+			if strings.HasPrefix(raw, from) && len(from) > len(bestFrom) {
+				best, bestFrom = base, from
+			}
+		}
+	}
+	if bestFrom == "" {
+		return raw
+	}
+	return best + raw[len(bestFrom):]
+}
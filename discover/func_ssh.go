@@ -0,0 +1,174 @@
+package discover
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+// readSSHConfig parses the Host blocks of an ssh config. Match blocks are
+// skipped and Include is not followed. A missing file gives no blocks.
+func readSSHConfig(p string) ([]sshHostObj, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	// Options before the first Host apply to every host.
+	hosts := []sshHostObj{{patterns: []string{"*"}, opts: map[string]string{}}}
+	skip := false
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		key, value := line, ""
+		if i := strings.IndexAny(line, " \t="); i >= 0 {
+			key = line[:i]
+			value = strings.TrimLeft(line[i:], " \t=")
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch strings.ToLower(key) {
+		case "host":
+			hosts = append(hosts, sshHostObj{patterns: strings.Fields(value), opts: map[string]string{}})
+			skip = false
+			continue
+		case "match":
+			skip = true
+			continue
+		}
+		if skip {
+			continue
+		}
+
+		// The first value obtained for an option wins.
+		opts := hosts[len(hosts)-1].opts
+		if _, ok := opts[strings.ToLower(key)]; !ok {
+			opts[strings.ToLower(key)] = value
+		}
+	}
+	return hosts, sc.Err()
+}
+
+// matchHost applies ssh's pattern rules: any positive pattern must match
+// and no negated one may.
+func (h sshHostObj) matchHost(host string) bool {
+	ok := false
+	for _, p := range h.patterns {
+		neg := strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		m, _ := path.Match(strings.ToLower(p), strings.ToLower(host))
+		if m && neg {
+			return false
+		}
+		if m {
+			ok = true
+		}
+	}
+	return ok
+}
+
+// sshOptions collects the options ssh would use for alias, first match
+// first.
+func sshOptions(hosts []sshHostObj, alias string) map[string]string {
+	out := map[string]string{}
+	for _, h := range hosts {
+		if !h.matchHost(alias) {
+			continue
+		}
+		for k, v := range h.opts {
+			if _, ok := out[k]; !ok {
+				out[k] = v
+			}
+		}
+	}
+	return out
+}
+
+// // // //
+
+// sshTarget splits an SSH remote into user, host, port and path. ok is
+// false for other transports.
+func sshTarget(raw string) (user, host, port, p string, ok bool) {
+	switch {
+	case strings.HasPrefix(raw, "ssh://"), strings.HasPrefix(raw, "git+ssh://"), strings.HasPrefix(raw, "ssh+git://"):
+		rest := raw[strings.Index(raw, "://")+3:]
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			return "", "", "", "", false
+		}
+		host, p = rest[:i], rest[i+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			user, host = host[:at], host[at+1:]
+		}
+		if c := strings.LastIndex(host, ":"); c >= 0 && !strings.HasSuffix(host, "]") {
+			host, port = host[:c], host[c+1:]
+		}
+		return user, strings.Trim(host, "[]"), port, p, true
+	case strings.Contains(raw, "://"):
+		return "", "", "", "", false
+	}
+
+	// scp-like: [user@]host:path, where host has no slash before the colon.
+	c := strings.Index(raw, ":")
+	if c <= 0 || strings.Contains(raw[:c], "/") {
+		return "", "", "", "", false
+	}
+	host, p = raw[:c], raw[c+1:]
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		user, host = host[:at], host[at+1:]
+	}
+	return user, host, "", p, true
+}
+
+// resolveSSHAlias rewrites an SSH remote through ~/.ssh/config: HostName,
+// User and Port of the matching Host blocks replace the alias. The result
+// is scp-like unless a port has to be kept. Other URLs are returned as is.
+func resolveSSHAlias(raw string) (string, error) {
+	user, host, port, p, ok := sshTarget(raw)
+	if !ok {
+		return raw, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return raw, nil
+	}
+	hosts, err := readSSHConfig(filepath.Join(home, ".ssh", "config"))
+	if err != nil {
+		return "", err
+	}
+
+	opts := sshOptions(hosts, host)
+	real := host
+	if v, ok := opts["hostname"]; ok && v != "" {
+		real = strings.NewReplacer("%h", host, "%%", "%").Replace(v)
+	}
+	if user == "" {
+		user = opts["user"]
+	}
+	if user == "" {
+		user = "git"
+	}
+	if port == "" {
+		port = opts["port"]
+	}
+
+	if port != "" && port != "22" {
+		return "ssh://" + user + "@" + real + ":" + port + "/" + strings.TrimPrefix(p, "/"), nil
+	}
+	return user + "@" + real + ":" + strings.TrimPrefix(p, "/"), nil
+}
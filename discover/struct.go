@@ -0,0 +1,25 @@
+package discover
+
+// // // // // // // // // // // // // // // //
+
+// RemoteObj is a remote of a local checkout with its URL rewritten the way
+// git and ssh would see it.
+type RemoteObj struct {
+	name   string
+	raw    string
+	url    string
+	gitDir string
+}
+
+// configObj is a flattened git config: "section.subsection.key" (section
+// and key lower-cased, subsection as written) to its values in file order.
+type configObj struct {
+	keys  []string
+	value map[string][]string
+}
+
+// sshHostObj is one "Host" block of an ssh config.
+type sshHostObj struct {
+	patterns []string
+	opts     map[string]string
+}
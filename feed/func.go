@@ -0,0 +1,177 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/markdown"
+)
+
+// // // // // // // // // // // // // // // //
+
+func (f FormatType) String() string {
+	switch f {
+	case FormatAtom:
+		return "atom"
+	case FormatRSS:
+		return "rss"
+	case FormatJSON:
+		return "json"
+	}
+	return "unknown"
+}
+
+// ContentType is the media type the format is served with.
+func (f FormatType) ContentType() string {
+	switch f {
+	case FormatRSS:
+		return "application/rss+xml; charset=utf-8"
+	case FormatJSON:
+		return "application/feed+json; charset=utf-8"
+	}
+	return "application/atom+xml; charset=utf-8"
+}
+
+// ParseFormat is the inverse of FormatType.String.
+func ParseFormat(s string) (FormatType, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "atom":
+		return FormatAtom, true
+	case "rss":
+		return FormatRSS, true
+	case "json", "jsonfeed":
+		return FormatJSON, true
+	}
+	return FormatAtom, false
+}
+
+// //
+
+// Build reads the releases of repos and merges them into one feed, newest
+// first. Releases without a publication time follow the dated ones in
+// the order their providers listed them.
+func Build(ctx context.Context, opts OptionsObj, repos ...lightweigit.ProviderInterface) (*FeedObj, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(repos) == 0 {
+		return nil, errors.New("feed: no repositories")
+	}
+	if opts.PerRepo <= 0 {
+		opts.PerRepo = defaultPerRepo
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultLimit
+	}
+
+	f := &FeedObj{Title: opts.Title, Link: opts.Link, Self: opts.Self}
+	if len(repos) == 1 {
+		if f.Title == "" {
+			f.Title = "Releases of " + repos[0].String()
+		}
+		if u := repos[0].URL(); f.Link == "" && u != nil {
+			f.Link = u.String()
+		}
+	}
+	if f.Title == "" {
+		f.Title = "Releases"
+	}
+
+	for _, p := range repos {
+		if p == nil {
+			return nil, errors.New("feed: nil provider")
+		}
+		rels, err := collect(ctx, p, opts.PerRepo)
+		if err != nil {
+			return nil, fmt.Errorf("feed: %s: %w", p, err)
+		}
+		for _, rel := range rels {
+			if opts.SkipPrereleases && rel.IsPrerelease() {
+				continue
+			}
+			f.Entries = append(f.Entries, entryOf(p, rel, len(repos) > 1))
+		}
+	}
+
+	sort.SliceStable(f.Entries, func(i, j int) bool {
+		a, b := f.Entries[i].Published, f.Entries[j].Published
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.After(b)
+	})
+	if len(f.Entries) > opts.Limit {
+		f.Entries = f.Entries[:opts.Limit]
+	}
+
+	for _, e := range f.Entries {
+		if e.Published.After(f.Updated) {
+			f.Updated = e.Published
+		}
+	}
+	if f.Updated.IsZero() {
+		f.Updated = time.Now().UTC().Truncate(time.Second)
+	}
+	return f, nil
+}
+
+// collect reads up to limit releases of p in the provider's order.
+func collect(ctx context.Context, p lightweigit.ProviderInterface, limit int) ([]lightweigit.ProviderReleaseInterface, error) {
+	out := make(chan lightweigit.ProviderReleaseInterface)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- p.ReleasesStream(ctx, out, limit)
+		close(out)
+	}()
+
+	var list []lightweigit.ProviderReleaseInterface
+	for rel := range out {
+		list = append(list, rel)
+	}
+	return list, <-errc
+}
+
+// entryOf describes rel. In a feed of several repositories the title
+// names the repository too.
+func entryOf(p lightweigit.ProviderInterface, rel lightweigit.ProviderReleaseInterface, named bool) EntryObj {
+	e := EntryObj{
+		Title:      strings.TrimSpace(rel.Name()),
+		Repo:       p.String(),
+		HTML:       markdown.ReleaseHTML(p, rel),
+		Text:       strings.TrimSpace(markdown.ReleaseText(p, rel)),
+		Prerelease: rel.IsPrerelease(),
+	}
+	if tag := rel.Tag(); tag != nil {
+		e.Tag = tag.String()
+	}
+	if e.Title == "" {
+		e.Title = e.Tag
+	}
+	if named {
+		e.Title = e.Repo + ": " + e.Title
+	}
+	if u := rel.URL(); u != nil {
+		e.URL = u.String()
+	}
+
+	if rt, ok := rel.(lightweigit.ReleaseTimeInterface); ok {
+		e.Published = rt.Time()
+	} else if tt, ok := rel.Tag().(interface{ Time() time.Time }); ok {
+		// Local repositories know the tag's date only.
+		e.Published = tt.Time()
+	}
+	e.Published = e.Published.UTC()
+
+	// The ID must stay put across rebuilds; the URL does, where there is one.
+	e.ID = e.URL
+	if e.ID == "" {
+		e.ID = "urn:lightweigit:" + url.PathEscape(lightweigit.Identity(p).Key()) + ":" + url.PathEscape(e.Tag)
+	}
+	return e
+}
@@ -0,0 +1,122 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// NewHandler serves the feed of repos. The format comes from the "format"
+// query parameter (atom, rss or json), else from the Accept header, and
+// defaults to Atom.
+func NewHandler(opts OptionsObj, repos ...lightweigit.ProviderInterface) *HandlerObj {
+	if opts.TTL <= 0 {
+		opts.TTL = defaultTTL
+	}
+	return &HandlerObj{opts: opts, repos: repos}
+}
+
+// ServeHTTP answers GET and HEAD with Cache-Control, ETag and
+// Last-Modified set, and 304 to a matching conditional request. A failed
+// rebuild keeps serving the last feed; without one it answers 502.
+func (h *HandlerObj) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	f, age, err := h.get(r.Context())
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+
+	format := negotiate(r)
+	view := *f
+	if view.Self == "" {
+		view.Self = selfURL(r)
+	}
+	body := view.Render(format)
+	sum := sha256.Sum256(body)
+
+	hdr := w.Header()
+	hdr.Set("Content-Type", format.ContentType())
+	hdr.Set("Vary", "Accept")
+	hdr.Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	maxAge := h.opts.TTL - age
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	hdr.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge/time.Second)))
+
+	http.ServeContent(w, r, "", f.Updated, bytes.NewReader(body))
+}
+
+// get returns the cached feed and its age, rebuilding it once the TTL has
+// passed. Concurrent requests wait for one rebuild.
+func (h *HandlerObj) get(ctx context.Context) (*FeedObj, time.Duration, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.feed != nil && time.Since(h.built) < h.opts.TTL {
+		return h.feed, time.Since(h.built), nil
+	}
+
+	f, err := Build(ctx, h.opts, h.repos...)
+	if err != nil {
+		if h.feed == nil {
+			return nil, 0, err
+		}
+		if h.opts.OnError != nil {
+			h.opts.OnError(err)
+		}
+		return h.feed, time.Since(h.built), nil
+	}
+	h.feed, h.built = f, time.Now()
+	return f, 0, nil
+}
+
+// Invalidate drops the cached feed, for example after a webhook reported
+// a new release.
+func (h *HandlerObj) Invalidate() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.feed = nil
+}
+
+// //
+
+func negotiate(r *http.Request) FormatType {
+	if f, ok := ParseFormat(r.URL.Query().Get("format")); ok {
+		return f
+	}
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/atom+xml"):
+		return FormatAtom
+	case strings.Contains(accept, "application/rss+xml"):
+		return FormatRSS
+	case strings.Contains(accept, "application/feed+json"), strings.Contains(accept, "application/json"):
+		return FormatJSON
+	}
+	return FormatAtom
+}
+
+// selfURL rebuilds the URL the request was made to.
+func selfURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
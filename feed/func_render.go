@@ -0,0 +1,251 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+type atomLinkObj struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomTextObj struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomPersonObj struct {
+	Name string `xml:"name"`
+}
+
+type atomCategoryObj struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntryObj struct {
+	ID        string            `xml:"id"`
+	Title     string            `xml:"title"`
+	Updated   string            `xml:"updated"`
+	Published string            `xml:"published,omitempty"`
+	Author    atomPersonObj     `xml:"author"`
+	Links     []atomLinkObj     `xml:"link"`
+	Category  []atomCategoryObj `xml:"category"`
+	Content   *atomTextObj      `xml:"content,omitempty"`
+}
+
+type atomFeedObj struct {
+	XMLName   xml.Name       `xml:"feed"`
+	NS        string         `xml:"xmlns,attr"`
+	ID        string         `xml:"id"`
+	Title     string         `xml:"title"`
+	Updated   string         `xml:"updated"`
+	Generator string         `xml:"generator"`
+	Links     []atomLinkObj  `xml:"link"`
+	Entries   []atomEntryObj `xml:"entry"`
+}
+
+// Atom renders the feed as Atom 1.0. Undated entries take the feed's
+// update time, since Atom requires one.
+func (f *FeedObj) Atom() []byte {
+	doc := atomFeedObj{
+		NS:        atomNS,
+		ID:        firstOf(f.Self, f.Link, "urn:lightweigit:feed:"+f.Title),
+		Title:     f.Title,
+		Updated:   f.Updated.Format(time.RFC3339),
+		Generator: target.Name,
+	}
+	if f.Link != "" {
+		doc.Links = append(doc.Links, atomLinkObj{Rel: "alternate", Type: "text/html", Href: f.Link})
+	}
+	if f.Self != "" {
+		doc.Links = append(doc.Links, atomLinkObj{Rel: "self", Type: "application/atom+xml", Href: f.Self})
+	}
+
+	for _, e := range f.Entries {
+		updated := e.Published
+		if updated.IsZero() {
+			updated = f.Updated
+		}
+		ae := atomEntryObj{
+			ID:      e.ID,
+			Title:   e.Title,
+			Updated: updated.Format(time.RFC3339),
+			Author:  atomPersonObj{Name: e.Repo},
+		}
+		if !e.Published.IsZero() {
+			ae.Published = ae.Updated
+		}
+		if e.URL != "" {
+			ae.Links = append(ae.Links, atomLinkObj{Rel: "alternate", Type: "text/html", Href: e.URL})
+		}
+		if e.Prerelease {
+			ae.Category = append(ae.Category, atomCategoryObj{Term: "prerelease"})
+		}
+		if e.HTML != "" {
+			ae.Content = &atomTextObj{Type: "html", Body: e.HTML}
+		}
+		doc.Entries = append(doc.Entries, ae)
+	}
+	return encodeXML(doc)
+}
+
+// // // //
+
+type rssGUIDObj struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Body        string `xml:",chardata"`
+}
+
+type rssItemObj struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link,omitempty"`
+	GUID        rssGUIDObj `xml:"guid"`
+	PubDate     string     `xml:"pubDate,omitempty"`
+	Category    []string   `xml:"category"`
+	Description string     `xml:"description,omitempty"`
+}
+
+type rssChannelObj struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Description   string       `xml:"description"`
+	LastBuildDate string       `xml:"lastBuildDate"`
+	Generator     string       `xml:"generator"`
+	Items         []rssItemObj `xml:"item"`
+}
+
+type rssObj struct {
+	XMLName xml.Name      `xml:"rss"`
+	Version string        `xml:"version,attr"`
+	Channel rssChannelObj `xml:"channel"`
+}
+
+// RSS renders the feed as RSS 2.0, with the notes as HTML descriptions.
+func (f *FeedObj) RSS() []byte {
+	doc := rssObj{
+		Version: "2.0",
+		Channel: rssChannelObj{
+			Title:         f.Title,
+			Link:          firstOf(f.Link, f.Self),
+			Description:   f.Title,
+			LastBuildDate: f.Updated.Format(time.RFC1123Z),
+			Generator:     target.Name,
+		},
+	}
+	for _, e := range f.Entries {
+		it := rssItemObj{
+			Title:       e.Title,
+			Link:        e.URL,
+			GUID:        rssGUIDObj{IsPermaLink: e.ID == e.URL, Body: e.ID},
+			Description: e.HTML,
+		}
+		if !e.Published.IsZero() {
+			it.PubDate = e.Published.Format(time.RFC1123Z)
+		}
+		if e.Prerelease {
+			it.Category = append(it.Category, "prerelease")
+		}
+		doc.Channel.Items = append(doc.Channel.Items, it)
+	}
+	return encodeXML(doc)
+}
+
+// // // //
+
+type jsonAuthorObj struct {
+	Name string `json:"name"`
+}
+
+type jsonItemObj struct {
+	ID            string          `json:"id"`
+	URL           string          `json:"url,omitempty"`
+	Title         string          `json:"title"`
+	ContentHTML   string          `json:"content_html"`
+	ContentText   string          `json:"content_text,omitempty"`
+	DatePublished string          `json:"date_published,omitempty"`
+	Authors       []jsonAuthorObj `json:"authors,omitempty"`
+	Tags          []string        `json:"tags,omitempty"`
+}
+
+type jsonFeedObj struct {
+	Version     string        `json:"version"`
+	Title       string        `json:"title"`
+	HomePageURL string        `json:"home_page_url,omitempty"`
+	FeedURL     string        `json:"feed_url,omitempty"`
+	Items       []jsonItemObj `json:"items"`
+}
+
+// JSON renders the feed as JSON Feed 1.1 with both renderings of the
+// notes.
+func (f *FeedObj) JSON() []byte {
+	doc := jsonFeedObj{
+		Version:     jsonFeedVersion,
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.Self,
+		Items:       make([]jsonItemObj, 0, len(f.Entries)),
+	}
+	for _, e := range f.Entries {
+		it := jsonItemObj{
+			ID:          e.ID,
+			URL:         e.URL,
+			Title:       e.Title,
+			ContentHTML: e.HTML,
+			ContentText: e.Text,
+			Authors:     []jsonAuthorObj{{Name: e.Repo}},
+		}
+		if !e.Published.IsZero() {
+			it.DatePublished = e.Published.Format(time.RFC3339)
+		}
+		if e.Prerelease {
+			it.Tags = append(it.Tags, "prerelease")
+		}
+		doc.Items = append(doc.Items, it)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.Encode(doc)
+	return buf.Bytes()
+}
+
+// Render renders the feed in format.
+func (f *FeedObj) Render(format FormatType) []byte {
+	switch format {
+	case FormatRSS:
+		return f.RSS()
+	case FormatJSON:
+		return f.JSON()
+	}
+	return f.Atom()
+}
+
+// //
+
+func encodeXML(doc any) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	enc.Encode(doc)
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func firstOf(s ...string) string {
+	for _, v := range s {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
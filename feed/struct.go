@@ -0,0 +1,82 @@
+package feed
+
+import (
+	"sync"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// FormatType is a feed format.
+type FormatType byte
+
+const (
+	FormatAtom FormatType = iota
+	FormatRSS
+	FormatJSON
+)
+
+// OptionsObj tunes Build and Handler. The zero value reads 20 releases of
+// each repository and keeps the newest 50.
+type OptionsObj struct {
+	// Title and Link describe the feed. With one repository they default
+	// to its name and web page.
+	Title string
+	Link  string
+
+	// Self is the URL the feed is served at. Handler fills it from the
+	// request when empty.
+	Self string
+
+	PerRepo int
+	Limit   int
+
+	SkipPrereleases bool
+
+	// TTL is how long Handler serves a built feed before building it
+	// again. The default is ten minutes.
+	TTL time.Duration
+
+	// OnError receives the error of a rebuild that failed while a stale
+	// feed was still served.
+	OnError func(error)
+}
+
+// EntryObj is one release of the feed. HTML is the sanitized rendering
+// of its notes and Text the plain one.
+type EntryObj struct {
+	ID         string
+	Title      string
+	URL        string
+	Repo       string
+	Tag        string
+	HTML       string
+	Text       string
+	Prerelease bool
+
+	// Published is zero for releases of providers that report no time.
+	Published time.Time
+}
+
+// FeedObj is a built feed; Atom, RSS and JSON render it. Updated is the
+// newest publication time, or when the feed was built if no entry has one.
+type FeedObj struct {
+	Title   string
+	Link    string
+	Self    string
+	Updated time.Time
+	Entries []EntryObj
+}
+
+// HandlerObj serves a feed of a fixed set of repositories, rebuilt at
+// most once per TTL.
+type HandlerObj struct {
+	opts  OptionsObj
+	repos []lightweigit.ProviderInterface
+
+	mu    sync.Mutex
+	feed  *FeedObj
+	built time.Time
+}
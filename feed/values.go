@@ -0,0 +1,21 @@
+package feed
+
+import (
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+const (
+	// defaultPerRepo is how many releases are read from each repository.
+	defaultPerRepo = 20
+
+	// defaultLimit caps the entries of the whole feed.
+	defaultLimit = 50
+
+	// defaultTTL is how long a Handler serves a feed before rebuilding it.
+	defaultTTL = 10 * time.Minute
+
+	jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+	atomNS          = "http://www.w3.org/2005/Atom"
+)
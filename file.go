@@ -0,0 +1,87 @@
+package lightweigit
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+// FileObj is one file read at a ref.
+type FileObj struct {
+	// Path is the cleaned repository path, without a leading slash.
+	Path string
+	Ref  string
+
+	// SHA is the git blob name of the content: what git stores it under
+	// (SHA-1 unless a local repository uses SHA-256).
+	SHA string
+
+	Content []byte
+}
+
+func (f *FileObj) Size() int {
+	return len(f.Content)
+}
+
+func (f *FileObj) String() string {
+	return string(f.Content)
+}
+
+// ProviderFileInterface is implemented by providers that read single files
+// at a ref (a branch, tag or commit SHA) without fetching an archive.
+// Missing files and refs give ErrNotFound, files over MaxFileSize
+// ErrResponseTooLarge.
+type ProviderFileInterface interface {
+	File(ref, path string) (*FileObj, error)
+}
+
+// TagFileInterface is implemented by tags that read files at themselves.
+type TagFileInterface interface {
+	File(path string) (*FileObj, error)
+}
+
+// //
+
+// BlobSHA is the SHA-1 git blob name of content.
+func BlobSHA(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CleanPath normalizes a repository path for File: slashes only, no
+// leading slash, no "." or ".." escaping the root.
+func CleanPath(p string) (string, error) {
+	p = path.Clean("/" + strings.ReplaceAll(strings.TrimSpace(p), "\\", "/"))
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return "", errors.New("empty file path")
+	}
+	return p, nil
+}
+
+// EscapePath escapes each segment of a slash-separated path.
+func EscapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, s := range parts {
+		parts[i] = url.PathEscape(s)
+	}
+	return strings.Join(parts, "/")
+}
+
+// NewFile wraps content read at ref and names its blob.
+func NewFile(ref, p string, content []byte) *FileObj {
+	return &FileObj{
+		Path:    p,
+		Ref:     ref,
+		SHA:     BlobSHA(content),
+		Content: content,
+	}
+}
@@ -3,6 +3,7 @@ package lightweigit
 import (
 	"bytes"
 	"compress/flate"
+	"context"
 	"encoding/binary"
 	"encoding/gob"
 	"encoding/json"
@@ -25,44 +26,107 @@ func UserAgent(obj ProviderInterface) string {
 }
 
 func GetJSON(obj ProviderInterface, u string, out any) error {
-	req, err := http.NewRequest(http.MethodGet, u, nil)
+	b, err := get(obj, u, "application/json", maxJSONBody)
 	if err != nil {
 		return err
 	}
+	return json.Unmarshal(b, out)
+}
+
+// GetRaw fetches u as is, for file contents. Bodies over MaxFileSize give
+// ErrResponseTooLarge; HTTP errors are reported as by GetJSON.
+func GetRaw(obj ProviderInterface, u string) ([]byte, error) {
+	return get(obj, u, "*/*", MaxFileSize)
+}
+
+func get(obj ProviderInterface, u, accept string, limit int64) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Set("User-Agent", UserAgent(obj))
-	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept", accept)
 
 	resp, err := HttpClient.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return ErrNotFound
+		return nil, ErrNotFound
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
 		detail := strings.TrimSpace(string(b))
 		switch resp.StatusCode {
 		case http.StatusForbidden:
-			return fmt.Errorf("%s api error: %s: %s: %w", obj.Type(), resp.Status, detail, ErrForbidden)
+			return nil, fmt.Errorf("%s api error: %s: %s: %w", obj.Type(), resp.Status, detail, ErrForbidden)
 		case http.StatusTooManyRequests:
-			return fmt.Errorf("%s api error: %s: %s: %w", obj.Type(), resp.Status, detail, ErrTooManyRequests)
+			return nil, fmt.Errorf("%s api error: %s: %s: %w", obj.Type(), resp.Status, detail, ErrTooManyRequests)
 		}
-		return fmt.Errorf("%s api error: %s: %s", obj.Type(), resp.Status, detail)
+		return nil, fmt.Errorf("%s api error: %s: %s", obj.Type(), resp.Status, detail)
 	}
 
 	// Read one byte past the cap: hitting it means the body was cut, so
 	// decoding would fail with a misleading JSON error. Report it explicitly.
-	b, err := io.ReadAll(io.LimitReader(resp.Body, maxJSONBody+1))
+	b, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if len(b) > maxJSONBody {
-		return fmt.Errorf("%s api: body over %d bytes: %w", obj.Type(), maxJSONBody, ErrResponseTooLarge)
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("%s api: body over %d bytes: %w", obj.Type(), limit, ErrResponseTooLarge)
 	}
-	return json.Unmarshal(b, out)
+	return b, nil
+}
+
+// Download starts a GET of a release asset or archive u and returns its
+// body. It goes through the transport of HttpClient but without its
+// timeout, which is sized for API calls rather than downloads; ctx bounds
+// it instead. HTTP errors map to the same sentinels as GetJSON.
+func Download(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	if u == nil {
+		return nil, fmt.Errorf("no download URL: %w", ErrNotFound)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", target.Name+" "+target.Version)
+	req.Header.Set("Accept", "application/octet-stream")
+
+	client := &http.Client{Transport: HttpClient.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.Body, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("download %s: %w", resp.Status, ErrNotFound)
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	detail := strings.TrimSpace(string(b))
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("download %s: %s: %w", resp.Status, detail, ErrForbidden)
+	case http.StatusTooManyRequests:
+		return nil, fmt.Errorf("download %s: %s: %w", resp.Status, detail, ErrTooManyRequests)
+	}
+	return nil, fmt.Errorf("download %s: %s", resp.Status, detail)
+}
+
+// IsRateLimit reports whether err is a rate-limit answer: a 429, or a 403
+// whose body says so, which is how GitHub answers its primary and
+// secondary limits. Other 403s are plain refusals and are not retried.
+func IsRateLimit(err error) bool {
+	if errors.Is(err, ErrTooManyRequests) {
+		return true
+	}
+	return errors.Is(err, ErrForbidden) && strings.Contains(strings.ToLower(err.Error()), "rate limit")
 }
 
 // //
@@ -118,7 +182,7 @@ func Unmarshal(data []byte, a any) (target.ModType, error) {
 
 	m := target.ModType(data[0])
 
-	if m.String() == "unknown" {
+	if m.String() == "unknown" && !Registry.hasMod(m) {
 		return m, fmt.Errorf("unknown mod type")
 	}
 
@@ -3,6 +3,7 @@ package github
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/voluminor/lightweigit-loader"
 )
@@ -35,3 +36,35 @@ func (obj *Obj) URL() *url.URL {
 		"",
 	)
 }
+
+// Identity folds the name to lower case: GitHub matches owners and
+// repositories case-insensitively.
+func (obj *Obj) Identity() lightweigit.IdentityObj {
+	return lightweigit.IdentityObj{
+		Family: "github",
+		Host:   obj.Domain(),
+		Name:   strings.ToLower(obj.name),
+	}
+}
+
+func (obj *Obj) CloneHTTPS() string {
+	return "https://github.com/" + obj.name + ".git"
+}
+
+func (obj *Obj) CloneSSH() string {
+	return "git@github.com:" + obj.name + ".git"
+}
+
+// //
+
+func (obj *Obj) IssueURL(n int) string {
+	return fmt.Sprintf("https://github.com/%s/issues/%d", obj.name, n)
+}
+
+func (obj *Obj) UserURL(name string) string {
+	return lightweigit.BuildURL("https", "github.com", name, "").String()
+}
+
+func (obj *Obj) FileURL(ref, path string) string {
+	return lightweigit.AddURL(obj.URL(), "/blob/"+ref+"/"+strings.TrimPrefix(path, "/"), "").String()
+}
@@ -2,6 +2,7 @@ package github
 
 import (
 	"net/url"
+	"time"
 
 	"github.com/voluminor/lightweigit-loader"
 	"github.com/voluminor/lightweigit-loader/target"
@@ -60,6 +61,7 @@ type byteReleaseObj struct {
 	Name         string
 	BodyMD       string
 	IsPrerelease bool
+	Published    int64
 	Assets       []byteAssetObj
 }
 
@@ -81,6 +83,9 @@ func (rel *ReleaseObj) Marshal() []byte {
 		IsPrerelease: rel.isPrerelease,
 		Assets:       make([]byteAssetObj, 0),
 	}
+	if !rel.published.IsZero() {
+		dataObj.Published = rel.published.UnixNano()
+	}
 	for _, asset := range rel.assets {
 		dataObj.Assets = append(dataObj.Assets, byteAssetObj{
 			Size:        asset.Size(),
@@ -117,6 +122,9 @@ func UnmarshalRelease(data []byte) (lightweigit.ProviderReleaseInterface, error)
 		isPrerelease: dataObj.IsPrerelease,
 		assets:       make([]lightweigit.ProviderReleaseAssetInterface, 0),
 	}
+	if dataObj.Published != 0 {
+		release.published = time.Unix(0, dataObj.Published).UTC()
+	}
 	for _, asset := range dataObj.Assets {
 		u, _ := url.Parse(asset.DownloadURL)
 		release.assets = append(release.assets, &ReleaseAssetObj{
@@ -128,3 +136,43 @@ func UnmarshalRelease(data []byte) (lightweigit.ProviderReleaseInterface, error)
 
 	return release, nil
 }
+
+// // // //
+
+type byteBranchObj struct {
+	Obj    byteObj
+	Name   string
+	Commit string
+}
+
+//
+
+func (br *BranchObj) Marshal() []byte {
+	dataObj := byteBranchObj{
+		Obj: byteObj{
+			Name: br.Provider.name,
+		},
+		Name:   br.name,
+		Commit: br.commit,
+	}
+	return lightweigit.Marshal(br.Mod(), dataObj)
+}
+
+func UnmarshalBranch(data []byte) (lightweigit.ProviderBranchInterface, error) {
+	dataObj := new(byteBranchObj)
+	mod, err := lightweigit.Unmarshal(data, dataObj)
+	if err != nil {
+		return nil, err
+	}
+	if mod != target.ModGithubBranch {
+		return nil, lightweigit.ErrModTag
+	}
+
+	return &BranchObj{
+		Provider: &Obj{
+			name: dataObj.Obj.Name,
+		},
+		name:   dataObj.Name,
+		commit: dataObj.Commit,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// archiveURL is the github.com archive link of ref; tags and branches
+// pass it fully qualified so that a same-named branch cannot shadow them.
+func (obj *Obj) archiveURL(ref string, format lightweigit.ArchiveFormatType) *url.URL {
+	return lightweigit.BuildURL(
+		"https",
+		"github.com",
+		fmt.Sprintf("%s/archive/%s%s", obj.name, ref, format.Ext()),
+		"",
+	)
+}
+
+// Archive links the source of ref, a branch, tag or commit SHA. GitHub
+// builds zip and tar.gz archives only.
+func (obj *Obj) Archive(ref string, format lightweigit.ArchiveFormatType) (*url.URL, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty ref")
+	}
+	if format != lightweigit.ArchiveZIP && format != lightweigit.ArchiveTarGz {
+		return nil, fmt.Errorf("%s archives: %w", format, lightweigit.ErrUnsupported)
+	}
+	return obj.archiveURL(ref, format), nil
+}
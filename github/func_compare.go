@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type compareCommitObj struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			Date  string `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+type compareFileObj struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+}
+
+type compareRespObj struct {
+	Commits []compareCommitObj `json:"commits"`
+	Files   []compareFileObj   `json:"files"`
+}
+
+// //
+
+// Compare reads compare/base...head, paging through the commits. GitHub
+// sends the files with the first page and stops listing them at 300.
+func (obj *Obj) Compare(base, head string) (*lightweigit.CompareObj, error) {
+	base, head = strings.TrimSpace(base), strings.TrimSpace(head)
+	if base == "" || head == "" {
+		return nil, errors.New("empty ref")
+	}
+
+	res := &lightweigit.CompareObj{Base: base, Head: head}
+	spec := url.PathEscape(base) + "..." + url.PathEscape(head)
+	err := lightweigit.StreamPages(context.Background(), 100, 0,
+		func(perPage, page int) ([]compareCommitObj, error) {
+			var cr compareRespObj
+			if err := obj.getJSON(fmt.Sprintf("compare/%s?per_page=%d&page=%d", spec, perPage, page), &cr); err != nil {
+				return nil, err
+			}
+			if page == 1 {
+				for _, f := range cr.Files {
+					res.Files = append(res.Files, lightweigit.ChangedFileObj{
+						Path:      f.Filename,
+						OldPath:   f.PreviousFilename,
+						Status:    lightweigit.FileStatus(f.Status),
+						Additions: f.Additions,
+						Deletions: f.Deletions,
+					})
+				}
+			}
+			return cr.Commits, nil
+		},
+		func(li compareCommitObj) error {
+			res.Commits = append(res.Commits, lightweigit.CommitObj{
+				SHA:     li.SHA,
+				Author:  li.Commit.Author.Name,
+				Email:   li.Commit.Author.Email,
+				Message: li.Commit.Message,
+				Date:    lightweigit.ParseTime(li.Commit.Author.Date),
+			})
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,79 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type gitTagRespObj struct {
+	SHA     string `json:"sha"`
+	Message string `json:"message"`
+	Tagger  struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		Date  string `json:"date"`
+	} `json:"tagger"`
+	Object struct {
+		Type string `json:"type"`
+		SHA  string `json:"sha"`
+	} `json:"object"`
+	Verification struct {
+		Verified  bool   `json:"verified"`
+		Reason    string `json:"reason"`
+		Signature string `json:"signature"`
+		Payload   string `json:"payload"`
+	} `json:"verification"`
+}
+
+// //
+
+// TagDetail reads git/ref/tags/name and, when it points at a tag object,
+// git/tags/sha with the tagger and GitHub's signature verification.
+func (obj *Obj) TagDetail(name string) (*lightweigit.TagDetailObj, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("empty tag")
+	}
+
+	var rr refRespObj
+	if err := obj.getJSON(fmt.Sprintf("git/ref/tags/%s", name), &rr); err != nil {
+		return nil, err
+	}
+
+	d := &lightweigit.TagDetailObj{
+		Name:   name,
+		Kind:   lightweigit.TagLightweight,
+		SHA:    rr.Object.SHA,
+		Commit: rr.Object.SHA,
+	}
+	if rr.Object.Type != "tag" {
+		return d, nil
+	}
+
+	var gt gitTagRespObj
+	if err := obj.getJSON("git/tags/"+rr.Object.SHA, &gt); err != nil {
+		return nil, err
+	}
+
+	d.Kind = lightweigit.TagAnnotated
+	d.Commit = gt.Object.SHA
+	d.Message, d.Signature = lightweigit.SplitTagSignature(gt.Message)
+	d.TaggerName = gt.Tagger.Name
+	d.TaggerEmail = gt.Tagger.Email
+	d.TaggerDate = lightweigit.ParseTime(gt.Tagger.Date)
+
+	v := gt.Verification
+	if v.Signature != "" {
+		d.Signature = v.Signature
+	}
+	d.Payload = v.Payload
+	if v.Reason != "" && v.Reason != "unsigned" {
+		d.Verification = &lightweigit.VerificationObj{Verified: v.Verified, Reason: v.Reason}
+	}
+	return d, nil
+}
@@ -0,0 +1,34 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// File reads path at ref from raw.githubusercontent.com, which costs no
+// API quota.
+func (obj *Obj) File(ref, path string) (*lightweigit.FileObj, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty ref")
+	}
+	p, err := lightweigit.CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := lightweigit.GetRaw(obj, fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", obj.name, lightweigit.EscapePath(ref), lightweigit.EscapePath(p)))
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", p, ref, err)
+	}
+	return lightweigit.NewFile(ref, p, b), nil
+}
+
+func (tag *TagObj) File(path string) (*lightweigit.FileObj, error) {
+	return tag.Provider.File(tag.name, path)
+}
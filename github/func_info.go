@@ -0,0 +1,56 @@
+package github
+
+import (
+	"fmt"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type repoItemObj struct {
+	FullName      string `json:"full_name"`
+	Description   string `json:"description"`
+	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
+	Disabled      bool   `json:"disabled"`
+	Private       bool   `json:"private"`
+	Visibility    string `json:"visibility"`
+	Stars         int    `json:"stargazers_count"`
+	PushedAt      string `json:"pushed_at"`
+	Parent        *struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"parent"`
+	License *struct {
+		SPDXID string `json:"spdx_id"`
+	} `json:"license"`
+}
+
+// //
+
+func (obj *Obj) RepositoryInfo() (*lightweigit.RepositoryInfoObj, error) {
+	var li repoItemObj
+	if err := lightweigit.GetJSON(obj, fmt.Sprintf("https://api.github.com/repos/%s", obj.name), &li); err != nil {
+		return nil, err
+	}
+
+	info := &lightweigit.RepositoryInfoObj{
+		Name:          li.FullName,
+		Description:   li.Description,
+		DefaultBranch: li.DefaultBranch,
+		Archived:      li.Archived || li.Disabled,
+		Visibility:    li.Visibility,
+		Stars:         li.Stars,
+		Pushed:        lightweigit.ParseTime(li.PushedAt),
+	}
+	if info.Visibility == "" {
+		info.Visibility = lightweigit.Visibility(li.Private, false)
+	}
+	if li.Parent != nil {
+		info.Parent = li.Parent.HTMLURL
+	}
+	if li.License != nil {
+		info.License = lightweigit.SPDX(li.License.SPDXID)
+	}
+	return info, nil
+}
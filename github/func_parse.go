@@ -6,6 +6,8 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
 )
 
 // // // // // // // // // // // // // // // //
@@ -99,3 +101,54 @@ func Parse(raw string) (*Obj, error) {
 
 	return &Obj{name: owner + "/" + repo}, nil
 }
+
+// ParseOffline is Parse; this provider never needs the network to resolve.
+func ParseOffline(raw string) (*Obj, error) {
+	return Parse(raw)
+}
+
+// Ref reads the tag, release, branch, commit, file or tree raw points at,
+// relative to this repository. GitHub's tree, blob and commits pages name
+// a ref without saying whether it is a branch or a tag.
+func (obj *Obj) Ref(raw string) lightweigit.RefObj {
+	tail := lightweigit.PathAfter(raw, obj.name)
+	if len(tail) < 2 {
+		return lightweigit.RefObj{}
+	}
+
+	switch tail[0] {
+	case "releases":
+		if len(tail) >= 3 && (tail[1] == "tag" || tail[1] == "download") {
+			return lightweigit.RefObj{Kind: lightweigit.RefRelease, Name: tail[2]}
+		}
+	case "tree":
+		return lightweigit.RefAt(lightweigit.RefTree, lightweigit.RefTree, tail[1:])
+	case "blob", "raw", "blame":
+		return lightweigit.RefAt(lightweigit.RefFile, lightweigit.RefTree, tail[1:])
+	case "commit", "commits":
+		return lightweigit.RefAt(lightweigit.RefCommit, lightweigit.RefCommit, tail[1:2])
+	case "archive":
+		// archive/refs/tags/<tag>.zip, archive/refs/heads/<branch>.zip or
+		// archive/<ref>.tar.gz; here the whole rest is the name.
+		if len(tail) >= 4 && tail[1] == "refs" {
+			name := trimArchiveExt(strings.Join(tail[3:], "/"))
+			switch tail[2] {
+			case "tags":
+				return lightweigit.RefObj{Kind: lightweigit.RefTag, Name: name}
+			case "heads":
+				return lightweigit.RefObj{Kind: lightweigit.RefBranch, Name: name}
+			}
+		}
+		return lightweigit.RefObj{Kind: lightweigit.RefTree, Name: trimArchiveExt(strings.Join(tail[1:], "/"))}
+	}
+	return lightweigit.RefObj{}
+}
+
+func trimArchiveExt(s string) string {
+	for _, ext := range []string{".tar.gz", ".zip"} {
+		if strings.HasSuffix(s, ext) {
+			return strings.TrimSuffix(s, ext)
+		}
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+package github
+
+import (
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func init() {
+	lightweigit.RegisterBuiltin(lightweigit.ProviderEntryObj{
+		Name:     "github",
+		Priority: 100,
+
+		Match: func(host string) bool {
+			return host == "github.com" || host == "www.github.com"
+		},
+
+		TagMod:           target.ModGithubTag,
+		ReleaseMod:       target.ModGithubRelease,
+		UnmarshalTag:     UnmarshalTag,
+		UnmarshalRelease: UnmarshalRelease,
+
+		BranchMod:       target.ModGithubBranch,
+		UnmarshalBranch: UnmarshalBranch,
+	}, Parse, ParseOffline, nil)
+}
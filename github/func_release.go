@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/voluminor/lightweigit-loader"
 	"github.com/voluminor/lightweigit-loader/target"
@@ -81,6 +82,12 @@ func (rel *ReleaseObj) IsPrerelease() bool {
 	return rel.isPrerelease
 }
 
+// Time is when the release was published; zero when the forge did not
+// say.
+func (rel *ReleaseObj) Time() time.Time {
+	return rel.published
+}
+
 // // // //
 
 type releaseAssetItemObj struct {
@@ -96,6 +103,18 @@ type releaseItemObj struct {
 	Draft      bool                  `json:"draft"`
 	Prerelease bool                  `json:"prerelease"`
 	Assets     []releaseAssetItemObj `json:"assets"`
+
+	PublishedAt string `json:"published_at"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// published is the publication time, or the creation time for releases
+// published before the field existed.
+func (li releaseItemObj) published() time.Time {
+	if t := lightweigit.ParseTime(li.PublishedAt); !t.IsZero() {
+		return t
+	}
+	return lightweigit.ParseTime(li.CreatedAt)
 }
 
 func buildReleaseObj(obj *Obj, li releaseItemObj) *ReleaseObj {
@@ -122,6 +141,7 @@ func buildReleaseObj(obj *Obj, li releaseItemObj) *ReleaseObj {
 		bodyMD:       li.Body,
 		assets:       assets,
 		isPrerelease: li.Prerelease,
+		published:    li.published(),
 	}
 }
 
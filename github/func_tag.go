@@ -28,21 +28,11 @@ func (tag *TagObj) URL() *url.URL {
 }
 
 func (tag *TagObj) ZIP() *url.URL {
-	return lightweigit.BuildURL(
-		"https",
-		"github.com",
-		fmt.Sprintf("%s/archive/refs/tags/%s.zip", tag.Provider.name, tag.name),
-		"",
-	)
+	return tag.Provider.archiveURL("refs/tags/"+tag.name, lightweigit.ArchiveZIP)
 }
 
 func (tag *TagObj) TAR() *url.URL {
-	return lightweigit.BuildURL(
-		"https",
-		"github.com",
-		fmt.Sprintf("%s/archive/refs/tags/%s.tar.gz", tag.Provider.name, tag.name),
-		"",
-	)
+	return tag.Provider.archiveURL("refs/tags/"+tag.name, lightweigit.ArchiveTarGz)
 }
 
 // // // //
@@ -2,6 +2,7 @@ package github
 
 import (
 	"net/url"
+	"time"
 
 	"github.com/voluminor/lightweigit-loader"
 )
@@ -17,6 +18,13 @@ type TagObj struct {
 	name     string
 }
 
+// BranchObj is a branch and the head commit it had when it was listed.
+type BranchObj struct {
+	Provider *Obj
+	name     string
+	commit   string
+}
+
 type ReleaseAssetObj struct {
 	download    url.URL
 	contentType string
@@ -30,4 +38,5 @@ type ReleaseObj struct {
 	bodyMD       string
 	assets       []lightweigit.ProviderReleaseAssetInterface
 	isPrerelease bool
+	published    time.Time
 }
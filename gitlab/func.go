@@ -1,8 +1,10 @@
 package gitlab
 
 import (
+	"context"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/voluminor/lightweigit-loader"
 )
@@ -10,7 +12,31 @@ import (
 // // // // // // // // // // // // // // // //
 
 func (obj *Obj) getJSON(u string, out any) error {
-	return lightweigit.GetJSON(obj, fmt.Sprintf("https://%s/api/v4/projects/%d/%s", obj.host, obj.id, u), &out)
+	if err := obj.Resolve(context.Background()); err != nil {
+		return err
+	}
+	return lightweigit.GetJSON(obj, fmt.Sprintf("https://%s%s/projects/%d/%s", obj.host, obj.apiBase(), obj.projectID(), u), &out)
+}
+
+// projectID is the numeric project ID, 0 until resolved.
+func (obj *Obj) projectID() uint32 {
+	obj.mu.RLock()
+	defer obj.mu.RUnlock()
+	return obj.id
+}
+
+// apiBase is the API root path: "/api/v4" unless the host is pinned with
+// another one.
+func (obj *Obj) apiBase() string {
+	if obj.api != "" {
+		return obj.api
+	}
+	return "/api/v4"
+}
+
+// apiPath joins p onto apiBase in the slash-less form BuildURL expects.
+func (obj *Obj) apiPath(p string) string {
+	return strings.TrimPrefix(obj.apiBase(), "/") + "/" + p
 }
 
 // //
@@ -19,6 +45,16 @@ func (obj *Obj) Type() string {
 	return "gitlab"
 }
 
+// Identity folds the path to lower case, as GitLab resolves namespaces
+// and projects case-insensitively.
+func (obj *Obj) Identity() lightweigit.IdentityObj {
+	return lightweigit.IdentityObj{
+		Family: "gitlab",
+		Host:   lightweigit.NormalizeHost(obj.host),
+		Name:   strings.ToLower(obj.name),
+	}
+}
+
 func (obj *Obj) Domain() string {
 	return obj.host
 }
@@ -31,7 +67,43 @@ func (obj *Obj) URL() *url.URL {
 	return lightweigit.BuildURL(
 		"https",
 		obj.host,
-		obj.name,
+		lightweigit.WebPrefix(obj.api)+"/"+obj.name,
 		"",
 	)
 }
+
+// CloneHTTPS is the instance's http_url_to_repo once known (see
+// FetchCloneURLs), else https://host[/prefix]/namespace/repo.git.
+func (obj *Obj) CloneHTTPS() string {
+	obj.mu.RLock()
+	defer obj.mu.RUnlock()
+	if obj.httpURL != "" {
+		return obj.httpURL
+	}
+	return "https://" + obj.host + lightweigit.WebPrefix(obj.api) + "/" + obj.name + ".git"
+}
+
+// CloneSSH is the instance's ssh_url_to_repo once known, else
+// git@host:namespace/repo.git with the full nested group path.
+func (obj *Obj) CloneSSH() string {
+	obj.mu.RLock()
+	defer obj.mu.RUnlock()
+	if obj.sshURL != "" {
+		return obj.sshURL
+	}
+	return "git@" + lightweigit.NormalizeHost(obj.host) + ":" + obj.name + ".git"
+}
+
+// //
+
+func (obj *Obj) IssueURL(n int) string {
+	return lightweigit.AddURL(obj.URL(), fmt.Sprintf("/-/issues/%d", n), "").String()
+}
+
+func (obj *Obj) UserURL(name string) string {
+	return lightweigit.BuildURL("https", obj.host, lightweigit.WebPrefix(obj.api)+"/"+name, "").String()
+}
+
+func (obj *Obj) FileURL(ref, path string) string {
+	return lightweigit.AddURL(obj.URL(), "/-/blob/"+ref+"/"+strings.TrimPrefix(path, "/"), "").String()
+}
@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"net/url"
+	"time"
 
 	"github.com/voluminor/lightweigit-loader"
 	"github.com/voluminor/lightweigit-loader/target"
@@ -13,6 +14,7 @@ type byteObj struct {
 	ID   uint32
 	Name string
 	Host string
+	API  string
 }
 type byteTagObj struct {
 	Obj  byteObj
@@ -26,7 +28,8 @@ func (tag *TagObj) Marshal() []byte {
 		Obj: byteObj{
 			Name: tag.Provider.name,
 			Host: tag.Provider.host,
-			ID:   tag.Provider.id,
+			API:  tag.Provider.api,
+			ID:   tag.Provider.projectID(),
 		},
 		Name: tag.name,
 	}
@@ -47,6 +50,7 @@ func UnmarshalTag(data []byte) (lightweigit.ProviderTagInterface, error) {
 		Provider: &Obj{
 			name: dataObj.Obj.Name,
 			host: dataObj.Obj.Host,
+			api:  dataObj.Obj.API,
 			id:   dataObj.Obj.ID,
 		},
 		name: dataObj.Name,
@@ -66,6 +70,7 @@ type byteReleaseObj struct {
 	Name         string
 	BodyMD       string
 	IsPrerelease bool
+	Published    int64
 	Assets       []byteAssetObj
 }
 
@@ -76,13 +81,15 @@ func (rel *ReleaseObj) Marshal() []byte {
 		Obj: byteObj{
 			Name: rel.Provider.name,
 			Host: rel.Provider.host,
-			ID:   rel.Provider.id,
+			API:  rel.Provider.api,
+			ID:   rel.Provider.projectID(),
 		},
 		Tag: byteTagObj{
 			Obj: byteObj{
 				Name: rel.Provider.name,
 				Host: rel.Provider.host,
-				ID:   rel.Provider.id,
+				API:  rel.Provider.api,
+				ID:   rel.Provider.projectID(),
 			},
 			Name: rel.tag.String(),
 		},
@@ -91,6 +98,9 @@ func (rel *ReleaseObj) Marshal() []byte {
 		IsPrerelease: rel.isPrerelease,
 		Assets:       make([]byteAssetObj, 0),
 	}
+	if !rel.published.IsZero() {
+		dataObj.Published = rel.published.UnixNano()
+	}
 	for _, asset := range rel.assets {
 		dataObj.Assets = append(dataObj.Assets, byteAssetObj{
 			Size:        asset.Size(),
@@ -115,6 +125,7 @@ func UnmarshalRelease(data []byte) (lightweigit.ProviderReleaseInterface, error)
 	obj := &Obj{
 		name: dataObj.Obj.Name,
 		host: dataObj.Obj.Host,
+		api:  dataObj.Obj.API,
 		id:   dataObj.Obj.ID,
 	}
 	tag := &TagObj{
@@ -129,6 +140,9 @@ func UnmarshalRelease(data []byte) (lightweigit.ProviderReleaseInterface, error)
 		isPrerelease: dataObj.IsPrerelease,
 		assets:       make([]lightweigit.ProviderReleaseAssetInterface, 0),
 	}
+	if dataObj.Published != 0 {
+		release.published = time.Unix(0, dataObj.Published).UTC()
+	}
 	for _, asset := range dataObj.Assets {
 		u, _ := url.Parse(asset.DownloadURL)
 		release.assets = append(release.assets, &ReleaseAssetObj{
@@ -140,3 +154,49 @@ func UnmarshalRelease(data []byte) (lightweigit.ProviderReleaseInterface, error)
 
 	return release, nil
 }
+
+// // // //
+
+type byteBranchObj struct {
+	Obj    byteObj
+	Name   string
+	Commit string
+}
+
+//
+
+func (br *BranchObj) Marshal() []byte {
+	dataObj := byteBranchObj{
+		Obj: byteObj{
+			Name: br.Provider.name,
+			Host: br.Provider.host,
+			API:  br.Provider.api,
+			ID:   br.Provider.projectID(),
+		},
+		Name:   br.name,
+		Commit: br.commit,
+	}
+	return lightweigit.Marshal(br.Mod(), dataObj)
+}
+
+func UnmarshalBranch(data []byte) (lightweigit.ProviderBranchInterface, error) {
+	dataObj := new(byteBranchObj)
+	mod, err := lightweigit.Unmarshal(data, dataObj)
+	if err != nil {
+		return nil, err
+	}
+	if mod != target.ModGitlabBranch {
+		return nil, lightweigit.ErrModTag
+	}
+
+	return &BranchObj{
+		Provider: &Obj{
+			name: dataObj.Obj.Name,
+			host: dataObj.Obj.Host,
+			api:  dataObj.Obj.API,
+			id:   dataObj.Obj.ID,
+		},
+		name:   dataObj.Name,
+		commit: dataObj.Commit,
+	}, nil
+}
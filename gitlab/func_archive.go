@@ -0,0 +1,45 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// archiveURL is the repository/archive endpoint of the project for ref.
+// It needs the project ID: on a handle that is not resolved yet the link
+// carries ID 0.
+func (obj *Obj) archiveURL(ref string, format lightweigit.ArchiveFormatType) *url.URL {
+	return lightweigit.BuildURL(
+		"https",
+		obj.host,
+		obj.apiPath(fmt.Sprintf("projects/%d/repository/archive%s", obj.projectID(), format.Ext())),
+		fmt.Sprintf("sha=%s", url.QueryEscape(ref)),
+	)
+}
+
+// Archive links the source of ref, a branch, tag or commit SHA, as zip,
+// tar.gz or tar.bz2. A ParseOffline handle is resolved first to learn the
+// project ID.
+func (obj *Obj) Archive(ref string, format lightweigit.ArchiveFormatType) (*url.URL, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty ref")
+	}
+	switch format {
+	case lightweigit.ArchiveZIP, lightweigit.ArchiveTarGz, lightweigit.ArchiveTarBz2:
+	default:
+		return nil, fmt.Errorf("%s archives: %w", format, lightweigit.ErrUnsupported)
+	}
+
+	if err := obj.Resolve(context.Background()); err != nil {
+		return nil, err
+	}
+	return obj.archiveURL(ref, format), nil
+}
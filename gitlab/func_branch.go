@@ -0,0 +1,98 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func (br *BranchObj) Mod() target.ModType {
+	return target.ModGitlabBranch
+}
+
+func (br *BranchObj) String() string {
+	return br.name
+}
+
+func (br *BranchObj) Commit() string {
+	return br.commit
+}
+
+func (br *BranchObj) URL() *url.URL {
+	return lightweigit.AddURL(
+		br.Provider.URL(),
+		"/-/tree/"+br.name,
+		"",
+	)
+}
+
+func (br *BranchObj) ZIP() *url.URL {
+	return br.Provider.archiveURL(br.name, lightweigit.ArchiveZIP)
+}
+
+func (br *BranchObj) TAR() *url.URL {
+	return br.Provider.archiveURL(br.name, lightweigit.ArchiveTarGz)
+}
+
+// // // //
+
+type branchItemObj struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+func (obj *Obj) branch(li branchItemObj) *BranchObj {
+	return &BranchObj{
+		Provider: obj,
+		name:     li.Name,
+		commit:   li.Commit.ID,
+	}
+}
+
+// //
+
+// BranchDefault reads the default branch name from the project, then its
+// head.
+func (obj *Obj) BranchDefault() (lightweigit.ProviderBranchInterface, error) {
+	info, err := obj.RepositoryInfo()
+	if err != nil {
+		return nil, err
+	}
+	if info.DefaultBranch == "" {
+		return nil, lightweigit.ErrNotFound
+	}
+	return obj.BranchFind(info.DefaultBranch)
+}
+
+func (obj *Obj) BranchFind(findBranch string) (lightweigit.ProviderBranchInterface, error) {
+	var li branchItemObj
+	if err := obj.getJSON(fmt.Sprintf("repository/branches/%s", url.PathEscape(findBranch)), &li); err != nil {
+		return nil, err
+	}
+	if li.Name == "" {
+		li.Name = findBranch
+	}
+	return obj.branch(li), nil
+}
+
+func (obj *Obj) BranchesStream(ctx context.Context, out chan lightweigit.ProviderBranchInterface, limit int) error {
+	return lightweigit.StreamPages(ctx, 50, limit,
+		func(perPage, page int) ([]branchItemObj, error) {
+			var branches []branchItemObj
+			if err := obj.getJSON(fmt.Sprintf("repository/branches?per_page=%d&page=%d", perPage, page), &branches); err != nil {
+				return nil, err
+			}
+			return branches, nil
+		},
+		func(li branchItemObj) error {
+			return lightweigit.Send[lightweigit.ProviderBranchInterface](ctx, out, obj.branch(li))
+		},
+	)
+}
@@ -0,0 +1,73 @@
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type compareRespObj struct {
+	Commits []struct {
+		ID           string `json:"id"`
+		AuthorName   string `json:"author_name"`
+		AuthorEmail  string `json:"author_email"`
+		AuthoredDate string `json:"authored_date"`
+		Message      string `json:"message"`
+	} `json:"commits"`
+	Diffs []struct {
+		OldPath     string `json:"old_path"`
+		NewPath     string `json:"new_path"`
+		NewFile     bool   `json:"new_file"`
+		RenamedFile bool   `json:"renamed_file"`
+		DeletedFile bool   `json:"deleted_file"`
+		Diff        string `json:"diff"`
+	} `json:"diffs"`
+}
+
+// //
+
+// Compare reads repository/compare, which answers in one piece: GitLab
+// does not paginate it. Line counts come from the diffs, which GitLab
+// leaves empty for files over its diff limits.
+func (obj *Obj) Compare(base, head string) (*lightweigit.CompareObj, error) {
+	base, head = strings.TrimSpace(base), strings.TrimSpace(head)
+	if base == "" || head == "" {
+		return nil, errors.New("empty ref")
+	}
+
+	var cr compareRespObj
+	u := fmt.Sprintf("repository/compare?from=%s&to=%s&straight=false", url.QueryEscape(base), url.QueryEscape(head))
+	if err := obj.getJSON(u, &cr); err != nil {
+		return nil, err
+	}
+
+	res := &lightweigit.CompareObj{Base: base, Head: head}
+	for _, li := range cr.Commits {
+		res.Commits = append(res.Commits, lightweigit.CommitObj{
+			SHA:     li.ID,
+			Author:  li.AuthorName,
+			Email:   li.AuthorEmail,
+			Message: li.Message,
+			Date:    lightweigit.ParseTime(li.AuthoredDate),
+		})
+	}
+	for _, d := range cr.Diffs {
+		f := lightweigit.ChangedFileObj{Path: d.NewPath, Status: "modified"}
+		switch {
+		case d.NewFile:
+			f.Status = "added"
+		case d.DeletedFile:
+			f.Status, f.Path = "removed", d.OldPath
+		case d.RenamedFile:
+			f.Status, f.OldPath = "renamed", d.OldPath
+		}
+		f.Additions, f.Deletions = lightweigit.DiffStat(d.Diff)
+		res.Files = append(res.Files, f)
+	}
+	return res, nil
+}
@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type tagDetailRespObj struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Target  string `json:"target"`
+	Commit  struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+	CreatedAt string `json:"created_at"`
+}
+
+type tagSignatureRespObj struct {
+	SignatureType      string `json:"signature_type"`
+	VerificationStatus string `json:"verification_status"`
+}
+
+// //
+
+// TagDetail reads repository/tags/name, where target differs from the
+// commit for annotated tags, and repository/tags/name/signature for
+// GitLab's verdict (15.7+; 404 for unsigned tags). GitLab reports neither
+// the tagger, beyond the date the tag was created, nor the raw signature.
+func (obj *Obj) TagDetail(name string) (*lightweigit.TagDetailObj, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("empty tag")
+	}
+
+	var t tagDetailRespObj
+	tagEsc := url.PathEscape(name)
+	if err := obj.getJSON(fmt.Sprintf("repository/tags/%s", tagEsc), &t); err != nil {
+		return nil, err
+	}
+
+	d := &lightweigit.TagDetailObj{
+		Name:   name,
+		Kind:   lightweigit.TagLightweight,
+		SHA:    t.Commit.ID,
+		Commit: t.Commit.ID,
+	}
+	if t.Target == "" || t.Target == t.Commit.ID {
+		return d, nil
+	}
+
+	d.Kind = lightweigit.TagAnnotated
+	d.SHA = t.Target
+	d.Message, d.Signature = lightweigit.SplitTagSignature(t.Message)
+	d.TaggerDate = lightweigit.ParseTime(t.CreatedAt)
+
+	var sig tagSignatureRespObj
+	err := obj.getJSON(fmt.Sprintf("repository/tags/%s/signature", tagEsc), &sig)
+	switch {
+	case errors.Is(err, lightweigit.ErrNotFound):
+	case err != nil:
+		return nil, err
+	case sig.VerificationStatus != "":
+		d.Verification = &lightweigit.VerificationObj{
+			Verified: sig.VerificationStatus == "verified",
+			Reason:   sig.VerificationStatus,
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,40 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// File reads path at ref through repository/files/:path/raw.
+func (obj *Obj) File(ref, path string) (*lightweigit.FileObj, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty ref")
+	}
+	p, err := lightweigit.CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := obj.Resolve(context.Background()); err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("https://%s%s/projects/%d/repository/files/%s/raw?ref=%s",
+		obj.host, obj.apiBase(), obj.projectID(), url.PathEscape(p), url.QueryEscape(ref))
+	b, err := lightweigit.GetRaw(obj, u)
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", p, ref, err)
+	}
+	return lightweigit.NewFile(ref, p, b), nil
+}
+
+func (tag *TagObj) File(path string) (*lightweigit.FileObj, error) {
+	return tag.Provider.File(tag.name, path)
+}
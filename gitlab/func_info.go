@@ -0,0 +1,59 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type projectItemObj struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	Description       string `json:"description"`
+	DefaultBranch     string `json:"default_branch"`
+	Archived          bool   `json:"archived"`
+	Visibility        string `json:"visibility"`
+	Stars             int    `json:"star_count"`
+	LastActivityAt    string `json:"last_activity_at"`
+	ForkedFrom        *struct {
+		WebURL string `json:"web_url"`
+	} `json:"forked_from_project"`
+	License *struct {
+		Key string `json:"key"`
+	} `json:"license"`
+}
+
+// //
+
+// RepositoryInfo reads the project with license=true. GitLab reports no
+// push time; Pushed is the last activity.
+func (obj *Obj) RepositoryInfo() (*lightweigit.RepositoryInfoObj, error) {
+	if err := obj.Resolve(context.Background()); err != nil {
+		return nil, err
+	}
+
+	var li projectItemObj
+	u := fmt.Sprintf("https://%s%s/projects/%d?license=true", obj.host, obj.apiBase(), obj.projectID())
+	if err := lightweigit.GetJSON(obj, u, &li); err != nil {
+		return nil, err
+	}
+
+	info := &lightweigit.RepositoryInfoObj{
+		Name:          li.PathWithNamespace,
+		Description:   li.Description,
+		DefaultBranch: li.DefaultBranch,
+		Archived:      li.Archived,
+		Visibility:    li.Visibility,
+		Stars:         li.Stars,
+		Pushed:        lightweigit.ParseTime(li.LastActivityAt),
+	}
+	if li.ForkedFrom != nil {
+		info.Parent = li.ForkedFrom.WebURL
+	}
+	if li.License != nil {
+		info.License = lightweigit.SPDX(li.License.Key)
+	}
+	return info, nil
+}
@@ -1,6 +1,7 @@
 package gitlab
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -80,48 +81,102 @@ func namespaceRepoFromPath(p string) (string, string, error) {
 	return strings.Join(namespaceParts, "/"), repo, nil
 }
 
-func validateGitLab(host, name string) (*Obj, error) {
+// projectObj is the part of the project API response kept on the handle.
+type projectObj struct {
+	Id      uint32 `json:"id"`
+	SSHURL  string `json:"ssh_url_to_repo"`
+	HTTPURL string `json:"http_url_to_repo"`
+}
+
+func (obj *Obj) fetchProject(ctx context.Context) (projectObj, error) {
+	var md projectObj
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://%s/api/v4/projects/%s", host, url.PathEscape(name)), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s%s/projects/%s", obj.host, obj.apiBase(), url.PathEscape(obj.name)), nil)
 	if err != nil {
-		return nil, err
+		return md, err
 	}
 	req.Header.Set("User-Agent", "gitlab check metadata")
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := lightweigit.HttpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return md, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
 	if err != nil {
-		return nil, err
+		return md, err
 	}
 
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		var md struct {
-			Id uint32 `json:"id"`
-		}
 		if err := json.Unmarshal(body, &md); err != nil {
-			return nil, fmt.Errorf("metadata is not JSON: %w", err)
+			return md, fmt.Errorf("metadata is not JSON: %w", err)
 		}
-		return &Obj{host: host, name: name, id: md.Id}, nil
+		return md, nil
 	}
 
 	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return md, fmt.Errorf("metadata endpoint returned %s: %w", resp.Status, lightweigit.ErrNotFound)
 	case http.StatusForbidden:
-		return nil, fmt.Errorf("metadata endpoint returned %s: %w", resp.Status, lightweigit.ErrForbidden)
+		return md, fmt.Errorf("metadata endpoint returned %s: %w", resp.Status, lightweigit.ErrForbidden)
 	case http.StatusTooManyRequests:
-		return nil, fmt.Errorf("metadata endpoint returned %s: %w", resp.Status, lightweigit.ErrTooManyRequests)
+		return md, fmt.Errorf("metadata endpoint returned %s: %w", resp.Status, lightweigit.ErrTooManyRequests)
+	}
+	return md, fmt.Errorf("metadata endpoint returned %s", resp.Status)
+}
+
+// Resolve looks up the numeric project ID that every API call is keyed on.
+// Handles from ParseOffline resolve themselves on first use; call Resolve
+// explicitly to control the context. Concurrent first uses of one handle
+// resolve it once. Resolve is a no-op once the ID is known.
+func (obj *Obj) Resolve(ctx context.Context) error {
+	if obj.projectID() != 0 {
+		return nil
+	}
+	obj.resolving.Lock()
+	defer obj.resolving.Unlock()
+	if obj.projectID() != 0 {
+		return nil
+	}
+
+	md, err := obj.fetchProject(ctx)
+	if err != nil {
+		return err
+	}
+	obj.setProject(md)
+	return nil
+}
+
+func (obj *Obj) setProject(md projectObj) {
+	obj.mu.Lock()
+	defer obj.mu.Unlock()
+	obj.id = md.Id
+	obj.sshURL = md.SSHURL
+	obj.httpURL = md.HTTPURL
+}
+
+// FetchCloneURLs reads the clone URLs the instance reports for the
+// project, replacing the conventional ones CloneHTTPS and CloneSSH build
+// otherwise. Parse does this as part of resolving the project ID.
+func (obj *Obj) FetchCloneURLs(ctx context.Context) error {
+	md, err := obj.fetchProject(ctx)
+	if err != nil {
+		return err
 	}
-	return nil, fmt.Errorf("metadata endpoint returned %s", resp.Status)
+	obj.setProject(md)
+	return nil
 }
 
 // // // //
 
-func Parse(raw string) (*Obj, error) {
+// parseSyntax splits raw into host and namespace/repo. prefix is the web
+// path a pinned forge is served under; it is cut off before the split.
+func parseSyntax(raw string, prefix string) (*Obj, error) {
 	s := strings.TrimSpace(raw)
 	if s == "" {
 		return nil, errors.New("an empty URL string")
@@ -132,7 +187,7 @@ func Parse(raw string) (*Obj, error) {
 		if err != nil {
 			return nil, err
 		}
-		return validateGitLab(host, name)
+		return &Obj{host: host, name: name}, nil
 	}
 
 	if !strings.Contains(s, "://") {
@@ -148,15 +203,99 @@ func Parse(raw string) (*Obj, error) {
 		return nil, fmt.Errorf("URL has no host: %q", raw)
 	}
 
+	// Only the exact host www.gitlab.com is an alias; the port stays. The
+	// port of an SSH URL is the SSH daemon's, not the web server's.
 	host := strings.ToLower(u.Host)
-	if strings.HasPrefix(host, "www.gitlab.com") {
-		host = strings.Replace(host, "www.gitlab.com", "gitlab.com", 1)
+	if lightweigit.IsSSHScheme(u.Scheme) {
+		host = strings.ToLower(u.Hostname())
+	}
+	if strings.ToLower(u.Hostname()) == "www.gitlab.com" {
+		host = "gitlab.com" + strings.TrimPrefix(host, "www.gitlab.com")
+	}
+
+	p := u.Path
+	if prefix != "" && strings.HasPrefix(p, prefix+"/") {
+		p = p[len(prefix):]
 	}
 
-	namespace, repo, err := namespaceRepoFromPath(u.Path)
+	namespace, repo, err := namespaceRepoFromPath(p)
 	if err != nil {
 		return nil, err
 	}
 
-	return validateGitLab(host, namespace+"/"+repo)
+	return &Obj{host: host, name: namespace + "/" + repo}, nil
+}
+
+// ParseOffline is the syntactic half of Parse: it never touches the network
+// and returns a handle whose project ID is resolved on first use (see
+// Resolve). Without probing, any host could be GitLab, so only hosts that
+// name themselves as such are accepted here.
+func ParseOffline(raw string) (*Obj, error) {
+	obj, err := parseSyntax(raw, "")
+	if err != nil {
+		return nil, err
+	}
+
+	host := obj.host
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	if !strings.Contains(host, "gitlab") {
+		return nil, fmt.Errorf("not GitLab host without probing: %q", obj.host)
+	}
+	return obj, nil
+}
+
+// ParsePinned parses raw for a host pinned to GitLab in the host map: the
+// host is taken as GitLab without probing, and the project ID is resolved
+// on first use against the pinned API base.
+func ParsePinned(raw string, pin lightweigit.HostPinObj) (*Obj, error) {
+	obj, err := parseSyntax(raw, lightweigit.WebPrefix(pin.APIBase))
+	if err != nil {
+		return nil, err
+	}
+	obj.api = pin.APIBase
+	return obj, nil
+}
+
+func Parse(raw string) (*Obj, error) {
+	obj, err := parseSyntax(raw, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := obj.Resolve(context.Background()); err != nil {
+		// At parse time a 404 is as likely a host that is not GitLab as a
+		// missing project, so it must not read as ErrNotFound.
+		if errors.Is(err, lightweigit.ErrNotFound) {
+			return nil, fmt.Errorf("%s: %v: %w", obj.host, err, lightweigit.ErrHostMismatch)
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Ref reads the tag, release, branch, commit, file or tree raw points at,
+// from the part of the path after "/-/". Tree, blob and commits pages name
+// a ref without saying whether it is a branch or a tag.
+func (obj *Obj) Ref(raw string) lightweigit.RefObj {
+	tail := lightweigit.PathAfter(raw, obj.name)
+	if len(tail) < 3 || tail[0] != "-" {
+		return lightweigit.RefObj{}
+	}
+
+	switch tail[1] {
+	case "tags":
+		return lightweigit.RefAt(lightweigit.RefTag, lightweigit.RefTag, tail[2:3])
+	case "releases":
+		return lightweigit.RefAt(lightweigit.RefRelease, lightweigit.RefRelease, tail[2:3])
+	case "tree":
+		return lightweigit.RefAt(lightweigit.RefTree, lightweigit.RefTree, tail[2:])
+	case "blob", "raw", "blame":
+		return lightweigit.RefAt(lightweigit.RefFile, lightweigit.RefTree, tail[2:])
+	case "commit", "commits":
+		return lightweigit.RefAt(lightweigit.RefCommit, lightweigit.RefCommit, tail[2:3])
+	case "archive":
+		return lightweigit.RefAt(lightweigit.RefTree, lightweigit.RefTree, tail[2:3])
+	}
+	return lightweigit.RefObj{}
 }
@@ -0,0 +1,24 @@
+package gitlab
+
+import (
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func init() {
+	// No Match: any host may run GitLab, and Parse confirms it through the API.
+	lightweigit.RegisterBuiltin(lightweigit.ProviderEntryObj{
+		Name:     "gitlab",
+		Priority: 50,
+
+		TagMod:           target.ModGitlabTag,
+		ReleaseMod:       target.ModGitlabRelease,
+		UnmarshalTag:     UnmarshalTag,
+		UnmarshalRelease: UnmarshalRelease,
+
+		BranchMod:       target.ModGitlabBranch,
+		UnmarshalBranch: UnmarshalBranch,
+	}, Parse, ParseOffline, ParsePinned)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/voluminor/lightweigit-loader"
 	"github.com/voluminor/lightweigit-loader/target"
@@ -56,21 +57,11 @@ func (rel *ReleaseObj) Tag() lightweigit.ProviderTagInterface {
 }
 
 func (rel *ReleaseObj) ZIP() *url.URL {
-	return lightweigit.BuildURL(
-		"https",
-		rel.Provider.host,
-		fmt.Sprintf("api/v4/projects/%d/repository/archive.zip", rel.Provider.id),
-		fmt.Sprintf("sha=%s", url.QueryEscape(rel.tag.String())),
-	)
+	return rel.Provider.archiveURL(rel.tag.String(), lightweigit.ArchiveZIP)
 }
 
 func (rel *ReleaseObj) TAR() *url.URL {
-	return lightweigit.BuildURL(
-		"https",
-		rel.Provider.host,
-		fmt.Sprintf("api/v4/projects/%d/repository/archive.tar.gz", rel.Provider.id),
-		fmt.Sprintf("sha=%s", url.QueryEscape(rel.tag.String())),
-	)
+	return rel.Provider.archiveURL(rel.tag.String(), lightweigit.ArchiveTarGz)
 }
 
 func (rel *ReleaseObj) Assets() []lightweigit.ProviderReleaseAssetInterface {
@@ -81,6 +72,12 @@ func (rel *ReleaseObj) IsPrerelease() bool {
 	return rel.isPrerelease
 }
 
+// Time is when the release was published; zero when the forge did not
+// say.
+func (rel *ReleaseObj) Time() time.Time {
+	return rel.published
+}
+
 // // // //
 
 type releaseLinkItemObj struct {
@@ -100,6 +97,18 @@ type releaseItemObj struct {
 	Assets            struct {
 		Links []releaseLinkItemObj `json:"links"`
 	} `json:"assets"`
+
+	ReleasedAt string `json:"released_at"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// published is released_at, which a release dated in the past or future
+// sets apart from created_at.
+func (li releaseItemObj) published() time.Time {
+	if t := lightweigit.ParseTime(li.ReleasedAt); !t.IsZero() {
+		return t
+	}
+	return lightweigit.ParseTime(li.CreatedAt)
 }
 
 func buildReleaseObj(obj *Obj, li releaseItemObj) *ReleaseObj {
@@ -135,6 +144,7 @@ func buildReleaseObj(obj *Obj, li releaseItemObj) *ReleaseObj {
 		bodyMD:       li.Description,
 		assets:       assets,
 		isPrerelease: li.UpcomingRelease,
+		published:    li.published(),
 	}
 }
 
@@ -28,21 +28,11 @@ func (tag *TagObj) URL() *url.URL {
 }
 
 func (tag *TagObj) ZIP() *url.URL {
-	return lightweigit.BuildURL(
-		"https",
-		tag.Provider.host,
-		fmt.Sprintf("api/v4/projects/%d/repository/archive.zip", tag.Provider.id),
-		fmt.Sprintf("sha=%s", url.QueryEscape(tag.name)),
-	)
+	return tag.Provider.archiveURL(tag.name, lightweigit.ArchiveZIP)
 }
 
 func (tag *TagObj) TAR() *url.URL {
-	return lightweigit.BuildURL(
-		"https",
-		tag.Provider.host,
-		fmt.Sprintf("api/v4/projects/%d/repository/archive.tar.gz", tag.Provider.id),
-		fmt.Sprintf("sha=%s", url.QueryEscape(tag.name)),
-	)
+	return tag.Provider.archiveURL(tag.name, lightweigit.ArchiveTarGz)
 }
 
 // // // //
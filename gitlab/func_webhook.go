@@ -0,0 +1,58 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// hookReleaseObj is a Release Hook delivery. It names the fields of the
+// REST release differently and has no upcoming_release flag.
+type hookReleaseObj struct {
+	Tag         string `json:"tag"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ReleasedAt  string `json:"released_at"`
+	Assets      struct {
+		Links []releaseLinkItemObj `json:"links"`
+	} `json:"assets"`
+}
+
+// hookTimeLayouts are the forms released_at comes in: the hook's own and
+// the ISO 8601 of the API.
+var hookTimeLayouts = []string{"2006-01-02 15:04:05 MST", time.RFC3339}
+
+// WebhookRelease reads a whole Release Hook payload. A release dated in
+// the future counts as upcoming, as upcoming_release does in the API.
+func (obj *Obj) WebhookRelease(payload []byte) (lightweigit.ProviderReleaseInterface, error) {
+	var h hookReleaseObj
+	if err := json.Unmarshal(payload, &h); err != nil {
+		return nil, err
+	}
+	if h.Tag == "" {
+		return nil, errors.New("webhook release without tag")
+	}
+
+	li := releaseItemObj{
+		TagName:     h.Tag,
+		Name:        h.Name,
+		Description: h.Description,
+	}
+	li.Assets.Links = h.Assets.Links
+	for _, layout := range hookTimeLayouts {
+		if t, err := time.Parse(layout, h.ReleasedAt); err == nil {
+			li.UpcomingRelease = t.After(time.Now())
+			li.ReleasedAt = t.Format(time.RFC3339)
+			break
+		}
+	}
+	return buildReleaseObj(obj, li), nil
+}
+
+func (obj *Obj) WebhookTag(name string) lightweigit.ProviderTagInterface {
+	return &TagObj{Provider: obj, name: name}
+}
@@ -2,6 +2,8 @@ package gitlab
 
 import (
 	"net/url"
+	"sync"
+	"time"
 
 	"github.com/voluminor/lightweigit-loader"
 )
@@ -11,8 +13,15 @@ import (
 type Obj struct {
 	name string
 	host string
-
-	id uint32
+	api  string
+
+	// mu guards the fields below, which a lazy resolve sets while other
+	// goroutines may use the handle; resolving serializes resolves.
+	mu        sync.RWMutex
+	resolving sync.Mutex
+	id        uint32
+	sshURL    string
+	httpURL   string
 }
 
 type TagObj struct {
@@ -20,6 +29,13 @@ type TagObj struct {
 	name     string
 }
 
+// BranchObj is a branch and the head commit it had when it was listed.
+type BranchObj struct {
+	Provider *Obj
+	name     string
+	commit   string
+}
+
 type ReleaseAssetObj struct {
 	download    url.URL
 	contentType string
@@ -33,4 +49,5 @@ type ReleaseObj struct {
 	bodyMD       string
 	assets       []lightweigit.ProviderReleaseAssetInterface
 	isPrerelease bool
+	published    time.Time
 }
@@ -1,6 +1,7 @@
 package gogsFamily
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,8 +13,12 @@ import (
 
 // // // // // // // // // // // // // // // //
 
-func getBytes(obj lightweigit.ProviderInterface, absURL string, accept string, limitBytes int64) ([]byte, int, error) {
-	req, err := http.NewRequest(http.MethodGet, absURL, nil)
+func getBytes(ctx context.Context, obj lightweigit.ProviderInterface, absURL string, accept string, limitBytes int64) ([]byte, int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, absURL, nil)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -38,7 +43,14 @@ func getBytes(obj lightweigit.ProviderInterface, absURL string, accept string, l
 		if obj != nil {
 			t = obj.Type()
 		}
-		return nil, resp.StatusCode, fmt.Errorf("%s api error: %s: %s", t, resp.Status, strings.TrimSpace(string(b)))
+		detail := strings.TrimSpace(string(b))
+		switch resp.StatusCode {
+		case http.StatusForbidden:
+			return nil, resp.StatusCode, fmt.Errorf("%s api error: %s: %s: %w", t, resp.Status, detail, lightweigit.ErrForbidden)
+		case http.StatusTooManyRequests:
+			return nil, resp.StatusCode, fmt.Errorf("%s api error: %s: %s: %w", t, resp.Status, detail, lightweigit.ErrTooManyRequests)
+		}
+		return nil, resp.StatusCode, fmt.Errorf("%s api error: %s: %s", t, resp.Status, detail)
 	}
 
 	b, err := io.ReadAll(io.LimitReader(resp.Body, limitBytes))
@@ -49,15 +61,49 @@ func getBytes(obj lightweigit.ProviderInterface, absURL string, accept string, l
 }
 
 func (obj *Obj) getJSON(u string, out any) error {
-	return lightweigit.GetJSON(obj, fmt.Sprintf("https://%s/api/v1/repos/%s/%s", obj.host, obj.name, u), out)
+	if _, err := obj.ensureResolved(context.Background()); err != nil {
+		return err
+	}
+	return lightweigit.GetJSON(obj, fmt.Sprintf("https://%s%s/repos/%s/%s", obj.host, obj.apiBase(), obj.repoName(), u), out)
+}
+
+// apiBase is the API root path: "/api/v1" unless the host is pinned with
+// another one.
+func (obj *Obj) apiBase() string {
+	if obj.api != "" {
+		return obj.api
+	}
+	return "/api/v1"
 }
 
 // // // // // // // // // // // // // // // //
 
-func (obj *Obj) Kind() KindType { return obj.kind }
+func (obj *Obj) Kind() KindType {
+	obj.mu.RLock()
+	defer obj.mu.RUnlock()
+	return obj.kind
+}
 
 func (obj *Obj) Type() string {
-	return obj.kind.String()
+	return obj.Kind().String()
+}
+
+// repoName is the owner/repo path, the first candidate until resolved.
+func (obj *Obj) repoName() string {
+	obj.mu.RLock()
+	defer obj.mu.RUnlock()
+	return obj.name
+}
+
+// Identity is shared by Gitea, Forgejo and Gogs, so a detection that
+// changes the kind does not change the identity. Owner and repository
+// names are case-insensitive on all three.
+func (obj *Obj) Identity() lightweigit.IdentityObj {
+	return lightweigit.IdentityObj{
+		Family: "gogsFamily",
+		Host:   lightweigit.NormalizeHost(obj.host),
+		Name:   strings.ToLower(obj.repoName()),
+	}
 }
 
 func (obj *Obj) Domain() string {
@@ -65,7 +111,7 @@ func (obj *Obj) Domain() string {
 }
 
 func (obj *Obj) String() string {
-	return obj.name
+	return obj.repoName()
 }
 
 func (obj *Obj) URL() *url.URL {
@@ -75,7 +121,45 @@ func (obj *Obj) URL() *url.URL {
 	return lightweigit.BuildURL(
 		"https",
 		obj.host,
-		obj.name,
+		lightweigit.WebPrefix(obj.api)+"/"+obj.repoName(),
 		"",
 	)
 }
+
+// CloneHTTPS is the forge's clone_url once known (see FetchCloneURLs),
+// else https://host[/prefix]/owner/repo.git.
+func (obj *Obj) CloneHTTPS() string {
+	obj.mu.RLock()
+	defer obj.mu.RUnlock()
+	if obj.cloneURL != "" {
+		return obj.cloneURL
+	}
+	return "https://" + obj.host + lightweigit.WebPrefix(obj.api) + "/" + obj.name + ".git"
+}
+
+// CloneSSH is the forge's ssh_url once known, which names a non-standard
+// SSH port; else git@host:owner/repo.git.
+func (obj *Obj) CloneSSH() string {
+	obj.mu.RLock()
+	defer obj.mu.RUnlock()
+	if obj.sshURL != "" {
+		return obj.sshURL
+	}
+	return "git@" + lightweigit.NormalizeHost(obj.host) + ":" + obj.name + ".git"
+}
+
+// //
+
+func (obj *Obj) IssueURL(n int) string {
+	return lightweigit.AddURL(obj.URL(), fmt.Sprintf("/issues/%d", n), "").String()
+}
+
+func (obj *Obj) UserURL(name string) string {
+	return lightweigit.BuildURL("https", obj.host, lightweigit.WebPrefix(obj.api)+"/"+name, "").String()
+}
+
+// FileURL uses the untyped /src/<ref>/ form: Gogs knows no other, and
+// Gitea and Forgejo look the ref up as a branch, tag or commit.
+func (obj *Obj) FileURL(ref, path string) string {
+	return lightweigit.AddURL(obj.URL(), "/src/"+ref+"/"+strings.TrimPrefix(path, "/"), "").String()
+}
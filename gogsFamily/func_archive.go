@@ -0,0 +1,35 @@
+package gogsFamily
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// archiveURL is the archive link of ref. The ref goes in unescaped and
+// AddURL escapes it once; slashes of branches such as release/1.0 stay.
+func (obj *Obj) archiveURL(ref string, format lightweigit.ArchiveFormatType) *url.URL {
+	return lightweigit.AddURL(
+		obj.URL(),
+		"/archive/"+ref+format.Ext(),
+		"",
+	)
+}
+
+// Archive links the source of ref, a branch, tag or commit SHA. Gitea,
+// Forgejo and Gogs build zip and tar.gz archives only.
+func (obj *Obj) Archive(ref string, format lightweigit.ArchiveFormatType) (*url.URL, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty ref")
+	}
+	if format != lightweigit.ArchiveZIP && format != lightweigit.ArchiveTarGz {
+		return nil, fmt.Errorf("%s archives: %w", format, lightweigit.ErrUnsupported)
+	}
+	return obj.archiveURL(ref, format), nil
+}
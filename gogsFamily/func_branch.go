@@ -0,0 +1,109 @@
+package gogsFamily
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func (br *BranchObj) Mod() target.ModType {
+	return target.ModGogsFamilyBranch
+}
+
+func (br *BranchObj) String() string {
+	return br.name
+}
+
+func (br *BranchObj) Commit() string {
+	return br.commit
+}
+
+func (br *BranchObj) URL() *url.URL {
+	return lightweigit.AddURL(
+		br.Provider.URL(),
+		"/src/branch/"+br.name,
+		"",
+	)
+}
+
+func (br *BranchObj) ZIP() *url.URL {
+	return br.Provider.archiveURL(br.name, lightweigit.ArchiveZIP)
+}
+
+func (br *BranchObj) TAR() *url.URL {
+	return br.Provider.archiveURL(br.name, lightweigit.ArchiveTarGz)
+}
+
+// // // //
+
+type branchItemObj struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+func (obj *Obj) branch(li branchItemObj) *BranchObj {
+	return &BranchObj{
+		Provider: obj,
+		name:     li.Name,
+		commit:   li.Commit.ID,
+	}
+}
+
+// //
+
+// BranchDefault reads the default branch name from the repository, then
+// its head.
+func (obj *Obj) BranchDefault() (lightweigit.ProviderBranchInterface, error) {
+	info, err := obj.RepositoryInfo()
+	if err != nil {
+		return nil, err
+	}
+	if info.DefaultBranch == "" {
+		return nil, lightweigit.ErrNotFound
+	}
+	return obj.BranchFind(info.DefaultBranch)
+}
+
+// BranchFind keeps slashes in the name: the branch routes of the family
+// take the rest of the path.
+func (obj *Obj) BranchFind(findBranch string) (lightweigit.ProviderBranchInterface, error) {
+	var li branchItemObj
+	if err := obj.getJSON(fmt.Sprintf("branches/%s", findBranch), &li); err != nil {
+		return nil, err
+	}
+	if li.Name == "" {
+		li.Name = findBranch
+	}
+	return obj.branch(li), nil
+}
+
+// BranchesStream pages with limit/page. Gogs ignores both and answers
+// with every branch at once; a page longer than asked for is taken as that
+// whole list and ends the stream.
+func (obj *Obj) BranchesStream(ctx context.Context, out chan lightweigit.ProviderBranchInterface, limit int) error {
+	unpaged := false
+	return lightweigit.StreamPages(ctx, 50, limit,
+		func(perPage, page int) ([]branchItemObj, error) {
+			if unpaged {
+				return nil, nil
+			}
+
+			var branches []branchItemObj
+			if err := obj.getJSON(fmt.Sprintf("branches?limit=%d&page=%d", perPage, page), &branches); err != nil {
+				return nil, err
+			}
+			unpaged = len(branches) > perPage
+			return branches, nil
+		},
+		func(li branchItemObj) error {
+			return lightweigit.Send[lightweigit.ProviderBranchInterface](ctx, out, obj.branch(li))
+		},
+	)
+}
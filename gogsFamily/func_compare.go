@@ -0,0 +1,89 @@
+package gogsFamily
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type compareRespObj struct {
+	Commits []struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name  string `json:"name"`
+				Email string `json:"email"`
+				Date  string `json:"date"`
+			} `json:"author"`
+		} `json:"commit"`
+		Files []struct {
+			Filename string `json:"filename"`
+			Status   string `json:"status"`
+		} `json:"files"`
+	} `json:"commits"`
+}
+
+// //
+
+// Compare reads compare/base...head, which Gitea and Forgejo serve and Gogs
+// does not (ErrUnsupported). The changed files are the union of
+// the files of each commit, with the status of the latest one; the forge
+// reports no line counts there.
+func (obj *Obj) Compare(base, head string) (*lightweigit.CompareObj, error) {
+	base, head = strings.TrimSpace(base), strings.TrimSpace(head)
+	if base == "" || head == "" {
+		return nil, errors.New("empty ref")
+	}
+	if obj.Kind() == TypeGogs {
+		return nil, fmt.Errorf("gogs compare: %w", lightweigit.ErrUnsupported)
+	}
+
+	var cr compareRespObj
+	if err := obj.getJSON(fmt.Sprintf("compare/%s...%s", url.PathEscape(base), url.PathEscape(head)), &cr); err != nil {
+		return nil, err
+	}
+
+	res := &lightweigit.CompareObj{Base: base, Head: head}
+	for _, li := range cr.Commits {
+		res.Commits = append(res.Commits, lightweigit.CommitObj{
+			SHA:     li.SHA,
+			Author:  li.Commit.Author.Name,
+			Email:   li.Commit.Author.Email,
+			Message: li.Commit.Message,
+			Date:    lightweigit.ParseTime(li.Commit.Author.Date),
+		})
+	}
+	// Some releases list the newest commit first.
+	n := len(res.Commits)
+	newestFirst := n > 1 && res.Commits[0].Date.After(res.Commits[n-1].Date)
+	if newestFirst {
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			res.Commits[i], res.Commits[j] = res.Commits[j], res.Commits[i]
+		}
+	}
+
+	seen := make(map[string]bool)
+	for k := range cr.Commits {
+		i := n - 1 - k
+		if newestFirst {
+			i = k
+		}
+		for _, f := range cr.Commits[i].Files {
+			if seen[f.Filename] {
+				continue
+			}
+			seen[f.Filename] = true
+			res.Files = append(res.Files, lightweigit.ChangedFileObj{
+				Path:   f.Filename,
+				Status: lightweigit.FileStatus(f.Status),
+			})
+		}
+	}
+	return res, nil
+}
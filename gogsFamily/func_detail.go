@@ -0,0 +1,87 @@
+package gogsFamily
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+type gitTagRespObj struct {
+	SHA     string `json:"sha"`
+	Message string `json:"message"`
+	Tagger  struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		Date  string `json:"date"`
+	} `json:"tagger"`
+	Object struct {
+		SHA string `json:"sha"`
+	} `json:"object"`
+	Verification *struct {
+		Verified  bool   `json:"verified"`
+		Reason    string `json:"reason"`
+		Signature string `json:"signature"`
+		Payload   string `json:"payload"`
+	} `json:"verification"`
+}
+
+// //
+
+// TagDetail reads tags/name, whose id is the tag object for annotated
+// tags, and then git/tags/id with the tagger and Gitea's signature
+// verification. Gogs serves neither (ErrUnsupported).
+func (obj *Obj) TagDetail(name string) (*lightweigit.TagDetailObj, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("empty tag")
+	}
+	if obj.Kind() == TypeGogs {
+		return nil, fmt.Errorf("gogs tag detail: %w", lightweigit.ErrUnsupported)
+	}
+
+	var li tagItemObj
+	if err := obj.getJSON(fmt.Sprintf("tags/%s", url.PathEscape(name)), &li); err != nil {
+		return nil, err
+	}
+
+	d := &lightweigit.TagDetailObj{
+		Name:   name,
+		Kind:   lightweigit.TagLightweight,
+		SHA:    li.Commit.SHA,
+		Commit: li.Commit.SHA,
+	}
+	if li.ID == "" || li.ID == li.Commit.SHA {
+		return d, nil
+	}
+
+	var gt gitTagRespObj
+	if err := obj.getJSON("git/tags/"+li.ID, &gt); err != nil {
+		return nil, err
+	}
+
+	d.Kind = lightweigit.TagAnnotated
+	d.SHA = li.ID
+	if gt.Object.SHA != "" {
+		d.Commit = gt.Object.SHA
+	}
+	d.Message, d.Signature = lightweigit.SplitTagSignature(gt.Message)
+	d.TaggerName = gt.Tagger.Name
+	d.TaggerEmail = gt.Tagger.Email
+	d.TaggerDate = lightweigit.ParseTime(gt.Tagger.Date)
+
+	if v := gt.Verification; v != nil {
+		if v.Signature != "" {
+			d.Signature = v.Signature
+		}
+		d.Payload = v.Payload
+		if v.Verified || v.Signature != "" {
+			d.Verification = &lightweigit.VerificationObj{Verified: v.Verified, Reason: v.Reason}
+		}
+	}
+	return d, nil
+}
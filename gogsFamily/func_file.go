@@ -0,0 +1,39 @@
+package gogsFamily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// File reads path at ref through raw/:ref/:path, the form Gitea, Forgejo
+// and Gogs all serve.
+func (obj *Obj) File(ref, path string) (*lightweigit.FileObj, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty ref")
+	}
+	p, err := lightweigit.CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := obj.Resolve(context.Background()); err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("https://%s%s/repos/%s/raw/%s/%s", obj.host, obj.apiBase(), obj.repoName(), lightweigit.EscapePath(ref), lightweigit.EscapePath(p))
+	b, err := lightweigit.GetRaw(obj, u)
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", p, ref, err)
+	}
+	return lightweigit.NewFile(ref, p, b), nil
+}
+
+func (tag *TagObj) File(path string) (*lightweigit.FileObj, error) {
+	return tag.Provider.File(tag.name, path)
+}
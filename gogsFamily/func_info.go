@@ -0,0 +1,45 @@
+package gogsFamily
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// RepositoryInfo reads the repository; the clone URLs it carries are kept
+// as FetchCloneURLs would. None of the family reports a push time, so
+// Pushed is the last update. Licenses are only detected by Gitea 1.22+ and
+// matching Forgejo releases.
+func (obj *Obj) RepositoryInfo() (*lightweigit.RepositoryInfoObj, error) {
+	ctx := context.Background()
+	if err := obj.Resolve(ctx); err != nil {
+		return nil, err
+	}
+
+	var li repoItemObj
+	u := fmt.Sprintf("https://%s%s/repos/%s", obj.host, obj.apiBase(), obj.repoName())
+	if _, err := getJSONProbe(ctx, obj, u, &li); err != nil {
+		return nil, err
+	}
+	obj.setRepo(li)
+
+	info := &lightweigit.RepositoryInfoObj{
+		Name:          li.FullName,
+		Description:   li.Description,
+		DefaultBranch: li.DefaultBranch,
+		Archived:      li.Archived,
+		Visibility:    lightweigit.Visibility(li.Private, li.Internal),
+		Stars:         li.Stars,
+		Pushed:        lightweigit.ParseTime(li.UpdatedAt),
+	}
+	if li.Parent != nil {
+		info.Parent = li.Parent.HTMLURL
+	}
+	if len(li.Licenses) > 0 {
+		info.License = lightweigit.SPDX(li.Licenses[0])
+	}
+	return info, nil
+}
@@ -1,6 +1,7 @@
 package gogsFamily
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -84,8 +85,28 @@ type versionObj struct {
 	Version string `json:"version"`
 }
 
-func getJSONProbe(obj lightweigit.ProviderInterface, absURL string, out any) (int, error) {
-	b, code, err := getBytes(obj, absURL, "application/json", 1<<20)
+// repoItemObj is the part of the repository API response that is read:
+// the clone URLs kept on the handle and what RepositoryInfo reports.
+type repoItemObj struct {
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+
+	FullName      string   `json:"full_name"`
+	Description   string   `json:"description"`
+	DefaultBranch string   `json:"default_branch"`
+	Archived      bool     `json:"archived"`
+	Private       bool     `json:"private"`
+	Internal      bool     `json:"internal"`
+	Stars         int      `json:"stars_count"`
+	UpdatedAt     string   `json:"updated_at"`
+	Licenses      []string `json:"licenses"`
+	Parent        *struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"parent"`
+}
+
+func getJSONProbe(ctx context.Context, obj lightweigit.ProviderInterface, absURL string, out any) (int, error) {
+	b, code, err := getBytes(ctx, obj, absURL, "application/json", 1<<20)
 	if err != nil {
 		return code, err
 	}
@@ -95,7 +116,7 @@ func getJSONProbe(obj lightweigit.ProviderInterface, absURL string, out any) (in
 	return code, json.Unmarshal(b, out)
 }
 
-func detectProvider(host string) (KindType, error) {
+func detectProvider(ctx context.Context, host string) (KindType, error) {
 	host = strings.TrimSpace(host)
 	host = strings.TrimPrefix(host, "https://")
 	host = strings.TrimPrefix(host, "http://")
@@ -108,7 +129,7 @@ func detectProvider(host string) (KindType, error) {
 	probe := &Obj{host: host, kind: TypeUnknown}
 	var v versionObj
 
-	code, err := getJSONProbe(probe, base+"/api/forgejo/v1/version", &v)
+	code, err := getJSONProbe(ctx, probe, base+"/api/forgejo/v1/version", &v)
 	if err == nil && strings.TrimSpace(v.Version) != "" {
 		return TypeForgejo, nil
 	}
@@ -116,7 +137,7 @@ func detectProvider(host string) (KindType, error) {
 		return TypeForgejo, nil
 	}
 
-	code, err = getJSONProbe(probe, base+"/api/v1/version", &v)
+	code, err = getJSONProbe(ctx, probe, base+"/api/v1/version", &v)
 	if err == nil && strings.TrimSpace(v.Version) != "" {
 		if strings.Contains(v.Version, "+gitea-") {
 			return TypeForgejo, nil
@@ -130,36 +151,173 @@ func detectProvider(host string) (KindType, error) {
 	return TypeUnknown, nil
 }
 
-func probeRepoAPI(host string, name string) bool {
+// probeRepoAPI reports whether the repo API knows name; on success the
+// response body is returned as well (zero when it is not readable).
+func probeRepoAPI(ctx context.Context, host string, name string) (repoItemObj, bool) {
+	var repo repoItemObj
+
 	host = strings.TrimSpace(host)
 	host = strings.TrimPrefix(host, "https://")
 	host = strings.TrimPrefix(host, "http://")
 	host = strings.TrimRight(host, "/")
 	if host == "" || name == "" {
-		return false
+		return repo, false
 	}
 
 	probe := &Obj{host: host, kind: TypeUnknown}
 
 	u := "https://" + host + "/api/v1/repos/" + strings.TrimLeft(name, "/")
-	_, code, err := getBytes(probe, u, "application/json", 256<<10)
+	b, code, err := getBytes(ctx, probe, u, "application/json", 256<<10)
 	if err == nil {
-		return true
+		_ = json.Unmarshal(b, &repo)
+		return repo, true
 	}
 
 	if code == 401 || code == 403 {
-		return true
+		return repo, true
 	}
 	if errors.Is(err, lightweigit.ErrNotFound) {
-		return false
+		return repo, false
 	}
 
-	return false
+	return repo, false
+}
+
+// guessKind names the forge from the host alone; it is only a hint for
+// ParseOffline handles and is replaced by detectProvider on resolve.
+func guessKind(host string) KindType {
+	switch {
+	case strings.Contains(host, "forgejo"), host == "codeberg.org":
+		return TypeForgejo
+	case strings.Contains(host, "gitea"):
+		return TypeGitea
+	case strings.Contains(host, "gogs"):
+		return TypeGogs
+	}
+	return TypeUnknown
+}
+
+// pending reports whether the handle still waits for resolve.
+func (obj *Obj) pending() bool {
+	obj.mu.RLock()
+	defer obj.mu.RUnlock()
+	return obj.cands != nil
+}
+
+// ensureResolved runs resolve on a pending handle, one caller at a time;
+// the others wait for it and find the handle resolved.
+func (obj *Obj) ensureResolved(ctx context.Context) (bool, error) {
+	if !obj.pending() {
+		return true, nil
+	}
+	obj.resolving.Lock()
+	defer obj.resolving.Unlock()
+	if !obj.pending() {
+		return true, nil
+	}
+	return obj.resolve(ctx)
+}
+
+// resolve detects the forge kind and picks the first owner/repo candidate
+// the repo API confirms. Without a confirmation the last candidate is kept
+// and false is returned. It runs with obj.resolving held and takes obj.mu
+// only to publish the result.
+func (obj *Obj) resolve(ctx context.Context) (bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	kind, err := detectProvider(ctx, obj.host)
+	if err != nil {
+		return false, err
+	}
+	// Detection treats failed probes as "unknown"; a canceled context must
+	// not be mistaken for that and leaves the handle unresolved.
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	obj.mu.RLock()
+	cands := obj.cands
+	obj.mu.RUnlock()
+
+	// An SSH address names the repository exactly: there is nothing to
+	// choose from once the forge itself answered.
+	if len(cands) == 1 && kind != TypeUnknown {
+		obj.settle(cands[0], kind, nil)
+		return true, nil
+	}
+
+	for _, c := range cands {
+		if repo, ok := probeRepoAPI(ctx, obj.host, c); ok {
+			if kind == TypeUnknown {
+				kind = TypeGogs
+			}
+			obj.settle(c, kind, &repo)
+			return true, nil
+		}
+	}
+
+	obj.settle(cands[len(cands)-1], kind, nil)
+	return kind != TypeUnknown, nil
+}
+
+// settle publishes the outcome of resolve.
+func (obj *Obj) settle(name string, kind KindType, repo *repoItemObj) {
+	obj.mu.Lock()
+	defer obj.mu.Unlock()
+	obj.name, obj.kind, obj.cands = name, kind, nil
+	if repo != nil {
+		obj.cloneURL, obj.sshURL = repo.CloneURL, repo.SSHURL
+	}
+}
+
+// Resolve runs the forge detection and repository probing that ParseOffline
+// skipped. Handles resolve themselves on first use; call Resolve explicitly
+// to control the context. Concurrent first uses of one handle resolve it
+// once. Resolve is a no-op on a resolved handle.
+func (obj *Obj) Resolve(ctx context.Context) error {
+	ok, err := obj.ensureResolved(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s: no Gogs-family repository at %q: %w", obj.host, obj.repoName(), lightweigit.ErrNotFound)
+	}
+	return nil
+}
+
+func (obj *Obj) setRepo(repo repoItemObj) {
+	obj.mu.Lock()
+	defer obj.mu.Unlock()
+	obj.cloneURL = repo.CloneURL
+	obj.sshURL = repo.SSHURL
+}
+
+// FetchCloneURLs reads the clone URLs the forge reports for the
+// repository; ssh_url carries the SSH port of instances that do not run
+// SSH on 22. Until then CloneHTTPS and CloneSSH follow the conventions.
+func (obj *Obj) FetchCloneURLs(ctx context.Context) error {
+	if err := obj.Resolve(ctx); err != nil {
+		return err
+	}
+
+	var repo repoItemObj
+	u := fmt.Sprintf("https://%s%s/repos/%s", obj.host, obj.apiBase(), obj.repoName())
+	if _, err := getJSONProbe(ctx, obj, u, &repo); err != nil {
+		return err
+	}
+	obj.setRepo(repo)
+	return nil
 }
 
 // // // //
 
-func Parse(raw string) (*Obj, error) {
+// ParseOffline splits raw into host and owner/repo without any request.
+// The kind is guessed from the host name and the name is the first path
+// candidate (forges served from the domain root); both are settled by
+// Resolve.
+func ParseOffline(raw string) (*Obj, error) {
 	s := strings.TrimSpace(raw)
 	if s == "" {
 		return nil, errors.New("an empty URL string")
@@ -171,21 +329,11 @@ func Parse(raw string) (*Obj, error) {
 			return nil, err
 		}
 
-		kind, err := detectProvider(host)
-		if err != nil {
-			return nil, err
-		}
-
-		if kind == TypeUnknown {
-			if probeRepoAPI(host, name) {
-				kind = TypeGogs
-			}
-		}
-
 		return &Obj{
-			name: name,
-			host: host,
-			kind: kind,
+			name:  name,
+			host:  host,
+			kind:  guessKind(host),
+			cands: []string{name},
 		}, nil
 	}
 
@@ -224,28 +372,137 @@ func Parse(raw string) (*Obj, error) {
 		return nil, fmt.Errorf("could not find owner/repo in path: %q", u.Path)
 	}
 
-	kind, err := detectProvider(u.Host)
+	host := strings.ToLower(u.Host)
+	if lightweigit.IsSSHScheme(u.Scheme) {
+		host = strings.ToLower(u.Hostname())
+	}
+
+	return &Obj{
+		name:  cands[0],
+		host:  host,
+		kind:  guessKind(strings.ToLower(u.Hostname())),
+		cands: cands,
+	}, nil
+}
+
+// ParsePinned parses raw for a host pinned to the Gitea family in the host
+// map. The kind comes from the pin (gitea when empty) and the repository is
+// the first owner/repo after the pinned web prefix; nothing is probed.
+func ParsePinned(raw string, pin lightweigit.HostPinObj) (*Obj, error) {
+	kind := TypeGitea
+	if pin.Kind != "" {
+		kind = ParseKind(pin.Kind)
+		if kind == TypeUnknown {
+			return nil, fmt.Errorf("unknown Gogs-family kind: %q", pin.Kind)
+		}
+	}
+
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return nil, errors.New("an empty URL string")
+	}
+
+	if !strings.Contains(s, "://") && strings.Contains(s, "@") && strings.Contains(s, ":") {
+		host, name, err := parseScpLikeAny(s)
+		if err != nil {
+			return nil, err
+		}
+		return &Obj{name: name, host: host, api: pin.APIBase, kind: kind}, nil
+	}
+
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
 	if err != nil {
+		return nil, fmt.Errorf("URL could not be parsed: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("URL has no host: %q", raw)
+	}
+
+	p := u.Path
+	if prefix := lightweigit.WebPrefix(pin.APIBase); prefix != "" && strings.HasPrefix(p, prefix+"/") {
+		p = p[len(prefix):]
+	}
+
+	owner, repo, ok := ownerRepoFromPathSegments(strings.Split(strings.Trim(p, "/"), "/"), 0)
+	if !ok {
+		return nil, fmt.Errorf("expected a path of the type /owner/repo, received: %q", u.Path)
+	}
+
+	host := strings.ToLower(u.Host)
+	if lightweigit.IsSSHScheme(u.Scheme) {
+		host = strings.ToLower(u.Hostname())
+	}
+	return &Obj{name: owner + "/" + repo, host: host, api: pin.APIBase, kind: kind}, nil
+}
+
+func Parse(raw string) (*Obj, error) {
+	obj, err := ParseOffline(raw)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := obj.resolve(context.Background()); err != nil {
 		return nil, err
 	}
+	return obj, nil
+}
 
-	for _, c := range cands {
-		if probeRepoAPI(u.Host, c) {
-			if kind == TypeUnknown {
-				kind = TypeGogs
+// refFromTail reads the ref out of the path segments after owner/repo.
+// Gitea and Forgejo qualify source pages with branch/tag/commit; Gogs does
+// not, and its refs come back as files or trees at an unqualified name.
+func refFromTail(tail []string) lightweigit.RefObj {
+	if len(tail) < 2 {
+		return lightweigit.RefObj{}
+	}
+
+	qualified := func(k lightweigit.RefKindType, rest []string) lightweigit.RefObj {
+		if len(rest) >= 2 {
+			switch rest[0] {
+			case "branch":
+				return lightweigit.RefAt(k, lightweigit.RefBranch, rest[1:])
+			case "tag":
+				return lightweigit.RefAt(k, lightweigit.RefTag, rest[1:])
+			case "commit":
+				return lightweigit.RefAt(k, lightweigit.RefCommit, rest[1:])
 			}
-			return &Obj{
-				name: c,
-				host: u.Host,
-				kind: kind,
-			}, nil
 		}
+		return lightweigit.RefAt(k, lightweigit.RefTree, rest)
 	}
 
-	last := cands[len(cands)-1]
-	return &Obj{
-		name: last,
-		host: u.Host,
-		kind: kind,
-	}, nil
+	switch tail[0] {
+	case "src", "raw", "blame":
+		return qualified(lightweigit.RefFile, tail[1:])
+	case "releases":
+		if len(tail) >= 3 && (tail[1] == "tag" || tail[1] == "download") {
+			return lightweigit.RefObj{Kind: lightweigit.RefRelease, Name: tail[2]}
+		}
+	case "commit":
+		return lightweigit.RefAt(lightweigit.RefCommit, lightweigit.RefCommit, tail[1:2])
+	case "commits":
+		return qualified(lightweigit.RefTree, tail[1:])
+	case "archive":
+		name := strings.Join(tail[1:], "/")
+		for _, ext := range []string{".tar.gz", ".zip", ".bundle"} {
+			name = strings.TrimSuffix(name, ext)
+		}
+		return lightweigit.RefObj{Kind: lightweigit.RefTree, Name: name}
+	}
+	return lightweigit.RefObj{}
+}
+
+// Ref reads the tag, release, branch, commit, file or tree raw points at.
+// An unresolved handle tries each owner/repo candidate of the path.
+func (obj *Obj) Ref(raw string) lightweigit.RefObj {
+	obj.mu.RLock()
+	names := append([]string{obj.name}, obj.cands...)
+	obj.mu.RUnlock()
+	for _, name := range names {
+		if ref := refFromTail(lightweigit.PathAfter(raw, name)); ref.Kind != lightweigit.RefNone {
+			return ref
+		}
+	}
+	return lightweigit.RefObj{}
 }
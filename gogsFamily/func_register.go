@@ -0,0 +1,24 @@
+package gogsFamily
+
+import (
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func init() {
+	// Catch-all with no Match: Parse probes the host for a Gitea-family forge.
+	lightweigit.RegisterBuiltin(lightweigit.ProviderEntryObj{
+		Name:     "gogsFamily",
+		Priority: 0,
+
+		TagMod:           target.ModGogsFamilyTag,
+		ReleaseMod:       target.ModGogsFamilyRelease,
+		UnmarshalTag:     UnmarshalTag,
+		UnmarshalRelease: UnmarshalRelease,
+
+		BranchMod:       target.ModGogsFamilyBranch,
+		UnmarshalBranch: UnmarshalBranch,
+	}, Parse, ParseOffline, ParsePinned)
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/voluminor/lightweigit-loader"
 	"github.com/voluminor/lightweigit-loader/target"
@@ -57,19 +58,11 @@ func (rel *ReleaseObj) Tag() lightweigit.ProviderTagInterface {
 }
 
 func (rel *ReleaseObj) ZIP() *url.URL {
-	return lightweigit.AddURL(
-		rel.Provider.URL(),
-		"/archive/"+url.PathEscape(rel.tag.String())+".zip",
-		"",
-	)
+	return rel.Provider.archiveURL(rel.tag.String(), lightweigit.ArchiveZIP)
 }
 
 func (rel *ReleaseObj) TAR() *url.URL {
-	return lightweigit.AddURL(
-		rel.Provider.URL(),
-		"/archive/"+url.PathEscape(rel.tag.String())+".tar.gz",
-		"",
-	)
+	return rel.Provider.archiveURL(rel.tag.String(), lightweigit.ArchiveTarGz)
 }
 
 func (rel *ReleaseObj) Assets() []lightweigit.ProviderReleaseAssetInterface {
@@ -80,6 +73,12 @@ func (rel *ReleaseObj) IsPrerelease() bool {
 	return rel.isPrerelease
 }
 
+// Time is when the release was published; zero when the forge did not
+// say.
+func (rel *ReleaseObj) Time() time.Time {
+	return rel.published
+}
+
 // // // //
 
 type releaseAssetItemObj struct {
@@ -96,6 +95,18 @@ type releaseItemObj struct {
 	Draft      bool                  `json:"draft"`
 	Prerelease bool                  `json:"prerelease"`
 	Assets     []releaseAssetItemObj `json:"assets"`
+
+	PublishedAt string `json:"published_at"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// published is the publication time, or the creation time for releases
+// published before the field existed.
+func (li releaseItemObj) published() time.Time {
+	if t := lightweigit.ParseTime(li.PublishedAt); !t.IsZero() {
+		return t
+	}
+	return lightweigit.ParseTime(li.CreatedAt)
 }
 
 func buildReleaseObj(obj *Obj, li releaseItemObj) *ReleaseObj {
@@ -135,6 +146,7 @@ func buildReleaseObj(obj *Obj, li releaseItemObj) *ReleaseObj {
 		bodyMD:       li.Body,
 		assets:       assets,
 		isPrerelease: isPrerelease,
+		published:    li.published(),
 	}
 }
 
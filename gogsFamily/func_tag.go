@@ -30,19 +30,11 @@ func (tag *TagObj) URL() *url.URL {
 }
 
 func (tag *TagObj) ZIP() *url.URL {
-	return lightweigit.AddURL(
-		tag.Provider.URL(),
-		"/archive/"+url.PathEscape(tag.name)+".zip",
-		"",
-	)
+	return tag.Provider.archiveURL(tag.name, lightweigit.ArchiveZIP)
 }
 
 func (tag *TagObj) TAR() *url.URL {
-	return lightweigit.AddURL(
-		tag.Provider.URL(),
-		"/archive/"+url.PathEscape(tag.name)+".tar.gz",
-		"",
-	)
+	return tag.Provider.archiveURL(tag.name, lightweigit.ArchiveTarGz)
 }
 
 // // // //
@@ -0,0 +1,27 @@
+package gogsFamily
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// WebhookRelease reads the "release" object of a release delivery, which
+// has the shape of the REST API's.
+func (obj *Obj) WebhookRelease(payload []byte) (lightweigit.ProviderReleaseInterface, error) {
+	var li releaseItemObj
+	if err := json.Unmarshal(payload, &li); err != nil {
+		return nil, err
+	}
+	if li.TagName == "" {
+		return nil, errors.New("webhook release without tag_name")
+	}
+	return buildReleaseObj(obj, li), nil
+}
+
+func (obj *Obj) WebhookTag(name string) lightweigit.ProviderTagInterface {
+	return &TagObj{Provider: obj, name: name}
+}
@@ -2,6 +2,9 @@ package gogsFamily
 
 import (
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/voluminor/lightweigit-loader"
 )
@@ -17,6 +20,20 @@ const (
 	TypeGogs
 )
 
+// ParseKind is the inverse of KindType.String; "" and unknown names give
+// TypeUnknown.
+func ParseKind(s string) KindType {
+	switch strings.ToLower(s) {
+	case "gitea":
+		return TypeGitea
+	case "forgejo":
+		return TypeForgejo
+	case "gogs":
+		return TypeGogs
+	}
+	return TypeUnknown
+}
+
 func (k KindType) String() string {
 	switch k {
 	case TypeGitea:
@@ -33,9 +50,24 @@ func (k KindType) String() string {
 // // // //
 
 type Obj struct {
-	name string
 	host string
+	api  string
+
+	// mu guards the fields below, which a lazy resolve may rewrite while
+	// other goroutines use the handle; resolving serializes resolves.
+	mu        sync.RWMutex
+	resolving sync.Mutex
+
+	name string
 	kind KindType
+
+	// cands holds the owner/repo candidates of a ParseOffline handle that
+	// still waits for resolve; nil once the handle is resolved.
+	cands []string
+
+	// cloneURL and sshURL are what the repo API reported, if it was asked.
+	cloneURL string
+	sshURL   string
 }
 
 type TagObj struct {
@@ -43,6 +75,13 @@ type TagObj struct {
 	name     string
 }
 
+// BranchObj is a branch and the head commit it had when it was listed.
+type BranchObj struct {
+	Provider *Obj
+	name     string
+	commit   string
+}
+
 type ReleaseAssetObj struct {
 	download    url.URL
 	contentType string
@@ -56,4 +95,5 @@ type ReleaseObj struct {
 	bodyMD       string
 	assets       []lightweigit.ProviderReleaseAssetInterface
 	isPrerelease bool
+	published    time.Time
 }
@@ -0,0 +1,173 @@
+package lightweigit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// // // // // // // // // // // // // // // //
+
+// HostMapEnv is the environment variable read by HostMapObj.LoadEnv when
+// no other name is given.
+const HostMapEnv = "LIGHTWEIGIT_HOSTS"
+
+// HostPinObj pins a host to one registered provider.
+//
+// Kind selects the flavour for providers that have several (gogsFamily:
+// gitea, forgejo, gogs). APIBase is the API root path on the host; empty
+// means the provider default ("/api/v4" for GitLab, "/api/v1" for the
+// Gitea family). A forge served under a sub-path is pinned with that path in
+// front, e.g. "/gitlab/api/v4": everything before "/api/" is then treated as
+// the web prefix of the forge.
+type HostPinObj struct {
+	Host     string
+	Provider string
+	Kind     string
+	APIBase  string
+}
+
+// WebPrefix returns the path a forge with the given API root is served
+// under: "/gitlab" for "/gitlab/api/v4", "" for "/api/v4".
+func WebPrefix(apiBase string) string {
+	if i := strings.Index(apiBase, "/api/"); i > 0 {
+		return strings.TrimRight(apiBase[:i], "/")
+	}
+	return ""
+}
+
+// HostMapObj holds host pins. A pinned host is parsed by its provider only,
+// without any detection or probing.
+type HostMapObj struct {
+	mu   sync.RWMutex
+	pins map[string]HostPinObj
+}
+
+// HostMap is the process-wide host map consulted by Registry.
+var HostMap = new(HostMapObj)
+
+// //
+
+func (m *HostMapObj) Set(pin HostPinObj) error {
+	pin.Host = HostOf(pin.Host)
+	if pin.Host == "" {
+		return errors.New("host map: empty host")
+	}
+	if pin.Provider == "" {
+		return fmt.Errorf("host map %s: empty provider", pin.Host)
+	}
+	if pin.APIBase != "" && !strings.HasPrefix(pin.APIBase, "/") {
+		return fmt.Errorf("host map %s: api base must start with '/': %q", pin.Host, pin.APIBase)
+	}
+	pin.APIBase = strings.TrimRight(pin.APIBase, "/")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pins == nil {
+		m.pins = make(map[string]HostPinObj)
+	}
+	m.pins[pin.Host] = pin
+	return nil
+}
+
+func (m *HostMapObj) Get(host string) (HostPinObj, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pin, ok := m.pins[HostOf(host)]
+	return pin, ok
+}
+
+func (m *HostMapObj) Remove(host string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pins, HostOf(host))
+}
+
+// // // //
+
+// Parse reads pins in the text form shared by files and the environment:
+// one entry per line or per ';', fields separated by spaces, '#' starts a
+// comment.
+//
+//	# host                 provider[:kind]      [api-base]
+//	git.company.internal   gitlab
+//	code.company.internal  gogsFamily:forgejo   /forge/api/v1
+//
+// Either every entry is applied or, on a syntax error, none.
+func (m *HostMapObj) Parse(text string) error {
+	entries := strings.FieldsFunc(text, func(r rune) bool {
+		return r == '\n' || r == ';'
+	})
+
+	pins := make([]HostPinObj, 0, len(entries))
+	for n, entry := range entries {
+		if i := strings.Index(entry, "#"); i >= 0 {
+			entry = entry[:i]
+		}
+		f := strings.Fields(entry)
+		if len(f) == 0 {
+			continue
+		}
+		if len(f) > 3 || len(f) < 2 {
+			return fmt.Errorf("host map entry %d: expected \"host provider[:kind] [api-base]\", got %q", n+1, strings.TrimSpace(entry))
+		}
+
+		pin := HostPinObj{Host: f[0], Provider: f[1]}
+		if i := strings.Index(pin.Provider, ":"); i >= 0 {
+			pin.Provider, pin.Kind = pin.Provider[:i], pin.Provider[i+1:]
+		}
+		if len(f) == 3 {
+			pin.APIBase = f[2]
+		}
+		pins = append(pins, pin)
+	}
+
+	probe := new(HostMapObj)
+	for _, pin := range pins {
+		if err := probe.Set(pin); err != nil {
+			return err
+		}
+	}
+	for _, pin := range pins {
+		m.Set(pin)
+	}
+	return nil
+}
+
+func (m *HostMapObj) Load(r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return m.Parse(string(b))
+}
+
+func (m *HostMapObj) LoadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := m.Parse(string(b)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadEnv reads pins from the environment variable name (HostMapEnv when
+// empty). An unset variable is not an error.
+func (m *HostMapObj) LoadEnv(name string) error {
+	if name == "" {
+		name = HostMapEnv
+	}
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	if err := m.Parse(v); err != nil {
+		return fmt.Errorf("$%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package lightweigit
+
+import (
+	"net"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+// IdentityObj names a repository independently of the URL it was parsed
+// from. Two handles of one repository have equal identities however they
+// were spelled: SSH or HTTPS, with or without "www.", ".git" or a port,
+// in any letter case the forge itself ignores.
+//
+// Family is the registry name of the provider ("gogsFamily" for Gitea,
+// Forgejo and Gogs alike, so that detection does not split one forge).
+// Host is lower-case and has no port: SSH and HTTPS of one self-hosted
+// forge rarely share a port. Name is folded to lower case on forges whose
+// owner and repository names are case-insensitive.
+type IdentityObj struct {
+	Family string
+	Host   string
+	Name   string
+}
+
+// ProviderIdentityInterface is implemented by providers that know their
+// own case rules; Identity falls back to a case-preserving guess for the
+// others.
+type ProviderIdentityInterface interface {
+	Identity() IdentityObj
+}
+
+// //
+
+// Key is the stable string form of the identity, for maps and storage.
+func (id IdentityObj) Key() string {
+	return id.Family + ":" + id.Host + "/" + id.Name
+}
+
+func (id IdentityObj) String() string {
+	return id.Key()
+}
+
+func (id IdentityObj) IsZero() bool {
+	return id == IdentityObj{}
+}
+
+// NormalizeHost lower-cases host and drops a port and a trailing dot.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+}
+
+// // // //
+
+// Identity returns the identity of p. Providers implementing
+// ProviderIdentityInterface decide it themselves; for others it is built
+// from Type, Domain and String with the name kept as is.
+func Identity(p ProviderInterface) IdentityObj {
+	if p == nil {
+		return IdentityObj{}
+	}
+	if ip, ok := p.(ProviderIdentityInterface); ok {
+		return ip.Identity()
+	}
+	return IdentityObj{
+		Family: p.Type(),
+		Host:   NormalizeHost(p.Domain()),
+		Name:   strings.Trim(p.String(), "/"),
+	}
+}
+
+// SameRepo reports whether a and b are handles of one repository. Nil
+// handles are never the same repository.
+func SameRepo(a, b ProviderInterface) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	return Identity(a) == Identity(b)
+}
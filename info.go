@@ -0,0 +1,110 @@
+package lightweigit
+
+import (
+	"strings"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+// RepositoryInfoObj holds repository-level facts, normalized across
+// providers. Fields a forge does not report stay at their zero value.
+type RepositoryInfoObj struct {
+	// Name is owner/repo (namespace/repo on GitLab) as the forge spells it.
+	Name          string
+	Description   string
+	DefaultBranch string
+
+	// Archived is set for archived repositories and for those the forge
+	// has disabled: neither receives new versions.
+	Archived bool
+
+	// Parent is the web URL of the repository this one was forked from;
+	// "" for repositories that are not forks.
+	Parent string
+
+	// Visibility is "public", "private" or "internal".
+	Visibility string
+
+	// License is the SPDX identifier of the detected license.
+	License string
+
+	Stars int
+
+	// Pushed is the last push, or the closest the forge reports (last
+	// activity on GitLab, last update on Bitbucket and Gogs).
+	Pushed time.Time
+}
+
+// ProviderInfoInterface is implemented by providers that can describe the
+// repository itself, beyond its tags and releases.
+type ProviderInfoInterface interface {
+	RepositoryInfo() (*RepositoryInfoObj, error)
+}
+
+// //
+
+// IsFork reports whether the repository has a fork parent.
+func (info *RepositoryInfoObj) IsFork() bool {
+	return info.Parent != ""
+}
+
+// Visibility maps a forge's private / internal flags onto the normalized
+// visibility names.
+func Visibility(private, internal bool) string {
+	switch {
+	case internal:
+		return "internal"
+	case private:
+		return "private"
+	}
+	return "public"
+}
+
+// spdxKeys maps lower-case license keys (GitLab's "key", GitHub-style
+// identifiers) to SPDX identifiers.
+var spdxKeys = map[string]string{
+	"0bsd":         "0BSD",
+	"agpl-3.0":     "AGPL-3.0",
+	"apache-2.0":   "Apache-2.0",
+	"artistic-2.0": "Artistic-2.0",
+	"bsd-2-clause": "BSD-2-Clause",
+	"bsd-3-clause": "BSD-3-Clause",
+	"bsl-1.0":      "BSL-1.0",
+	"cc0-1.0":      "CC0-1.0",
+	"epl-2.0":      "EPL-2.0",
+	"gpl-2.0":      "GPL-2.0",
+	"gpl-3.0":      "GPL-3.0",
+	"isc":          "ISC",
+	"lgpl-2.1":     "LGPL-2.1",
+	"lgpl-3.0":     "LGPL-3.0",
+	"mit":          "MIT",
+	"mpl-2.0":      "MPL-2.0",
+	"unlicense":    "Unlicense",
+	"zlib":         "Zlib",
+}
+
+// SPDX normalizes a license key to its SPDX identifier. Known keys are
+// mapped case-insensitively; placeholders such as GitHub's "NOASSERTION"
+// and "other" give "". Unknown keys are returned as they are.
+func SPDX(key string) string {
+	key = strings.TrimSpace(key)
+	switch strings.ToLower(key) {
+	case "", "noassertion", "other":
+		return ""
+	}
+	if id, ok := spdxKeys[strings.ToLower(key)]; ok {
+		return id
+	}
+	return key
+}
+
+// ParseTime reads an RFC 3339 timestamp as the forges send it; malformed
+// or empty values give the zero time.
+func ParseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -3,6 +3,7 @@ package lightweigit
 import (
 	"context"
 	"net/url"
+	"time"
 
 	"github.com/voluminor/lightweigit-loader/target"
 )
@@ -18,6 +19,18 @@ type ProviderTagInterface interface {
 	TAR() *url.URL
 }
 
+// ProviderBranchInterface is a branch head. String is the branch name and
+// Commit the SHA it pointed at when it was read.
+type ProviderBranchInterface interface {
+	Mod() target.ModType
+	Marshal() []byte
+	String() string
+	Commit() string
+	URL() *url.URL
+	ZIP() *url.URL
+	TAR() *url.URL
+}
+
 //
 
 type ProviderReleaseAssetInterface interface {
@@ -56,3 +69,52 @@ type ProviderInterface interface {
 	ReleaseFind(string) (ProviderReleaseInterface, error)
 	ReleasesStream(context.Context, chan ProviderReleaseInterface, int) error
 }
+
+// ProviderBranchesInterface is implemented by providers that list
+// branches. It parallels the tag methods of ProviderInterface;
+// BranchDefault is the branch the repository's HEAD points at.
+type ProviderBranchesInterface interface {
+	BranchDefault() (ProviderBranchInterface, error)
+	BranchFind(string) (ProviderBranchInterface, error)
+	BranchesStream(context.Context, chan ProviderBranchInterface, int) error
+}
+
+// ProviderCloneInterface is implemented by providers that know the URLs
+// git clones their repositories from. CloneHTTPS is an https:// URL;
+// CloneSSH is scp-like (git@host:path.git) or, when the forge runs SSH on
+// another port, an ssh:// URL.
+type ProviderCloneInterface interface {
+	CloneHTTPS() string
+	CloneSSH() string
+}
+
+// ProviderLinksInterface is implemented by providers that know the web
+// URLs of issues, users and files, used to turn "#123", "@user" and
+// relative links in release notes into absolute links.
+type ProviderLinksInterface interface {
+	IssueURL(n int) string
+	UserURL(name string) string
+	FileURL(ref, path string) string
+}
+
+// ProviderWebhookInterface is implemented by providers that build handles
+// from their forge's webhook deliveries. WebhookRelease reads the release
+// object of a release event; WebhookTag is the tag a push named.
+type ProviderWebhookInterface interface {
+	WebhookRelease(payload []byte) (ProviderReleaseInterface, error)
+	WebhookTag(name string) ProviderTagInterface
+}
+
+// ReleaseTimeInterface is implemented by releases that know when they
+// were published: those of GitHub, GitLab and the Gitea family, and
+// snapshots of them.
+type ReleaseTimeInterface interface {
+	Time() time.Time
+}
+
+// ProviderResolverInterface is implemented by providers whose ParseOffline
+// handle defers network validation. Resolve runs it explicitly under ctx;
+// otherwise it happens on the first API call.
+type ProviderResolverInterface interface {
+	Resolve(context.Context) error
+}
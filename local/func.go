@@ -0,0 +1,40 @@
+package local
+
+import (
+	"net/url"
+	"path/filepath"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+func (obj *Obj) Type() string {
+	return "local"
+}
+
+// Domain is empty: a local repository has no host.
+func (obj *Obj) Domain() string {
+	return ""
+}
+
+// String is the absolute path of the repository.
+func (obj *Obj) String() string {
+	return obj.path
+}
+
+// Identity keeps the path as is: whether it is case-sensitive depends on
+// the file system.
+func (obj *Obj) Identity() lightweigit.IdentityObj {
+	return lightweigit.IdentityObj{
+		Family: "local",
+		Name:   filepath.ToSlash(filepath.Clean(obj.path)),
+	}
+}
+
+func (obj *Obj) URL() *url.URL {
+	return &url.URL{
+		Scheme: "file",
+		Path:   filepath.ToSlash(obj.path),
+	}
+}
@@ -0,0 +1,76 @@
+package local
+
+import (
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+type byteObj struct {
+	Path   string
+	Common string
+}
+type byteTagObj struct {
+	Obj       byteObj
+	Name      string
+	Object    string
+	Commit    string
+	Annotated bool
+	Tagger    string
+	Message   string
+	Date      int64
+	Zone      int
+}
+
+//
+
+func (tag *TagObj) Marshal() []byte {
+	dataObj := byteTagObj{
+		Obj: byteObj{
+			Path:   tag.Provider.path,
+			Common: tag.Provider.common,
+		},
+		Name:      tag.name,
+		Object:    tag.object,
+		Commit:    tag.commit,
+		Annotated: tag.annotated,
+		Tagger:    tag.tagger,
+		Message:   tag.message,
+	}
+	if !tag.date.IsZero() {
+		_, dataObj.Zone = tag.date.Zone()
+		dataObj.Date = tag.date.Unix()
+	}
+	return lightweigit.Marshal(tag.Mod(), dataObj)
+}
+
+func UnmarshalTag(data []byte) (lightweigit.ProviderTagInterface, error) {
+	dataObj := new(byteTagObj)
+	mod, err := lightweigit.Unmarshal(data, dataObj)
+	if err != nil {
+		return nil, err
+	}
+	if mod != target.ModLocalTag {
+		return nil, lightweigit.ErrModTag
+	}
+
+	tag := &TagObj{
+		Provider: &Obj{
+			path:   dataObj.Obj.Path,
+			common: dataObj.Obj.Common,
+		},
+		name:      dataObj.Name,
+		object:    dataObj.Object,
+		commit:    dataObj.Commit,
+		annotated: dataObj.Annotated,
+		tagger:    dataObj.Tagger,
+		message:   dataObj.Message,
+	}
+	if dataObj.Date != 0 {
+		tag.date = time.Unix(dataObj.Date, 0).In(time.FixedZone("", dataObj.Zone))
+	}
+	return tag, nil
+}
@@ -0,0 +1,165 @@
+package local
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// resolveCommit maps ref to a commit: "HEAD", a full SHA, a tag or a
+// branch, in that order.
+func (obj *Obj) resolveCommit(ref string) (string, error) {
+	sha := ""
+	switch {
+	case ref == "HEAD":
+		b, err := os.ReadFile(filepath.Join(obj.common, "HEAD"))
+		if err != nil {
+			return "", err
+		}
+		head := strings.TrimSpace(string(b))
+		if strings.HasPrefix(head, "ref: "+branchPrefix) {
+			return obj.resolveCommit(strings.TrimPrefix(head, "ref: "+branchPrefix))
+		}
+		sha = head
+	case isSHA(ref):
+		sha = ref
+	default:
+		refs, err := obj.readTagRefs()
+		if err != nil {
+			return "", err
+		}
+		if r, ok := refs[ref]; ok {
+			if c := obj.loadTag(ref, r).commit; c != "" {
+				return c, nil
+			}
+			sha = r.object
+			break
+		}
+		if sha, err = obj.readBranchRef(ref); err != nil {
+			return "", err
+		}
+	}
+	if sha == "" {
+		return "", fmt.Errorf("ref %q: %w", ref, lightweigit.ErrNotFound)
+	}
+
+	// Peel tag objects down to the commit.
+	for depth := 0; depth < 4; depth++ {
+		typ, body, err := obj.readObject(sha)
+		if err != nil {
+			return "", err
+		}
+		if typ == "commit" {
+			return sha, nil
+		}
+		if typ != "tag" {
+			break
+		}
+		h, _ := parseHeaders(body)
+		sha = h["object"]
+	}
+	return "", fmt.Errorf("ref %q is not a commit: %w", ref, lightweigit.ErrNotFound)
+}
+
+// treeEntry finds name in a tree object: "<mode> <name>\x00<raw sha>"
+// records, the SHA hashLen bytes long.
+func treeEntry(tree []byte, name string, hashLen int) (string, string, bool) {
+	for len(tree) > 0 {
+		sp := bytes.IndexByte(tree, ' ')
+		nul := bytes.IndexByte(tree, 0)
+		if sp < 0 || nul < sp || len(tree) < nul+1+hashLen {
+			return "", "", false
+		}
+		mode, entry := string(tree[:sp]), string(tree[sp+1:nul])
+		sha := hex.EncodeToString(tree[nul+1 : nul+1+hashLen])
+		tree = tree[nul+1+hashLen:]
+
+		if entry == name {
+			return mode, sha, true
+		}
+	}
+	return "", "", false
+}
+
+// //
+
+// File reads path at ref from the object store; the ref is "HEAD", a full
+// commit SHA, a tag or a branch name.
+func (obj *Obj) File(ref, path string) (*lightweigit.FileObj, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty ref")
+	}
+	p, err := lightweigit.CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := obj.resolveCommit(ref)
+	if err != nil {
+		return nil, err
+	}
+	_, body, err := obj.readObject(commit)
+	if err != nil {
+		return nil, err
+	}
+	h, _ := parseHeaders(body)
+	sha := h["tree"]
+
+	parts := strings.Split(p, "/")
+	for i, name := range parts {
+		typ, tree, err := obj.readObject(sha)
+		if err != nil {
+			return nil, err
+		}
+		if typ != "tree" {
+			return nil, fmt.Errorf("%s at %s: %w", p, ref, lightweigit.ErrNotFound)
+		}
+
+		mode, next, ok := treeEntry(tree, name, len(sha)/2)
+		if !ok || (i < len(parts)-1 && mode != "40000") {
+			return nil, fmt.Errorf("%s at %s: %w", p, ref, lightweigit.ErrNotFound)
+		}
+		sha = next
+	}
+
+	typ, content, err := obj.readObject(sha)
+	if err != nil {
+		return nil, err
+	}
+	if typ != "blob" {
+		return nil, fmt.Errorf("%s at %s: not a file: %w", p, ref, lightweigit.ErrNotFound)
+	}
+	if len(content) > lightweigit.MaxFileSize {
+		return nil, fmt.Errorf("%s at %s: over %d bytes: %w", p, ref, lightweigit.MaxFileSize, lightweigit.ErrResponseTooLarge)
+	}
+
+	return &lightweigit.FileObj{
+		Path:    p,
+		Ref:     ref,
+		SHA:     sha,
+		Content: content,
+	}, nil
+}
+
+// File reads path at the commit the tag points at.
+func (tag *TagObj) File(path string) (*lightweigit.FileObj, error) {
+	ref := tag.commit
+	if ref == "" {
+		ref = tag.name
+	}
+	f, err := tag.Provider.File(ref, path)
+	if err != nil {
+		return nil, err
+	}
+	f.Ref = tag.name
+	return f, nil
+}
@@ -0,0 +1,102 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// GitDir walks up from dir to the nearest checkout and returns its git
+// directory. A ".git" file ("gitdir: <path>", as left by worktrees and
+// submodules) is followed.
+func GitDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for d := abs; ; {
+		p := filepath.Join(d, ".git")
+		fi, err := os.Stat(p)
+		switch {
+		case err == nil && fi.IsDir():
+			return p, nil
+		case err == nil:
+			return readGitFile(p)
+		case !errors.Is(err, os.ErrNotExist):
+			return "", err
+		}
+
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", fmt.Errorf("%s: not inside a git checkout", abs)
+		}
+		d = parent
+	}
+}
+
+func readGitFile(p string) (string, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(string(b))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", fmt.Errorf("%s: expected \"gitdir: <path>\"", p)
+	}
+	dir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(p), dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// CommonDir is where a git directory keeps config, refs and objects: a
+// worktree's own directory names it in "commondir"; other git directories
+// are their own common directory.
+func CommonDir(gitDir string) string {
+	b, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(b))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// isGitDir reports whether dir looks like a git directory (bare or not).
+func isGitDir(dir string) bool {
+	for _, p := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, p)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// openDir maps a path to a checkout or bare repository onto its git
+// directory: dir itself when it is one, else the ".git" inside it.
+func openDir(dir string) (string, error) {
+	if isGitDir(dir) {
+		return dir, nil
+	}
+
+	p := filepath.Join(dir, ".git")
+	fi, err := os.Stat(p)
+	if err != nil {
+		return "", fmt.Errorf("%s: not a git repository: %w", dir, lightweigit.ErrNotFound)
+	}
+	if fi.IsDir() {
+		return p, nil
+	}
+	return readGitFile(p)
+}
@@ -0,0 +1,44 @@
+package local
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// defaultDescription is what "git init" writes to the description file.
+const defaultDescription = "Unnamed repository; edit this file 'description' to name the repository."
+
+// RepositoryInfo reads what a repository on disk knows about itself: the
+// description file gitweb uses and the branch HEAD of the main checkout
+// points at. Everything a forge would add stays empty.
+func (obj *Obj) RepositoryInfo() (*lightweigit.RepositoryInfoObj, error) {
+	info := &lightweigit.RepositoryInfoObj{
+		Name: strings.TrimSuffix(filepath.Base(obj.path), ".git"),
+	}
+
+	b, err := os.ReadFile(filepath.Join(obj.common, "description"))
+	switch {
+	case err == nil:
+		if d := strings.TrimSpace(string(b)); d != defaultDescription {
+			info.Description = d
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	b, err = os.ReadFile(filepath.Join(obj.common, "HEAD"))
+	if err != nil {
+		return nil, err
+	}
+	head := strings.TrimSpace(string(b))
+	if strings.HasPrefix(head, "ref: refs/heads/") {
+		info.DefaultBranch = strings.TrimPrefix(head, "ref: refs/heads/")
+	}
+	return info, nil
+}
@@ -0,0 +1,368 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// maxObjectSize caps what is inflated for one object; tags and commits are
+// tiny, so anything larger is not worth reading here.
+const maxObjectSize = 16 << 20
+
+// maxDeltaDepth bounds delta chains in pack files.
+const maxDeltaDepth = 64
+
+var packTypes = map[byte]string{1: "commit", 2: "tree", 3: "blob", 4: "tag"}
+
+// readObject returns the type and content of an object, from a loose file
+// or from any pack.
+func (obj *Obj) readObject(sha string) (string, []byte, error) {
+	return obj.readObjectDepth(sha, 0)
+}
+
+// readObjectDepth is readObject for the base of a delta depth levels
+// down, so that maxDeltaDepth bounds chains that cross packs too.
+func (obj *Obj) readObjectDepth(sha string, depth int) (string, []byte, error) {
+	typ, body, err := readLooseObject(obj.common, sha)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return typ, body, err
+	}
+
+	want, err := hex.DecodeString(sha)
+	if err != nil {
+		return "", nil, err
+	}
+	packs, err := filepath.Glob(filepath.Join(obj.common, "objects", "pack", "*.idx"))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, idx := range packs {
+		ix, err := obj.index(idx, len(want))
+		if err != nil {
+			return "", nil, err
+		}
+		off, ok, err := ix.find(want)
+		if err != nil {
+			return "", nil, err
+		}
+		if ok {
+			return obj.readPack(strings.TrimSuffix(idx, ".idx")+".pack", ix, off, depth)
+		}
+	}
+	return "", nil, fmt.Errorf("object %s: %w", sha, lightweigit.ErrNotFound)
+}
+
+// readLooseObject inflates objects/xx/yyyy: "<type> <size>\x00<content>".
+func readLooseObject(common, sha string) (string, []byte, error) {
+	f, err := os.Open(filepath.Join(common, "objects", sha[:2], sha[2:]))
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("object %s: %w", sha, err)
+	}
+	defer zr.Close()
+
+	b, err := io.ReadAll(io.LimitReader(zr, maxObjectSize+64))
+	if err != nil {
+		return "", nil, fmt.Errorf("object %s: %w", sha, err)
+	}
+	head, body, ok := bytes.Cut(b, []byte{0})
+	if !ok {
+		return "", nil, fmt.Errorf("object %s: missing header", sha)
+	}
+	typ, size, _ := strings.Cut(string(head), " ")
+	if n, err := strconv.Atoi(size); err != nil || n != len(body) {
+		return "", nil, fmt.Errorf("object %s: size mismatch", sha)
+	}
+	return typ, body, nil
+}
+
+// // // //
+
+// index returns the parsed index at idx, reading the file on first use
+// only. hashLen is the size of an object name in bytes: 20 for SHA-1, 32
+// for SHA-256 repositories.
+func (obj *Obj) index(idx string, hashLen int) (*packIndexObj, error) {
+	obj.mu.Lock()
+	defer obj.mu.Unlock()
+	if ix, ok := obj.indexes[idx]; ok && ix.hashLen == hashLen {
+		return ix, nil
+	}
+
+	ix, err := readIndex(idx, hashLen)
+	if err != nil {
+		return nil, err
+	}
+	if obj.indexes == nil {
+		obj.indexes = make(map[string]*packIndexObj)
+	}
+	obj.indexes[idx] = ix
+	return ix, nil
+}
+
+// readIndex parses a version 2 pack index.
+func readIndex(idx string, hashLen int) (*packIndexObj, error) {
+	b, err := os.ReadFile(idx)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 8+256*4 || !bytes.Equal(b[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(b[4:8]) != 2 {
+		return nil, fmt.Errorf("%s: unsupported pack index", idx)
+	}
+	fanout := b[8 : 8+256*4]
+	n := int(binary.BigEndian.Uint32(fanout[255*4:]))
+	names := 8 + 256*4
+	crcs := names + n*hashLen
+	offs := crcs + n*4
+	large := offs + n*4
+	if len(b) < large {
+		return nil, fmt.Errorf("%s: truncated pack index", idx)
+	}
+
+	return &packIndexObj{
+		path:    idx,
+		hashLen: hashLen,
+		fanout:  fanout,
+		names:   b[names:crcs],
+		offs:    b[offs:large],
+		large:   b[large:],
+	}, nil
+}
+
+// find looks want up and returns the object's offset in the pack.
+func (ix *packIndexObj) find(want []byte) (int64, bool, error) {
+	if len(want) != ix.hashLen {
+		return 0, false, nil
+	}
+	name := func(i int) []byte {
+		return ix.names[i*ix.hashLen : (i+1)*ix.hashLen]
+	}
+
+	lo := 0
+	if want[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(ix.fanout[(int(want[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(ix.fanout[int(want[0])*4:]))
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(name(lo+i), want) >= 0
+	})
+	if i >= hi || !bytes.Equal(name(i), want) {
+		return 0, false, nil
+	}
+
+	off := binary.BigEndian.Uint32(ix.offs[i*4:])
+	if off&0x80000000 == 0 {
+		return int64(off), true, nil
+	}
+	j := int(off&0x7fffffff) * 8
+	if len(ix.large) < j+8 {
+		return 0, false, fmt.Errorf("%s: truncated pack index", ix.path)
+	}
+	return int64(binary.BigEndian.Uint64(ix.large[j:])), true, nil
+}
+
+// readPack opens pack once for the object at off and the whole delta
+// chain under it; depth is how deep in a chain the object already is.
+func (obj *Obj) readPack(pack string, ix *packIndexObj, off int64, depth int) (string, []byte, error) {
+	f, err := os.Open(pack)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	return obj.readPacked(f, ix, off, depth)
+}
+
+// readPacked reads the object at off in the open pack f, resolving offset
+// and ref deltas against their bases. A ref delta's base is looked up in
+// the same pack first, through its index ix.
+func (obj *Obj) readPacked(f *os.File, ix *packIndexObj, off int64, depth int) (string, []byte, error) {
+	pack := f.Name()
+	if depth > maxDeltaDepth {
+		return "", nil, fmt.Errorf("%s@%d: delta chain too deep", pack, off)
+	}
+	if off < 0 {
+		return "", nil, fmt.Errorf("%s@%d: bad object offset", pack, off)
+	}
+
+	// Each level reads through its own section, as the bases are read from
+	// the same file before the delta itself is inflated. bufio gives zlib
+	// the io.ByteReader it needs to stop exactly at the end of the
+	// object's stream.
+	r := bufio.NewReader(io.NewSectionReader(f, off, math.MaxInt64-off))
+
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+	kind := (c >> 4) & 7
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var (
+		baseType string
+		base     []byte
+	)
+	switch kind {
+	case 6: // OFS_DELTA: base at a negative, varint-encoded distance
+		c, err = r.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+		dist := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return "", nil, err
+			}
+			dist = (dist+1)<<7 | int64(c&0x7f)
+		}
+		baseType, base, err = obj.readPacked(f, ix, off-dist, depth+1)
+	case 7: // REF_DELTA: base named by its hash
+		h := make([]byte, ix.hashLen)
+		if _, err = io.ReadFull(r, h); err != nil {
+			return "", nil, err
+		}
+		var at int64
+		var ok bool
+		if at, ok, err = ix.find(h); err == nil && ok {
+			baseType, base, err = obj.readPacked(f, ix, at, depth+1)
+		} else if err == nil {
+			baseType, base, err = obj.readObjectDepth(hex.EncodeToString(h), depth+1)
+		}
+	default:
+		typ, ok := packTypes[kind]
+		if !ok {
+			return "", nil, fmt.Errorf("%s@%d: bad object type %d", pack, off, kind)
+		}
+		body, err := inflate(r)
+		return typ, body, err
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	delta, err := inflate(r)
+	if err != nil {
+		return "", nil, err
+	}
+	body, err := applyDelta(base, delta)
+	return baseType, body, err
+}
+
+// inflate reads one zlib stream; one over maxObjectSize is refused
+// rather than cut short.
+func inflate(r io.Reader) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	b, err := io.ReadAll(io.LimitReader(zr, maxObjectSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxObjectSize {
+		return nil, fmt.Errorf("object over %d bytes: %w", maxObjectSize, lightweigit.ErrResponseTooLarge)
+	}
+	return b, nil
+}
+
+// applyDelta rebuilds an object from its base and a git delta: two size
+// varints, then copy-from-base and insert instructions.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	errBad := errors.New("corrupt delta")
+
+	varint := func() (int, bool) {
+		n, shift := 0, 0
+		for len(delta) > 0 {
+			c := delta[0]
+			delta = delta[1:]
+			n |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return n, true
+			}
+		}
+		return 0, false
+	}
+
+	srcSize, ok := varint()
+	if !ok || srcSize != len(base) {
+		return nil, errBad
+	}
+	dstSize, ok := varint()
+	if !ok || dstSize > maxObjectSize {
+		return nil, errBad
+	}
+
+	out := make([]byte, 0, dstSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		if op&0x80 == 0 {
+			n := int(op)
+			if n == 0 || n > len(delta) {
+				return nil, errBad
+			}
+			out = append(out, delta[:n]...)
+			delta = delta[n:]
+			continue
+		}
+
+		var off, size int
+		for i := 0; i < 4; i++ {
+			if op&(1<<i) != 0 {
+				if len(delta) == 0 {
+					return nil, errBad
+				}
+				off |= int(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		for i := 0; i < 3; i++ {
+			if op&(0x10<<i) != 0 {
+				if len(delta) == 0 {
+					return nil, errBad
+				}
+				size |= int(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		if size == 0 {
+			size = 0x10000
+		}
+		if off+size > len(base) {
+			return nil, errBad
+		}
+		out = append(out, base[off:off+size]...)
+	}
+
+	if len(out) != dstSize {
+		return nil, errBad
+	}
+	return out, nil
+}
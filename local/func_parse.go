@@ -0,0 +1,50 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+// Parse opens the repository named by a file:// URL: a checkout, a
+// worktree or a bare repository. Only the local disk is read.
+func Parse(raw string) (*Obj, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return nil, errors.New("an empty URL string")
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("URL could not be parsed: %w", err)
+	}
+	if u.Scheme != "file" {
+		return nil, fmt.Errorf("not a file URL: %q", raw)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("file URL on a remote host: %q", u.Host)
+	}
+	if u.Path == "" {
+		return nil, fmt.Errorf("file URL without a path: %q", raw)
+	}
+
+	p, err := filepath.Abs(filepath.FromSlash(u.Path))
+	if err != nil {
+		return nil, err
+	}
+	gitDir, err := openDir(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Obj{path: p, common: CommonDir(gitDir)}, nil
+}
+
+// ParseOffline is Parse: this provider never needs the network.
+func ParseOffline(raw string) (*Obj, error) {
+	return Parse(raw)
+}
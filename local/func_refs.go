@@ -0,0 +1,149 @@
+package local
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+const (
+	tagPrefix    = "refs/tags/"
+	branchPrefix = "refs/heads/"
+)
+
+// readTagRefs collects the tags of the repository: packed-refs first, then
+// loose refs under refs/tags, which take precedence as in git.
+func (obj *Obj) readTagRefs() (map[string]refObj, error) {
+	refs := make(map[string]refObj)
+	if err := readPackedRefs(filepath.Join(obj.common, "packed-refs"), tagPrefix, refs); err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(obj.common, "refs", "tags")
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		sha, err := readLooseRef(obj.common, p, 0)
+		if err != nil {
+			return err
+		}
+		if sha != "" {
+			refs[filepath.ToSlash(rel)] = refObj{object: sha}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// readPackedRefs parses packed-refs: "<sha> <ref>" lines, each optionally
+// followed by "^<sha>" with the commit an annotated tag peels to. Only refs
+// under prefix are kept, keyed without it.
+func readPackedRefs(p, prefix string, refs map[string]refObj) error {
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	last := ""
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "", line[0] == '#':
+			continue
+		case line[0] == '^':
+			if r, ok := refs[last]; ok {
+				r.peeled = line[1:]
+				refs[last] = r
+			}
+			continue
+		}
+
+		last = ""
+		sha, name, ok := strings.Cut(line, " ")
+		if !ok || !strings.HasPrefix(name, prefix) || !isSHA(sha) {
+			continue
+		}
+		last = strings.TrimPrefix(name, prefix)
+		refs[last] = refObj{object: sha}
+	}
+	return sc.Err()
+}
+
+// readBranchRef resolves a branch name to its head: the loose ref, else
+// packed-refs. "" means there is no such branch.
+func (obj *Obj) readBranchRef(name string) (string, error) {
+	sha, err := readLooseRef(obj.common, filepath.Join(obj.common, filepath.FromSlash(branchPrefix+name)), 0)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return sha, err
+	}
+
+	refs := make(map[string]refObj)
+	if err := readPackedRefs(filepath.Join(obj.common, "packed-refs"), branchPrefix, refs); err != nil {
+		return "", err
+	}
+	return refs[name].object, nil
+}
+
+// readLooseRef reads a loose ref file, following "ref: " symbolic refs a
+// few levels deep. An unresolvable symbolic ref gives "".
+func readLooseRef(common, p string, depth int) (string, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+
+	s := strings.TrimSpace(string(b))
+	if strings.HasPrefix(s, "ref: ") {
+		target := strings.TrimSpace(s[len("ref: "):])
+		if depth > 4 {
+			return "", nil
+		}
+		sha, err := readLooseRef(common, filepath.Join(common, filepath.FromSlash(target)), depth+1)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return sha, err
+	}
+	if !isSHA(s) {
+		return "", nil
+	}
+	return s, nil
+}
+
+// isSHA accepts SHA-1 and SHA-256 object names.
+func isSHA(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
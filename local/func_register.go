@@ -0,0 +1,24 @@
+package local
+
+import (
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func init() {
+	lightweigit.RegisterBuiltin(lightweigit.ProviderEntryObj{
+		Name:     "local",
+		Priority: 200,
+
+		// file:// URLs have no host (or "localhost"); tried first so that
+		// no forge provider sends a request for them.
+		Match: func(host string) bool {
+			return host == "" || host == "localhost"
+		},
+
+		TagMod:       target.ModLocalTag,
+		UnmarshalTag: UnmarshalTag,
+	}, Parse, ParseOffline, nil)
+}
@@ -0,0 +1,25 @@
+package local
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// Releases are a hosting feature; a repository on disk has none. The
+// methods exist so that Obj satisfies lightweigit.ProviderInterface.
+
+func (obj *Obj) ReleaseLatest() (lightweigit.ProviderReleaseInterface, error) {
+	return nil, fmt.Errorf("local repository has no releases: %w", lightweigit.ErrNotFound)
+}
+
+func (obj *Obj) ReleaseFind(findRelease string) (lightweigit.ProviderReleaseInterface, error) {
+	return nil, fmt.Errorf("local repository has no releases: %w", lightweigit.ErrNotFound)
+}
+
+func (obj *Obj) ReleasesStream(ctx context.Context, out chan lightweigit.ProviderReleaseInterface, limit int) error {
+	return nil
+}
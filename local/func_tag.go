@@ -0,0 +1,266 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func (tag *TagObj) Mod() target.ModType {
+	return target.ModLocalTag
+}
+
+func (tag *TagObj) String() string {
+	return tag.name
+}
+
+// URL is the repository's file URL with the tag ref as fragment.
+func (tag *TagObj) URL() *url.URL {
+	u := tag.Provider.URL()
+	u.Fragment = tagPrefix + tag.name
+	return u
+}
+
+// ZIP is nil: there is no archive service for a local repository.
+func (tag *TagObj) ZIP() *url.URL {
+	return nil
+}
+
+// TAR is nil, like ZIP.
+func (tag *TagObj) TAR() *url.URL {
+	return nil
+}
+
+// //
+
+// Object is the object the tag ref points at: the tag object of an
+// annotated tag, the commit of a lightweight one.
+func (tag *TagObj) Object() string {
+	return tag.object
+}
+
+// Commit is the commit the tag peels to; "" when it could not be read.
+func (tag *TagObj) Commit() string {
+	return tag.commit
+}
+
+func (tag *TagObj) Annotated() bool {
+	return tag.annotated
+}
+
+// Tagger is "Name <email>" of an annotated tag.
+func (tag *TagObj) Tagger() string {
+	return tag.tagger
+}
+
+// Message is the message of an annotated tag, signature included.
+func (tag *TagObj) Message() string {
+	return tag.message
+}
+
+// Time is the tagger date of an annotated tag and the committer date of
+// the commit otherwise; zero when neither could be read.
+func (tag *TagObj) Time() time.Time {
+	return tag.date
+}
+
+// // // //
+
+// parseSignature splits "Name <email> 1700000000 +0100" into the identity
+// and the time.
+func parseSignature(s string) (string, time.Time) {
+	end := strings.LastIndex(s, ">")
+	if end < 0 {
+		return s, time.Time{}
+	}
+	who, when := s[:end+1], strings.Fields(s[end+1:])
+	if len(when) != 2 {
+		return who, time.Time{}
+	}
+
+	sec, err := strconv.ParseInt(when[0], 10, 64)
+	if err != nil {
+		return who, time.Time{}
+	}
+	t := time.Unix(sec, 0).UTC()
+	if tz := when[1]; len(tz) == 5 && (tz[0] == '+' || tz[0] == '-') {
+		h, err1 := strconv.Atoi(tz[1:3])
+		m, err2 := strconv.Atoi(tz[3:5])
+		if err1 == nil && err2 == nil {
+			off := (h*60 + m) * 60
+			if tz[0] == '-' {
+				off = -off
+			}
+			t = t.In(time.FixedZone(tz, off))
+		}
+	}
+	return who, t
+}
+
+// parseHeaders splits a commit or tag object into its header lines and
+// the message after the first blank line.
+func parseHeaders(body []byte) (map[string]string, string) {
+	head, msg, _ := bytes.Cut(body, []byte("\n\n"))
+	h := make(map[string]string)
+	for _, line := range strings.Split(string(head), "\n") {
+		k, v, ok := strings.Cut(line, " ")
+		if !ok || strings.HasPrefix(line, " ") {
+			continue
+		}
+		if _, dup := h[k]; !dup {
+			h[k] = v
+		}
+	}
+	return h, string(msg)
+}
+
+// loadTag fills a tag from its ref, reading the tag object (if any) and
+// the commit for the date. Unreadable objects leave fields empty rather
+// than failing the whole listing.
+func (obj *Obj) loadTag(name string, ref refObj) *TagObj {
+	tag := &TagObj{
+		Provider: obj,
+		name:     name,
+		object:   ref.object,
+		commit:   ref.peeled,
+	}
+
+	sha := ref.object
+	for depth := 0; depth < 4; depth++ {
+		typ, body, err := obj.readObject(sha)
+		if err != nil {
+			return tag
+		}
+
+		switch typ {
+		case "tag":
+			h, msg := parseHeaders(body)
+			if depth == 0 {
+				tag.annotated = true
+				tag.message = msg
+				tag.tagger, tag.date = parseSignature(h["tagger"])
+			}
+			sha = h["object"]
+			if h["type"] != "tag" && h["type"] != "commit" {
+				return tag
+			}
+			continue
+		case "commit":
+			tag.commit = sha
+			if tag.date.IsZero() {
+				h, _ := parseHeaders(body)
+				_, tag.date = parseSignature(h["committer"])
+			}
+		}
+		return tag
+	}
+	return tag
+}
+
+// naturalLess orders names with digit runs compared as numbers, so that
+// v1.10 sorts after v1.9.
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := digitPrefix(a), digitPrefix(b)
+		if da != "" && db != "" {
+			na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func digitPrefix(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// tags reads and orders every tag: newest first by Time, tags without a
+// date last, ties by version-aware name descending.
+func (obj *Obj) tags() ([]*TagObj, error) {
+	refs, err := obj.readTagRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*TagObj, 0, len(refs))
+	for name, ref := range refs {
+		list = append(list, obj.loadTag(name, ref))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if !a.date.Equal(b.date) {
+			if a.date.IsZero() || b.date.IsZero() {
+				return b.date.IsZero()
+			}
+			return a.date.After(b.date)
+		}
+		return naturalLess(b.name, a.name)
+	})
+	return list, nil
+}
+
+// //
+
+func (obj *Obj) TagLatest() (lightweigit.ProviderTagInterface, error) {
+	list, err := obj.tags()
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, lightweigit.ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (obj *Obj) TagFind(findTag string) (lightweigit.ProviderTagInterface, error) {
+	refs, err := obj.readTagRefs()
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := refs[findTag]
+	if !ok {
+		return nil, fmt.Errorf("tag %q: %w", findTag, lightweigit.ErrNotFound)
+	}
+	return obj.loadTag(findTag, ref), nil
+}
+
+func (obj *Obj) TagsStream(ctx context.Context, out chan lightweigit.ProviderTagInterface, limit int) error {
+	list, err := obj.tags()
+	if err != nil {
+		return err
+	}
+	for i, tag := range list {
+		if limit > 0 && i >= limit {
+			return nil
+		}
+		if err := lightweigit.Send[lightweigit.ProviderTagInterface](ctx, out, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package local
+
+import (
+	"sync"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+// Obj is a repository on disk: a checkout, a worktree or a bare mirror.
+// path is what was opened, common the git directory holding refs and
+// objects.
+type Obj struct {
+	path   string
+	common string
+
+	// mu guards indexes, the pack indexes parsed so far, by path. Pack
+	// names are content hashes, so a cached index never goes stale.
+	mu      sync.Mutex
+	indexes map[string]*packIndexObj
+}
+
+// packIndexObj is a version 2 pack index read once: the fanout table and
+// the name, offset and large offset tables, sliced out of the file.
+// hashLen is the size of an object name in bytes.
+type packIndexObj struct {
+	path    string
+	hashLen int
+	fanout  []byte
+	names   []byte
+	offs    []byte
+	large   []byte
+}
+
+// TagObj is a tag read from refs/tags or packed-refs. object is what the
+// ref points at; for annotated tags that is the tag object and commit the
+// commit it peels to.
+type TagObj struct {
+	Provider  *Obj
+	name      string
+	object    string
+	commit    string
+	annotated bool
+	tagger    string
+	message   string
+	date      time.Time
+}
+
+// refObj is one tag ref: its target and, from packed-refs "^" lines or a
+// peeled tag object, the commit underneath.
+type refObj struct {
+	object string
+	peeled string
+}
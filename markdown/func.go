@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// NewLinker links references to p, relative paths to the files at ref,
+// usually the release's tag. Providers without ProviderLinksInterface
+// only get root-relative paths resolved.
+func NewLinker(p lightweigit.ProviderInterface, ref string) *LinkerObj {
+	l := &LinkerObj{ref: ref}
+	if p == nil {
+		return l
+	}
+
+	l.links, _ = p.(lightweigit.ProviderLinksInterface)
+	if u := p.URL(); u != nil && (u.Scheme == "http" || u.Scheme == "https") {
+		l.base = &url.URL{Scheme: u.Scheme, Host: u.Host}
+	}
+	return l
+}
+
+func (l *LinkerObj) hasLinks() bool {
+	return l != nil && l.links != nil
+}
+
+// resolve makes a link target safe and absolute where it can. Only http,
+// https and mailto URLs and relative paths pass; anything else yields "".
+func (l *LinkerObj) resolve(raw string) string {
+	raw = strings.TrimSpace(html.UnescapeString(raw))
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return u.String()
+	case "":
+	default:
+		return ""
+	}
+
+	switch {
+	case u.Host != "":
+		u.Scheme = "https"
+		return u.String()
+	case u.Path == "" || l == nil:
+		return u.String()
+	case strings.HasPrefix(u.Path, "/"):
+		if l.base == nil {
+			return u.String()
+		}
+		return l.base.ResolveReference(u).String()
+	case l.links == nil || l.ref == "":
+		return u.String()
+	}
+
+	f, err := url.Parse(l.links.FileURL(l.ref, path.Clean("/" + u.Path)[1:]))
+	if err != nil {
+		return ""
+	}
+	f.RawQuery, f.Fragment = u.RawQuery, u.Fragment
+	return f.String()
+}
+
+// //
+
+// HTML renders md as sanitized HTML: raw markup other than a few
+// attribute-less tags (details, summary, br, b, i, em, strong, code, kbd,
+// sub, sup) is escaped, and links and images only keep http, https,
+// mailto and relative targets. Links get rel="nofollow noopener
+// noreferrer". l may be nil.
+func HTML(md string, l *LinkerObj) string {
+	h := &htmlObj{doc: parse(md, l)}
+	h.blocks(h.doc.blocks, false)
+	h.closeTo(0, false)
+	return h.b.String()
+}
+
+// Text renders md as plain text for terminals: headings underlined, list
+// markers normalized, code indented, link targets in parentheses after
+// their text. l may be nil.
+func Text(md string, l *LinkerObj) string {
+	t := &textObj{doc: parse(md, l)}
+	out := t.blocks(t.doc.blocks, false)
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+// ReleaseHTML renders the body of rel, a release of p, as HTML with
+// links resolved at its tag.
+func ReleaseHTML(p lightweigit.ProviderInterface, rel lightweigit.ProviderReleaseInterface) string {
+	return HTML(rel.BodyMD(), releaseLinker(p, rel))
+}
+
+// ReleaseText renders the body of rel, a release of p, as plain text.
+func ReleaseText(p lightweigit.ProviderInterface, rel lightweigit.ProviderReleaseInterface) string {
+	return Text(rel.BodyMD(), releaseLinker(p, rel))
+}
+
+func releaseLinker(p lightweigit.ProviderInterface, rel lightweigit.ProviderReleaseInterface) *LinkerObj {
+	ref := ""
+	if tag := rel.Tag(); tag != nil {
+		ref = tag.String()
+	}
+	return NewLinker(p, ref)
+}
@@ -0,0 +1,412 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+var (
+	fenceRe    = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})(.*)$")
+	headingRe  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?[ \t]*$`)
+	closingRe  = regexp.MustCompile(`(?:^|[ \t]+)#+$`)
+	ruleRe     = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	setextRe   = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	quoteRe    = regexp.MustCompile(`^ {0,3}> ?`)
+	itemRe     = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])( +|$)`)
+	delimRe    = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	refDefRe   = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^ \t<>]+)>?(?:[ \t]+(?:"([^"]*)"|'([^']*)'|\(([^)]*)\)))?[ \t]*$`)
+	htmlLineRe = regexp.MustCompile(`^ {0,3}</?(?:details|summary)[ \t]*>`)
+	commentRe  = regexp.MustCompile(`^ {0,3}<!--`)
+)
+
+// parse splits md into blocks, collecting the link definitions.
+func parse(md string, l *LinkerObj) *docObj {
+	md = strings.ReplaceAll(md, "\r\n", "\n")
+	md = strings.ReplaceAll(md, "\r", "\n")
+
+	lines := strings.Split(md, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+
+	d := &docObj{refs: make(map[string]refObj), linker: l}
+	d.blocks = d.parseBlocks(lines)
+	return d
+}
+
+// parseBlocks parses lines into blocks; containers parse their own lines
+// through it again.
+func (d *docObj) parseBlocks(lines []string) []*blockObj {
+	var out []*blockObj
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		var b *blockObj
+		n := 1
+		switch {
+		case isBlank(line):
+			i++
+			continue
+
+		case commentRe.MatchString(line):
+			for n = 1; !strings.Contains(lines[i+n-1], "-->") && i+n < len(lines); n++ {
+			}
+			i += n
+			continue
+
+		case isFenceOpen(line):
+			b, n = parseFence(lines[i:])
+
+		case indent(line) >= 4:
+			b, n = parseIndented(lines[i:])
+
+		case headingRe.MatchString(line):
+			m := headingRe.FindStringSubmatch(line)
+			b = &blockObj{typ: blockHeading, level: len(m[1]), text: strings.TrimSpace(closingRe.ReplaceAllString(m[2], ""))}
+
+		case ruleRe.MatchString(line):
+			b = &blockObj{typ: blockRule}
+
+		case quoteRe.MatchString(line):
+			b, n = d.parseQuote(lines[i:])
+
+		case itemRe.MatchString(line):
+			b, n = d.parseList(lines[i:])
+
+		case htmlLineRe.MatchString(line):
+			b = &blockObj{typ: blockHTML, text: strings.TrimSpace(line)}
+
+		case d.refDef(line):
+			i++
+			continue
+
+		case i+1 < len(lines) && isTableStart(line, lines[i+1]):
+			b, n = parseTable(lines[i:])
+
+		default:
+			b, n = parseParagraph(lines[i:])
+		}
+
+		b.first, b.last = i, i+n-1
+		for b.last > b.first && isBlank(lines[b.last]) {
+			b.last--
+		}
+		out = append(out, b)
+		i += n
+	}
+	return out
+}
+
+// startsBlock reports whether line begins a block that ends a paragraph.
+// Lists only do so when they have content and, if ordered, start at 1.
+func startsBlock(line string) bool {
+	if isFenceOpen(line) || headingRe.MatchString(line) || ruleRe.MatchString(line) ||
+		quoteRe.MatchString(line) || htmlLineRe.MatchString(line) || commentRe.MatchString(line) {
+		return true
+	}
+	if m := itemRe.FindStringSubmatch(line); m != nil && len(line) > len(m[0]) {
+		return !isDigit(m[2][0]) || m[2][:len(m[2])-1] == "1"
+	}
+	return false
+}
+
+// //
+
+func parseFence(lines []string) (*blockObj, int) {
+	m := fenceRe.FindStringSubmatch(lines[0])
+	pad, fence := len(m[1]), m[2]
+
+	b := &blockObj{typ: blockCode}
+	if f := strings.Fields(m[3]); len(f) > 0 {
+		b.lang = f[0]
+	}
+
+	var body []string
+	n := 1
+	for ; n < len(lines); n++ {
+		t := strings.TrimSpace(lines[n])
+		if indent(lines[n]) < 4 && len(t) >= len(fence) && strings.Trim(t, fence[:1]) == "" {
+			n++
+			break
+		}
+		body = append(body, trimIndent(lines[n], pad))
+	}
+	if len(body) > 0 {
+		b.text = strings.Join(body, "\n") + "\n"
+	}
+	return b, n
+}
+
+func parseIndented(lines []string) (*blockObj, int) {
+	var body []string
+	n := 0
+	for ; n < len(lines); n++ {
+		if !isBlank(lines[n]) && indent(lines[n]) < 4 {
+			break
+		}
+		body = append(body, trimIndent(lines[n], 4))
+	}
+	for len(body) > 0 && isBlank(body[len(body)-1]) {
+		body = body[:len(body)-1]
+	}
+	return &blockObj{typ: blockCode, text: strings.Join(body, "\n") + "\n"}, n
+}
+
+// parseQuote takes the lines marked with ">" and the lazy paragraph
+// lines that continue them.
+func (d *docObj) parseQuote(lines []string) (*blockObj, int) {
+	var inner []string
+	n := 0
+	for ; n < len(lines); n++ {
+		line := lines[n]
+		if m := quoteRe.FindString(line); m != "" {
+			inner = append(inner, line[len(m):])
+			continue
+		}
+		if isBlank(line) || isBlank(inner[len(inner)-1]) || startsBlock(line) {
+			break
+		}
+		inner = append(inner, line)
+	}
+	return &blockObj{typ: blockQuote, children: d.parseBlocks(inner)}, n
+}
+
+// parseList takes consecutive items of one kind: the same bullet, or
+// ordered with the same delimiter. An item runs on while lines are indented
+// past its marker, plus lazy paragraph lines.
+func (d *docObj) parseList(lines []string) (*blockObj, int) {
+	list := &blockObj{typ: blockList}
+	kind := ""
+
+	n := 0
+	for n < len(lines) {
+		m := itemRe.FindStringSubmatch(lines[n])
+		if m == nil || ruleRe.MatchString(lines[n]) {
+			break
+		}
+
+		marker := m[2]
+		k := itemKind(marker)
+		if kind == "" {
+			kind = k
+			if isDigit(marker[0]) {
+				list.ordered = true
+				list.start, _ = strconv.Atoi(marker[:len(marker)-1])
+			}
+		} else if k != kind {
+			break
+		}
+
+		width := len(m[1]) + len(marker) + len(m[3])
+		if len(m[3]) == 0 || len(m[3]) > 4 {
+			width = len(m[1]) + len(marker) + 1
+		}
+		first := ""
+		if len(lines[n]) > width {
+			first = lines[n][width:]
+		}
+
+		body := []string{first}
+		j := n + 1
+		for ; j < len(lines); j++ {
+			line := lines[j]
+			switch {
+			case isBlank(line):
+				body = append(body, "")
+				continue
+			case indent(line) >= width:
+				body = append(body, line[width:])
+				continue
+			case !isBlank(body[len(body)-1]) && !startsBlock(line) && !itemRe.MatchString(line):
+				body = append(body, strings.TrimLeft(line, " "))
+				continue
+			}
+			break
+		}
+
+		trailing := 0
+		for len(body) > 1 && isBlank(body[len(body)-1]) {
+			body = body[:len(body)-1]
+			trailing++
+		}
+
+		item := &blockObj{typ: blockItem, children: d.parseBlocks(body)}
+		for c := 1; c < len(item.children); c++ {
+			if item.children[c].first > item.children[c-1].last+1 {
+				list.loose = true
+			}
+		}
+		list.children = append(list.children, item)
+
+		n = j
+		if trailing > 0 && n < len(lines) {
+			if m := itemRe.FindStringSubmatch(lines[n]); m != nil && !ruleRe.MatchString(lines[n]) && itemKind(m[2]) == kind {
+				list.loose = true
+			}
+		}
+	}
+	return list, n
+}
+
+// itemKind is what items of one list share: the bullet, or the delimiter
+// after the number.
+func itemKind(marker string) string {
+	if isDigit(marker[0]) {
+		return marker[len(marker)-1:]
+	}
+	return marker
+}
+
+// //
+
+func isTableStart(head, delim string) bool {
+	if !strings.Contains(head, "|") || !delimRe.MatchString(delim) {
+		return false
+	}
+	return len(splitRow(head)) == len(splitRow(delim))
+}
+
+func parseTable(lines []string) (*blockObj, int) {
+	b := &blockObj{typ: blockTable}
+	for _, cell := range splitRow(lines[1]) {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			b.align = append(b.align, alignCenter)
+		case left:
+			b.align = append(b.align, alignLeft)
+		case right:
+			b.align = append(b.align, alignRight)
+		default:
+			b.align = append(b.align, alignNone)
+		}
+	}
+
+	b.rows = append(b.rows, splitRow(lines[0]))
+	n := 2
+	for ; n < len(lines) && !isBlank(lines[n]) && !startsBlock(lines[n]); n++ {
+		row := splitRow(lines[n])
+		for len(row) < len(b.align) {
+			row = append(row, "")
+		}
+		b.rows = append(b.rows, row[:len(b.align)])
+	}
+	return b, n
+}
+
+// splitRow splits a table row on the pipes that are not escaped.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// //
+
+// parseParagraph takes lines up to a blank line or another block; an
+// underline of "=" or "-" makes the paragraph a heading.
+func parseParagraph(lines []string) (*blockObj, int) {
+	text := []string{strings.TrimLeft(lines[0], " ")}
+	n := 1
+	for ; n < len(lines) && !isBlank(lines[n]); n++ {
+		if m := setextRe.FindStringSubmatch(lines[n]); m != nil {
+			level := 2
+			if m[1][0] == '=' {
+				level = 1
+			}
+			return &blockObj{typ: blockHeading, level: level, text: strings.TrimSpace(strings.Join(text, "\n"))}, n + 1
+		}
+		if startsBlock(lines[n]) {
+			break
+		}
+		text = append(text, strings.TrimLeft(lines[n], " "))
+	}
+	return &blockObj{typ: blockParagraph, text: strings.TrimRight(strings.Join(text, "\n"), " ")}, n
+}
+
+// refDef records line if it is a link reference definition. The first
+// definition of a label wins.
+func (d *docObj) refDef(line string) bool {
+	m := refDefRe.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+	label := refLabel(m[1])
+	if _, ok := d.refs[label]; !ok {
+		d.refs[label] = refObj{href: unescape(m[2]), title: m[3] + m[4] + m[5]}
+	}
+	return true
+}
+
+// //
+
+func refLabel(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func isFenceOpen(line string) bool {
+	m := fenceRe.FindStringSubmatch(line)
+	return m != nil && !(m[2][0] == '`' && strings.Contains(m[3], "`"))
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimIndent removes up to n leading spaces.
+func trimIndent(line string, n int) string {
+	for i := 0; i < n && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+// expandTabs turns the tabs in the indentation of line into spaces, to
+// the next multiple of four.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			b.WriteByte(' ')
+		case '\t':
+			b.WriteString(strings.Repeat(" ", 4-b.Len()%4))
+		default:
+			return b.String() + line[i:]
+		}
+	}
+	return b.String()
+}
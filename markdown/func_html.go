@@ -0,0 +1,251 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+var langRe = regexp.MustCompile(`^[A-Za-z0-9_+#.-]+$`)
+
+// htmlObj writes HTML. open is the stack of allowed tags the body opened
+// and has not closed yet; they are closed where their container ends, so
+// that the output stays well nested.
+type htmlObj struct {
+	doc  *docObj
+	b    strings.Builder
+	open []string
+}
+
+func (h *htmlObj) blocks(list []*blockObj, tight bool) {
+	for i, b := range list {
+		switch b.typ {
+		case blockParagraph:
+			if tight {
+				h.inline(b.text)
+				if i < len(list)-1 {
+					h.b.WriteString("\n")
+				}
+				continue
+			}
+			h.b.WriteString("<p>")
+			h.inline(b.text)
+			h.b.WriteString("</p>\n")
+
+		case blockHeading:
+			tag := "h" + strconv.Itoa(b.level)
+			h.b.WriteString("<" + tag + ">")
+			h.inline(b.text)
+			h.b.WriteString("</" + tag + ">\n")
+
+		case blockCode:
+			h.b.WriteString("<pre><code")
+			if langRe.MatchString(b.lang) {
+				h.b.WriteString(` class="language-` + html.EscapeString(b.lang) + `"`)
+			}
+			h.b.WriteString(">" + html.EscapeString(b.text) + "</code></pre>\n")
+
+		case blockQuote:
+			h.b.WriteString("<blockquote>\n")
+			depth := len(h.open)
+			h.blocks(b.children, false)
+			h.closeTo(depth, false)
+			h.b.WriteString("</blockquote>\n")
+
+		case blockList:
+			tag := "ul"
+			if b.ordered {
+				tag = "ol"
+			}
+			h.b.WriteString("<" + tag)
+			if b.ordered && b.start != 1 {
+				h.b.WriteString(` start="` + strconv.Itoa(b.start) + `"`)
+			}
+			h.b.WriteString(">\n")
+			for _, item := range b.children {
+				h.b.WriteString("<li>")
+				if b.loose {
+					h.b.WriteString("\n")
+				}
+				depth := len(h.open)
+				h.blocks(item.children, !b.loose)
+				h.closeTo(depth, false)
+				h.b.WriteString("</li>\n")
+			}
+			h.b.WriteString("</" + tag + ">\n")
+
+		case blockRule:
+			h.b.WriteString("<hr>\n")
+
+		case blockTable:
+			h.table(b)
+
+		case blockHTML:
+			h.inline(b.text)
+			h.b.WriteString("\n")
+		}
+	}
+}
+
+func (h *htmlObj) table(b *blockObj) {
+	row := func(cells []string, tag string) {
+		h.b.WriteString("<tr>\n")
+		for i, cell := range cells {
+			h.b.WriteString("<" + tag)
+			switch b.align[i] {
+			case alignLeft:
+				h.b.WriteString(` align="left"`)
+			case alignCenter:
+				h.b.WriteString(` align="center"`)
+			case alignRight:
+				h.b.WriteString(` align="right"`)
+			}
+			h.b.WriteString(">")
+			depth := len(h.open)
+			h.inline(cell)
+			h.closeTo(depth, false)
+			h.b.WriteString("</" + tag + ">\n")
+		}
+		h.b.WriteString("</tr>\n")
+	}
+
+	h.b.WriteString("<table>\n<thead>\n")
+	row(b.rows[0], "th")
+	h.b.WriteString("</thead>\n")
+	if len(b.rows) > 1 {
+		h.b.WriteString("<tbody>\n")
+		for _, cells := range b.rows[1:] {
+			row(cells, "td")
+		}
+		h.b.WriteString("</tbody>\n")
+	}
+	h.b.WriteString("</table>\n")
+}
+
+// //
+
+// inline writes the inline content of a block. Inline tags left open are
+// closed at its end; details may span blocks.
+func (h *htmlObj) inline(src string) {
+	depth := len(h.open)
+	h.nodes(h.doc.inline(src, false))
+	h.closeTo(depth, true)
+}
+
+func (h *htmlObj) nodes(list []inlineObj) {
+	for _, n := range list {
+		switch n.typ {
+		case inlineText:
+			h.b.WriteString(html.EscapeString(n.text))
+
+		case inlineCode:
+			h.b.WriteString("<code>" + html.EscapeString(n.text) + "</code>")
+
+		case inlineEm:
+			h.wrap("em", n.children)
+
+		case inlineStrong:
+			h.wrap("strong", n.children)
+
+		case inlineDel:
+			h.wrap("del", n.children)
+
+		case inlineLink:
+			if n.href == "" {
+				h.nodes(n.children)
+				continue
+			}
+			h.b.WriteString(`<a href="` + html.EscapeString(n.href) + `"`)
+			if n.title != "" {
+				h.b.WriteString(` title="` + html.EscapeString(n.title) + `"`)
+			}
+			h.b.WriteString(` rel="nofollow noopener noreferrer">`)
+			depth := len(h.open)
+			h.nodes(n.children)
+			h.closeTo(depth, false)
+			h.b.WriteString("</a>")
+
+		case inlineImage:
+			alt := plain(n.children)
+			if !strings.HasPrefix(n.href, "https://") && !strings.HasPrefix(n.href, "http://") {
+				h.b.WriteString(html.EscapeString(alt))
+				continue
+			}
+			h.b.WriteString(`<img src="` + html.EscapeString(n.href) + `" alt="` + html.EscapeString(alt) + `"`)
+			if n.title != "" {
+				h.b.WriteString(` title="` + html.EscapeString(n.title) + `"`)
+			}
+			h.b.WriteString(">")
+
+		case inlineBreak:
+			h.b.WriteString("<br>\n")
+
+		case inlineSoftBreak:
+			h.b.WriteString("\n")
+
+		case inlineHTML:
+			h.tag(n)
+		}
+	}
+}
+
+func (h *htmlObj) wrap(tag string, children []inlineObj) {
+	h.b.WriteString("<" + tag + ">")
+	depth := len(h.open)
+	h.nodes(children)
+	h.closeTo(depth, false)
+	h.b.WriteString("</" + tag + ">")
+}
+
+// tag writes an allowed tag. A closing tag closes what was opened after
+// its match; one without a match is dropped.
+func (h *htmlObj) tag(n inlineObj) {
+	switch {
+	case n.text == "br":
+		h.b.WriteString("<br>")
+
+	case !n.closing:
+		h.b.WriteString("<" + n.text + ">")
+		h.open = append(h.open, n.text)
+
+	default:
+		for i := len(h.open) - 1; i >= 0; i-- {
+			if h.open[i] == n.text {
+				h.closeTo(i, false)
+				return
+			}
+		}
+	}
+}
+
+// closeTo closes the open tags above depth. With keepDetails it stops at
+// a details element, which may hold several blocks.
+func (h *htmlObj) closeTo(depth int, keepDetails bool) {
+	for len(h.open) > depth {
+		top := h.open[len(h.open)-1]
+		if keepDetails && top == "details" {
+			return
+		}
+		h.b.WriteString("</" + top + ">")
+		h.open = h.open[:len(h.open)-1]
+	}
+}
+
+// plain is the text of nodes without markup, for image alt text.
+func plain(list []inlineObj) string {
+	var b strings.Builder
+	for _, n := range list {
+		switch n.typ {
+		case inlineText, inlineCode:
+			b.WriteString(n.text)
+		case inlineSoftBreak, inlineBreak:
+			b.WriteString(" ")
+		default:
+			b.WriteString(plain(n.children))
+		}
+	}
+	return b.String()
+}
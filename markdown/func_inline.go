@@ -0,0 +1,542 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// // // // // // // // // // // // // // // //
+
+var (
+	autolinkRe = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*)>`)
+	emailRe    = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*)>`)
+	tagRe      = regexp.MustCompile(`^<(/?)([A-Za-z][A-Za-z0-9]*)[ \t]*/?>`)
+	bareURLRe  = regexp.MustCompile(`^(?i:https?://|www\.)[^\s<]+`)
+	issueRe    = regexp.MustCompile(`^#([0-9]{1,9})\b`)
+	mentionRe  = regexp.MustCompile(`^@([A-Za-z0-9](?:[A-Za-z0-9_.-]*[A-Za-z0-9_])?)`)
+)
+
+// allowedTags are the HTML tags kept from bodies, and only without
+// attributes; any other markup is shown as text.
+var allowedTags = map[string]bool{
+	"details": true,
+	"summary": true,
+	"br":      true,
+	"b":       true,
+	"i":       true,
+	"em":      true,
+	"strong":  true,
+	"code":    true,
+	"kbd":     true,
+	"sub":     true,
+	"sup":     true,
+}
+
+// //
+
+// inline parses the inline content of a block. Inside link text, links,
+// bare URLs and references are left as text.
+func (d *docObj) inline(s string, inLink bool) []inlineObj {
+	var out []inlineObj
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			out = append(out, inlineObj{typ: inlineText, text: html.UnescapeString(text.String())})
+			text.Reset()
+		}
+	}
+	emit := func(n inlineObj) {
+		flush()
+		out = append(out, n)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			emit(inlineObj{typ: inlineBreak})
+			i += 2
+			continue
+
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			emit(inlineObj{typ: inlineText, text: s[i+1 : i+2]})
+			i += 2
+			continue
+
+		case c == '\n':
+			line := text.String()
+			trimmed := strings.TrimRight(line, " ")
+			text.Reset()
+			text.WriteString(trimmed)
+			if len(line)-len(trimmed) >= 2 {
+				emit(inlineObj{typ: inlineBreak})
+			} else {
+				emit(inlineObj{typ: inlineSoftBreak})
+			}
+			i++
+			continue
+
+		case c == '`':
+			if code, n := codeSpan(s[i:]); n > 0 {
+				emit(inlineObj{typ: inlineCode, text: code})
+				i += n
+				continue
+			}
+			n := runLen(s[i:], '`')
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+
+		case c == '!' && strings.HasPrefix(s[i:], "!["):
+			if node, n := d.link(s[i+1:], true); n > 0 {
+				emit(node)
+				i += n + 1
+				continue
+			}
+
+		case c == '[' && !inLink:
+			if node, n := d.link(s[i:], false); n > 0 {
+				emit(node)
+				i += n
+				continue
+			}
+
+		case c == '<':
+			if node, n := d.angle(s[i:], inLink); n > 0 {
+				if node.typ == inlineText {
+					flush()
+				} else {
+					emit(node)
+				}
+				i += n
+				continue
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			if nodes, n := d.emphasis(s, i, inLink); n > 0 {
+				flush()
+				out = append(out, nodes...)
+				i += n
+				continue
+			}
+			n := runLen(s[i:], c)
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+
+		case inLink || !wordStart(s, i):
+
+		case c == 'h' || c == 'H' || c == 'w' || c == 'W':
+			if m := bareURLRe.FindString(s[i:]); m != "" {
+				m = trimURL(m)
+				href := m
+				if strings.HasPrefix(strings.ToLower(m), "www.") {
+					href = "http://" + m
+				}
+				emit(inlineObj{typ: inlineLink, href: d.linker.resolve(href), auto: true, children: []inlineObj{{typ: inlineText, text: m}}})
+				i += len(m)
+				continue
+			}
+
+		case c == '#' && d.linker.hasLinks():
+			if m := issueRe.FindStringSubmatch(s[i:]); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				emit(inlineObj{typ: inlineLink, href: d.linker.links.IssueURL(n), auto: true, children: []inlineObj{{typ: inlineText, text: m[0]}}})
+				i += len(m[0])
+				continue
+			}
+
+		case c == '@' && d.linker.hasLinks():
+			if m := mentionRe.FindStringSubmatch(s[i:]); m != nil && !strings.HasPrefix(s[i+len(m[0]):], "/") {
+				emit(inlineObj{typ: inlineLink, href: d.linker.links.UserURL(m[1]), auto: true, children: []inlineObj{{typ: inlineText, text: m[0]}}})
+				i += len(m[0])
+				continue
+			}
+		}
+
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return out
+}
+
+// //
+
+// codeSpan parses the code span at the start of s and returns its content
+// and length, or 0 when the backticks are not closed.
+func codeSpan(s string) (string, int) {
+	open := runLen(s, '`')
+	for j := open; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		n := runLen(s[j:], '`')
+		if n != open {
+			j += n
+			continue
+		}
+
+		code := strings.ReplaceAll(s[open:j], "\n", " ")
+		if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+			code = code[1 : len(code)-1]
+		}
+		return code, j + n
+	}
+	return "", 0
+}
+
+// link parses the link or image whose label starts s: inline
+// "[text](url "title")", full "[text][id]", collapsed "[id][]" or shortcut
+// "[id]" references. It returns the length taken, 0 when s is no link.
+func (d *docObj) link(s string, image bool) (inlineObj, int) {
+	end := labelEnd(s)
+	if end < 0 {
+		return inlineObj{}, 0
+	}
+	label := s[1:end]
+
+	var href, title string
+	n := 0
+	switch {
+	case strings.HasPrefix(s[end+1:], "("):
+		var ok bool
+		href, title, n, ok = destination(s[end+2:])
+		if !ok {
+			return inlineObj{}, 0
+		}
+		n += end + 2
+
+	case strings.HasPrefix(s[end+1:], "["):
+		stop := strings.IndexByte(s[end+2:], ']')
+		if stop < 0 {
+			return inlineObj{}, 0
+		}
+		id := s[end+2 : end+2+stop]
+		if id == "" {
+			id = label
+		}
+		ref, ok := d.refs[refLabel(id)]
+		if !ok {
+			return inlineObj{}, 0
+		}
+		href, title, n = ref.href, ref.title, end+3+stop
+
+	default:
+		ref, ok := d.refs[refLabel(label)]
+		if !ok {
+			return inlineObj{}, 0
+		}
+		href, title, n = ref.href, ref.title, end+1
+	}
+
+	node := inlineObj{typ: inlineLink, href: d.linker.resolve(href), title: title, children: d.inline(label, true)}
+	if image {
+		node.typ = inlineImage
+	}
+	return node, n
+}
+
+// labelEnd returns the index of the bracket closing the one at the start
+// of s, skipping escapes and code spans, or -1.
+func labelEnd(s string) int {
+	depth := 0
+	for j := 0; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			if _, n := codeSpan(s[j:]); n > 0 {
+				j += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// destination parses what follows "](": the URL, in angle brackets or
+// with balanced parentheses, an optional title and the closing ")".
+func destination(s string) (href, title string, n int, ok bool) {
+	i := skipSpace(s, 0)
+	switch {
+	case strings.HasPrefix(s[i:], "<"):
+		end := strings.IndexAny(s[i+1:], ">\n")
+		if end < 0 || s[i+1+end] != '>' {
+			return "", "", 0, false
+		}
+		href = s[i+1 : i+1+end]
+		i += end + 2
+
+	default:
+		start, depth := i, 0
+	loop:
+		for ; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case ' ', '\t', '\n':
+				break loop
+			}
+		}
+		if i > len(s) {
+			i = len(s)
+		}
+		href = s[start:i]
+	}
+
+	j := skipSpace(s, i)
+	if j > i && j < len(s) && strings.IndexByte(`"'(`, s[j]) >= 0 {
+		closer := s[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		end := strings.IndexByte(s[j+1:], closer)
+		if end < 0 {
+			return "", "", 0, false
+		}
+		title = s[j+1 : j+1+end]
+		j = skipSpace(s, j+2+end)
+	}
+	if j >= len(s) || s[j] != ')' {
+		return "", "", 0, false
+	}
+	return unescape(href), unescape(title), j + 1, true
+}
+
+// angle parses what starts with "<": an autolink, an allowed tag or an
+// HTML comment, which is dropped and returned as empty text.
+func (d *docObj) angle(s string, inLink bool) (inlineObj, int) {
+	if strings.HasPrefix(s, "<!--") {
+		if end := strings.Index(s[4:], "-->"); end >= 0 {
+			return inlineObj{typ: inlineText}, end + 7
+		}
+		return inlineObj{}, 0
+	}
+
+	if m := tagRe.FindStringSubmatch(s); m != nil && allowedTags[strings.ToLower(m[2])] {
+		return inlineObj{typ: inlineHTML, text: strings.ToLower(m[2]), closing: m[1] != ""}, len(m[0])
+	}
+	if inLink {
+		return inlineObj{}, 0
+	}
+
+	if m := emailRe.FindStringSubmatch(s); m != nil {
+		return inlineObj{typ: inlineLink, href: "mailto:" + m[1], auto: true, children: []inlineObj{{typ: inlineText, text: m[1]}}}, len(m[0])
+	}
+	if m := autolinkRe.FindStringSubmatch(s); m != nil {
+		return inlineObj{typ: inlineLink, href: d.linker.resolve(m[1]), auto: true, children: []inlineObj{{typ: inlineText, text: m[1]}}}, len(m[0])
+	}
+	return inlineObj{}, 0
+}
+
+// //
+
+// emphasis parses the run of "*", "_" or "~" at s[i] with its closer:
+// one mark is emphasis, two strong emphasis (or strikethrough for "~"),
+// three both. Marks the closer does not match stay text. It returns the
+// length taken, 0 when the run opens nothing.
+func (d *docObj) emphasis(s string, i int, inLink bool) ([]inlineObj, int) {
+	c := s[i]
+	run := runLen(s[i:], c)
+	if !canOpen(s, i, run) {
+		return nil, 0
+	}
+
+	need := run
+	if need > 3 {
+		need = 3
+	}
+	if c == '~' && need != 2 {
+		return nil, 0
+	}
+
+	for ; need > 0; need-- {
+		j := findCloser(s, i+run, c, need)
+		if j < 0 {
+			continue
+		}
+
+		node := inlineObj{typ: inlineEm, children: d.inline(s[i+run:j], inLink)}
+		switch {
+		case c == '~':
+			node.typ = inlineDel
+		case need == 2:
+			node.typ = inlineStrong
+		case need == 3:
+			node = inlineObj{typ: inlineStrong, children: []inlineObj{node}}
+		}
+
+		var out []inlineObj
+		if run > need {
+			out = append(out, inlineObj{typ: inlineText, text: s[i : i+run-need]})
+		}
+		return append(out, node), j + need - i
+	}
+	return nil, 0
+}
+
+// findCloser returns where the run closing an opener of need marks c
+// starts, looking from i on, or -1. Runs that only open are skipped along
+// with their own closers, so that "*a **b** c*" nests.
+func findCloser(s string, i int, c byte, need int) int {
+	for i < len(s) {
+		switch {
+		case s[i] == '\\':
+			i += 2
+			continue
+		case s[i] == '`':
+			if _, n := codeSpan(s[i:]); n > 0 {
+				i += n
+				continue
+			}
+		}
+		if s[i] != c {
+			i++
+			continue
+		}
+
+		run := runLen(s[i:], c)
+		opens, closes := canOpen(s, i, run), canClose(s, i, run)
+		switch {
+		case closes && run >= need:
+			return i
+		case opens && !closes:
+			inner := run
+			if inner > 3 {
+				inner = 3
+			}
+			if j := findCloser(s, i+run, c, inner); j >= 0 {
+				i = j + runLen(s[j:], c)
+				continue
+			}
+		}
+		i += run
+	}
+	return -1
+}
+
+// canOpen and canClose follow the CommonMark flanking rules; "_" also may
+// not open or close inside a word.
+func canOpen(s string, i, run int) bool {
+	before, after := runeBefore(s, i), runeAfter(s, i+run)
+	if unicode.IsSpace(after) {
+		return false
+	}
+	if unicode.IsPunct(after) && !unicode.IsSpace(before) && !unicode.IsPunct(before) {
+		return false
+	}
+	return s[i] != '_' || !isWord(before)
+}
+
+func canClose(s string, i, run int) bool {
+	before, after := runeBefore(s, i), runeAfter(s, i+run)
+	if unicode.IsSpace(before) {
+		return false
+	}
+	if unicode.IsPunct(before) && !unicode.IsSpace(after) && !unicode.IsPunct(after) {
+		return false
+	}
+	return s[i] != '_' || !isWord(after)
+}
+
+// //
+
+// runeBefore and runeAfter treat the ends of s as spaces.
+func runeBefore(s string, i int) rune {
+	if i <= 0 {
+		return ' '
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return r
+}
+
+func runeAfter(s string, i int) rune {
+	if i >= len(s) {
+		return ' '
+	}
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return r
+}
+
+// wordStart reports whether s[i] follows a space or opening punctuation,
+// where bare URLs and references may begin.
+func wordStart(s string, i int) bool {
+	r := runeBefore(s, i)
+	return unicode.IsSpace(r) || strings.ContainsRune(`([{*_~"'`, r)
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isPunct(c byte) bool {
+	return c < 0x80 && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func runLen(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// trimURL drops the punctuation that ends a sentence rather than a bare
+// URL, and a closing parenthesis the URL did not open.
+func trimURL(u string) string {
+	for u != "" {
+		last := u[len(u)-1]
+		switch {
+		case strings.IndexByte(`?!.,:;*_~'"`, last) >= 0:
+			u = u[:len(u)-1]
+		case last == ')' && strings.Count(u, ")") > strings.Count(u, "("):
+			u = u[:len(u)-1]
+		default:
+			return u
+		}
+	}
+	return u
+}
+
+// unescape resolves backslash escapes and entities in link targets and
+// titles.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return html.UnescapeString(s)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return html.UnescapeString(b.String())
+}
@@ -0,0 +1,187 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// // // // // // // // // // // // // // // //
+
+type textObj struct {
+	doc *docObj
+}
+
+// blocks renders list one block after another, separated by blank lines
+// unless tight.
+func (t *textObj) blocks(list []*blockObj, tight bool) string {
+	sep := "\n\n"
+	if tight {
+		sep = "\n"
+	}
+
+	var parts []string
+	for _, b := range list {
+		if s := t.block(b); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func (t *textObj) block(b *blockObj) string {
+	switch b.typ {
+	case blockParagraph, blockHTML:
+		return strings.TrimSpace(t.inline(b.text))
+
+	case blockHeading:
+		s := strings.TrimSpace(t.inline(b.text))
+		mark := "-"
+		if b.level == 1 {
+			mark = "="
+		}
+		return s + "\n" + strings.Repeat(mark, width(s))
+
+	case blockCode:
+		return prefix(strings.TrimSuffix(b.text, "\n"), "    ", "    ")
+
+	case blockQuote:
+		return prefix(t.blocks(b.children, false), "> ", "> ")
+
+	case blockList:
+		items := make([]string, len(b.children))
+		for i, item := range b.children {
+			marker := "- "
+			if b.ordered {
+				marker = strconv.Itoa(b.start+i) + ". "
+			}
+			items[i] = prefix(t.blocks(item.children, !b.loose), marker, strings.Repeat(" ", len(marker)))
+		}
+		if b.loose {
+			return strings.Join(items, "\n\n")
+		}
+		return strings.Join(items, "\n")
+
+	case blockRule:
+		return "---"
+
+	case blockTable:
+		return t.table(b)
+	}
+	return ""
+}
+
+// table pads the cells into columns, with a dashed line under the header.
+func (t *textObj) table(b *blockObj) string {
+	rows := make([][]string, len(b.rows))
+	widths := make([]int, len(b.align))
+	for r, cells := range b.rows {
+		rows[r] = make([]string, len(cells))
+		for c, cell := range cells {
+			rows[r][c] = strings.Join(strings.Fields(t.inline(cell)), " ")
+			if w := width(rows[r][c]); w > widths[c] {
+				widths[c] = w
+			}
+		}
+	}
+
+	var lines []string
+	for r, cells := range rows {
+		padded := make([]string, len(cells))
+		for c, cell := range cells {
+			padded[c] = pad(cell, widths[c], b.align[c])
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(padded, " | "), " "))
+
+		if r == 0 {
+			dashes := make([]string, len(widths))
+			for c, w := range widths {
+				dashes[c] = strings.Repeat("-", w)
+			}
+			lines = append(lines, strings.Join(dashes, "-+-"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// //
+
+func (t *textObj) inline(src string) string {
+	var b strings.Builder
+	t.nodes(&b, t.doc.inline(src, false))
+	return b.String()
+}
+
+func (t *textObj) nodes(b *strings.Builder, list []inlineObj) {
+	for _, n := range list {
+		switch n.typ {
+		case inlineText, inlineCode:
+			b.WriteString(n.text)
+
+		case inlineEm, inlineStrong, inlineDel:
+			t.nodes(b, n.children)
+
+		case inlineLink:
+			label := plain(n.children)
+			t.nodes(b, n.children)
+			if n.href != "" && !n.auto && n.href != label && n.href != "mailto:"+label {
+				b.WriteString(" (" + n.href + ")")
+			}
+
+		case inlineImage:
+			if alt := plain(n.children); alt != "" {
+				b.WriteString(alt)
+			} else {
+				b.WriteString(n.href)
+			}
+
+		case inlineBreak, inlineSoftBreak:
+			b.WriteString("\n")
+
+		case inlineHTML:
+			if n.text == "br" {
+				b.WriteString("\n")
+			}
+		}
+	}
+}
+
+// //
+
+// prefix puts first before the first line of s and rest before the
+// others; blank lines only get the prefix trimmed of spaces.
+func prefix(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		p := rest
+		if i == 0 {
+			p = first
+		}
+		if line == "" {
+			p = strings.TrimRight(p, " ")
+		}
+		lines[i] = p + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func pad(s string, w int, align alignType) string {
+	gap := w - width(s)
+	switch align {
+	case alignRight:
+		return strings.Repeat(" ", gap) + s
+	case alignCenter:
+		return strings.Repeat(" ", gap/2) + s + strings.Repeat(" ", gap-gap/2)
+	}
+	return s + strings.Repeat(" ", gap)
+}
+
+func width(s string) int {
+	w := 0
+	for _, line := range strings.Split(s, "\n") {
+		if n := utf8.RuneCountInString(line); n > w {
+			w = n
+		}
+	}
+	return w
+}
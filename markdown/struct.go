@@ -0,0 +1,108 @@
+package markdown
+
+import (
+	"net/url"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// LinkerObj turns the references in a body into absolute URLs on one
+// repository: "#123" and "@user" through the provider's links, paths
+// starting with "/" against its host, other relative paths against the
+// files at ref. The zero value and nil leave links as they are.
+type LinkerObj struct {
+	links lightweigit.ProviderLinksInterface
+	base  *url.URL
+	ref   string
+}
+
+// //
+
+type blockType byte
+
+const (
+	blockParagraph blockType = iota
+	blockHeading
+	blockCode
+	blockQuote
+	blockList
+	blockItem
+	blockRule
+	blockTable
+	blockHTML
+)
+
+type alignType byte
+
+const (
+	alignNone alignType = iota
+	alignLeft
+	alignCenter
+	alignRight
+)
+
+// blockObj is one block of the document. text is the inline source of
+// paragraphs, headings and HTML lines and the content of code blocks;
+// quotes, lists and items hold children.
+type blockObj struct {
+	typ      blockType
+	text     string
+	level    int
+	lang     string
+	ordered  bool
+	start    int
+	loose    bool
+	children []*blockObj
+
+	align []alignType
+	rows  [][]string
+
+	// first and last are the lines the block spans, last without the
+	// blank lines after it; list looseness is read from the gaps.
+	first, last int
+}
+
+// //
+
+type inlineType byte
+
+const (
+	inlineText inlineType = iota
+	inlineCode
+	inlineEm
+	inlineStrong
+	inlineDel
+	inlineLink
+	inlineImage
+	inlineBreak
+	inlineSoftBreak
+	inlineHTML
+)
+
+// inlineObj is one inline element. Links keep the resolved target in href,
+// empty when the target is unsafe; inlineHTML keeps the tag name in text.
+// auto marks links whose text says enough on its own: "#123", "@user" and
+// bare URLs.
+type inlineObj struct {
+	typ      inlineType
+	text     string
+	href     string
+	title    string
+	closing  bool
+	auto     bool
+	children []inlineObj
+}
+
+// refObj is a link reference definition, "[id]: url "title"".
+type refObj struct {
+	href, title string
+}
+
+// docObj is a parsed body with the link definitions found in it.
+type docObj struct {
+	blocks []*blockObj
+	refs   map[string]refObj
+	linker *LinkerObj
+}
@@ -0,0 +1,75 @@
+package lightweigit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+// ParseAttemptObj is one provider's verdict on a URL.
+type ParseAttemptObj struct {
+	Provider string
+	Err      error
+}
+
+// ParseErrorObj is returned by global.Parse when no provider accepted the
+// URL. It keeps every provider's own error, so errors.Is / errors.As see
+// through to sentinels such as ErrForbidden or ErrTooManyRequests no matter
+// which provider in the chain produced them.
+type ParseErrorObj struct {
+	Raw      string
+	Attempts []ParseAttemptObj
+}
+
+func (e *ParseErrorObj) Add(provider string, err error) {
+	e.Attempts = append(e.Attempts, ParseAttemptObj{Provider: provider, Err: err})
+}
+
+func (e *ParseErrorObj) Error() string {
+	parts := make([]string, 0, len(e.Attempts))
+	for _, a := range e.Attempts {
+		parts = append(parts, a.Provider+": "+a.Err.Error())
+	}
+	return fmt.Sprintf("no provider accepted %q: %s", e.Raw, strings.Join(parts, "; "))
+}
+
+// Trace lists the providers in the order they were tried, one per line,
+// with the reason each of them rejected the URL.
+func (e *ParseErrorObj) Trace() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "parse %q:\n", e.Raw)
+	for i, a := range e.Attempts {
+		fmt.Fprintf(&b, "  %d. %s: %v\n", i+1, a.Provider, a.Err)
+	}
+	return b.String()
+}
+
+// Unwrap exposes the per-provider errors to errors.Is / errors.As on Go
+// 1.20+; Is and As below do the same walk for older toolchains.
+func (e *ParseErrorObj) Unwrap() []error {
+	errs := make([]error, 0, len(e.Attempts))
+	for _, a := range e.Attempts {
+		errs = append(errs, a.Err)
+	}
+	return errs
+}
+
+func (e *ParseErrorObj) Is(target error) bool {
+	for _, a := range e.Attempts {
+		if errors.Is(a.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ParseErrorObj) As(target any) bool {
+	for _, a := range e.Attempts {
+		if errors.As(a.Err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package lightweigit
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+// RefKindType names what a repository URL points at beyond the repository.
+type RefKindType uint8
+
+const (
+	RefNone RefKindType = iota
+	RefTag
+	RefRelease
+	RefBranch
+	RefCommit
+	RefFile
+	RefTree
+)
+
+func (k RefKindType) String() string {
+	switch k {
+	case RefTag:
+		return "tag"
+	case RefRelease:
+		return "release"
+	case RefBranch:
+		return "branch"
+	case RefCommit:
+		return "commit"
+	case RefFile:
+		return "file"
+	case RefTree:
+		return "tree"
+	}
+	return "none"
+}
+
+// RefObj is the part of a URL after the repository.
+//
+// Name is the tag, release tag, branch or commit the URL refers to; for
+// files and trees it is the ref they are viewed at, which the URL does not
+// always qualify as branch or tag. Path is the file or directory inside
+// the repository, without leading slash. Ref names containing '/' cannot
+// be told apart from the path: the first segment is taken as the name.
+type RefObj struct {
+	Kind RefKindType
+	Name string
+	Path string
+}
+
+// ProviderRefInterface is implemented by providers that can read a ref out
+// of their own URL shapes. Ref is purely syntactic and returns the zero
+// RefObj for URLs of other repositories.
+type ProviderRefInterface interface {
+	Ref(raw string) RefObj
+}
+
+// ParseResultObj is a parsed URL: the repository and what it points at.
+type ParseResultObj struct {
+	Provider ProviderInterface
+	Ref      RefObj
+}
+
+// //
+
+// PathAfter splits the path of raw (a URL or an scp-like address) and
+// returns the segments after the repository name; nil when name is not a
+// part of the path. Names are compared case-insensitively and a ".git"
+// suffix on the last name segment is ignored.
+func PathAfter(raw string, name string) []string {
+	s := strings.TrimSpace(raw)
+	if !strings.Contains(s, "://") {
+		if at := strings.LastIndex(s, "@"); at >= 0 {
+			if colon := strings.Index(s[at:], ":"); colon > 0 {
+				s = "ssh://" + s[:at+colon] + "/" + s[at+colon+1:]
+			}
+		}
+		if !strings.Contains(s, "://") {
+			s = "https://" + s
+		}
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil
+	}
+
+	var segs []string
+	for _, seg := range strings.Split(u.Path, "/") {
+		if seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	want := strings.Split(strings.Trim(name, "/"), "/")
+
+	for i := 0; i+len(want) <= len(segs); i++ {
+		ok := true
+		for j, w := range want {
+			seg := segs[i+j]
+			if j == len(want)-1 {
+				seg = strings.TrimSuffix(seg, ".git")
+			}
+			if !strings.EqualFold(seg, w) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return segs[i+len(want):]
+		}
+	}
+	return nil
+}
+
+// RefAt builds a RefObj of kind k from tail[0] as the name and the rest of
+// tail as the path. Without a name the result is the zero RefObj; file and
+// tree kinds without a path fall back to def.
+func RefAt(k RefKindType, def RefKindType, tail []string) RefObj {
+	if len(tail) == 0 || tail[0] == "" {
+		return RefObj{}
+	}
+	ref := RefObj{Kind: k, Name: tail[0], Path: strings.Join(tail[1:], "/")}
+	if ref.Path == "" && (k == RefFile || k == RefTree) {
+		ref.Kind = def
+	}
+	return ref
+}
+
+// // // //
+
+// Tag looks up the tag the URL refers to. Files, trees and commits viewed
+// at a named ref try that name as a tag; branches and URLs without a ref
+// give ErrNotFound.
+func (r *ParseResultObj) Tag() (ProviderTagInterface, error) {
+	if r.Ref.Kind == RefNone || r.Ref.Kind == RefBranch || r.Ref.Name == "" {
+		return nil, fmt.Errorf("URL does not reference a tag: %w", ErrNotFound)
+	}
+	return r.Provider.TagFind(r.Ref.Name)
+}
+
+// Release looks up the release the URL refers to, by the same rules as Tag.
+func (r *ParseResultObj) Release() (ProviderReleaseInterface, error) {
+	if r.Ref.Kind == RefNone || r.Ref.Kind == RefBranch || r.Ref.Name == "" {
+		return nil, fmt.Errorf("URL does not reference a release: %w", ErrNotFound)
+	}
+	return r.Provider.ReleaseFind(r.Ref.Name)
+}
@@ -0,0 +1,362 @@
+package lightweigit
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+// ProviderEntryObj describes a provider to the registry.
+//
+// Match decides by host whether Parse is worth calling at all; nil means
+// "any host" and leaves the decision to Parse. Providers are tried by
+// descending Priority, ties by Name. ParsePinned serves hosts pinned in
+// the registry's host map and must not probe. ParseOffline, ParsePinned,
+// the unmarshalers and the mod bytes are optional: a zero
+// TagMod/ReleaseMod/BranchMod means "no blob format".
+// Custom providers take mod bytes from target.ModCustomMin upwards;
+// target.ModSnapshot and target.ModWatchState are refused.
+type ProviderEntryObj struct {
+	Name     string
+	Priority int
+
+	Match        func(host string) bool
+	Parse        func(raw string) (ProviderInterface, error)
+	ParseOffline func(raw string) (ProviderInterface, error)
+	ParsePinned  func(raw string, pin HostPinObj) (ProviderInterface, error)
+
+	TagMod           target.ModType
+	ReleaseMod       target.ModType
+	UnmarshalTag     func(data []byte) (ProviderTagInterface, error)
+	UnmarshalRelease func(data []byte) (ProviderReleaseInterface, error)
+
+	BranchMod       target.ModType
+	UnmarshalBranch func(data []byte) (ProviderBranchInterface, error)
+}
+
+func (entry *ProviderEntryObj) mods() []target.ModType {
+	return []target.ModType{entry.TagMod, entry.ReleaseMod, entry.BranchMod}
+}
+
+// RegistryObj is an ordered, concurrency-safe set of providers. Hosts, when
+// set, pins hosts to providers ahead of the ordered search.
+type RegistryObj struct {
+	Hosts *HostMapObj
+
+	mu      sync.RWMutex
+	entries []ProviderEntryObj
+}
+
+// Registry is the process-wide registry behind Register and global.Parse.
+// The built-in providers add themselves to it from their init functions.
+var Registry = &RegistryObj{Hosts: HostMap}
+
+func Register(entry ProviderEntryObj) error {
+	return Registry.Register(entry)
+}
+
+// RegisterBuiltin registers a built-in provider from its package's init
+// function. parse, parseOffline and parsePinned fill the entry's Parse
+// functions; parsePinned may be nil. It panics on error: the name and mod
+// bytes of a built-in provider are fixed at build time.
+func RegisterBuiltin[P ProviderInterface](entry ProviderEntryObj, parse, parseOffline func(string) (P, error), parsePinned func(string, HostPinObj) (P, error)) {
+	entry.Parse = asProvider(parse)
+	entry.ParseOffline = asProvider(parseOffline)
+	if parsePinned != nil {
+		entry.ParsePinned = func(raw string, pin HostPinObj) (ProviderInterface, error) {
+			obj, err := parsePinned(raw, pin)
+			if err != nil {
+				return nil, err
+			}
+			return obj, nil
+		}
+	}
+
+	if err := Registry.Register(entry); err != nil {
+		panic(err)
+	}
+}
+
+// asProvider turns a package's Parse into the registry's signature. A
+// failed parse returns a nil interface, not a typed nil pointer.
+func asProvider[P ProviderInterface](parse func(string) (P, error)) func(string) (ProviderInterface, error) {
+	if parse == nil {
+		return nil
+	}
+	return func(raw string) (ProviderInterface, error) {
+		obj, err := parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+}
+
+// //
+
+// HostOf extracts the lower-cased host (without port) from a URL or an
+// scp-like SSH address; it returns "" when there is none.
+func HostOf(raw string) string {
+	s := strings.TrimSpace(raw)
+	if !strings.Contains(s, "://") {
+		if at := strings.LastIndex(s, "@"); at >= 0 {
+			if colon := strings.Index(s[at:], ":"); colon > 0 {
+				return strings.ToLower(s[at+1 : at+colon])
+			}
+		}
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// IsSSHScheme reports whether a URL scheme is one of git's SSH spellings.
+func IsSSHScheme(scheme string) bool {
+	switch strings.ToLower(scheme) {
+	case "ssh", "git+ssh", "ssh+git":
+		return true
+	}
+	return false
+}
+
+func (r *RegistryObj) Register(entry ProviderEntryObj) error {
+	if entry.Name == "" {
+		return errors.New("register: empty provider name")
+	}
+	if entry.Parse == nil {
+		return fmt.Errorf("register %s: nil Parse", entry.Name)
+	}
+	if entry.TagMod != 0 && entry.UnmarshalTag == nil {
+		return fmt.Errorf("register %s: TagMod without UnmarshalTag", entry.Name)
+	}
+	if entry.ReleaseMod != 0 && entry.UnmarshalRelease == nil {
+		return fmt.Errorf("register %s: ReleaseMod without UnmarshalRelease", entry.Name)
+	}
+	if entry.BranchMod != 0 && entry.UnmarshalBranch == nil {
+		return fmt.Errorf("register %s: BranchMod without UnmarshalBranch", entry.Name)
+	}
+	for _, m := range entry.mods() {
+		if m == target.ModSnapshot || m == target.ModWatchState {
+			return fmt.Errorf("register %s: mod %d is reserved for %s", entry.Name, m, m)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range r.entries {
+		if e.Name == entry.Name {
+			return fmt.Errorf("register %s: provider already registered", entry.Name)
+		}
+		for _, m := range entry.mods() {
+			for _, taken := range e.mods() {
+				if m != 0 && m == taken {
+					return fmt.Errorf("register %s: mod %d already taken by %s", entry.Name, m, e.Name)
+				}
+			}
+		}
+	}
+
+	r.entries = append(r.entries, entry)
+	sort.SliceStable(r.entries, func(i, j int) bool {
+		if r.entries[i].Priority != r.entries[j].Priority {
+			return r.entries[i].Priority > r.entries[j].Priority
+		}
+		return r.entries[i].Name < r.entries[j].Name
+	})
+	return nil
+}
+
+func (r *RegistryObj) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, e := range r.entries {
+		if e.Name == name {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Entries returns the providers in the order Parse tries them.
+func (r *RegistryObj) Entries() []ProviderEntryObj {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]ProviderEntryObj(nil), r.entries...)
+}
+
+func (r *RegistryObj) Lookup(name string) (ProviderEntryObj, bool) {
+	for _, e := range r.Entries() {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return ProviderEntryObj{}, false
+}
+
+func (r *RegistryObj) hasMod(m target.ModType) bool {
+	if m == 0 {
+		return false
+	}
+	for _, e := range r.Entries() {
+		for _, taken := range e.mods() {
+			if m == taken {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// // // //
+
+func (r *RegistryObj) parse(raw string, offline bool) (ProviderInterface, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, errors.New("an empty URL string")
+	}
+
+	host := HostOf(raw)
+	perr := &ParseErrorObj{Raw: raw}
+	if r.Hosts != nil {
+		if pin, ok := r.Hosts.Get(host); ok {
+			obj, err := r.parsePinned(raw, pin)
+			if err != nil {
+				perr.Add(pin.Provider, err)
+				return nil, perr
+			}
+			return obj, nil
+		}
+	}
+
+	for _, e := range r.Entries() {
+		if e.Match != nil && !e.Match(host) {
+			perr.Add(e.Name, fmt.Errorf("host %q: %w", host, ErrHostMismatch))
+			continue
+		}
+
+		fn := e.Parse
+		if offline {
+			fn = e.ParseOffline
+		}
+		if fn == nil {
+			perr.Add(e.Name, errors.New("no offline parse"))
+			continue
+		}
+
+		obj, err := fn(raw)
+		if err == nil {
+			return obj, nil
+		}
+		perr.Add(e.Name, err)
+	}
+
+	if len(perr.Attempts) == 0 {
+		return nil, errors.New("no providers registered")
+	}
+	return nil, perr
+}
+
+// parsePinned hands raw to the one provider its host is pinned to; the
+// other providers are not consulted, so the result does not depend on
+// detection.
+func (r *RegistryObj) parsePinned(raw string, pin HostPinObj) (ProviderInterface, error) {
+	e, ok := r.Lookup(pin.Provider)
+	if !ok {
+		return nil, fmt.Errorf("host %q is pinned to unregistered provider", pin.Host)
+	}
+	if e.ParsePinned == nil {
+		return nil, fmt.Errorf("host %q: provider does not support pinned hosts", pin.Host)
+	}
+	return e.ParsePinned(raw, pin)
+}
+
+// Parse tries the providers in order and returns the first match. When
+// none matches, the error is a *ParseErrorObj with the reason of each.
+func (r *RegistryObj) Parse(raw string) (ProviderInterface, error) {
+	return r.parse(raw, false)
+}
+
+// ParseOffline is Parse over the providers' ParseOffline functions; it
+// sends no requests.
+func (r *RegistryObj) ParseOffline(raw string) (ProviderInterface, error) {
+	return r.parse(raw, true)
+}
+
+// ParseRef is Parse that also keeps what the URL points at beyond the
+// repository, for providers implementing ProviderRefInterface.
+func (r *RegistryObj) ParseRef(raw string) (*ParseResultObj, error) {
+	return parseResult(raw, r.Parse)
+}
+
+// ParseRefOffline is ParseRef over ParseOffline.
+func (r *RegistryObj) ParseRefOffline(raw string) (*ParseResultObj, error) {
+	return parseResult(raw, r.ParseOffline)
+}
+
+func parseResult(raw string, parse func(string) (ProviderInterface, error)) (*ParseResultObj, error) {
+	obj, err := parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ParseResultObj{Provider: obj}
+	if rp, ok := obj.(ProviderRefInterface); ok {
+		res.Ref = rp.Ref(raw)
+	}
+	return res, nil
+}
+
+func (r *RegistryObj) UnmarshalTag(data []byte) (ProviderTagInterface, error) {
+	if len(data) < 5 {
+		return nil, errors.New("not enough data")
+	}
+
+	m := target.ModType(data[0])
+	for _, e := range r.Entries() {
+		if e.TagMod != 0 && e.TagMod == m {
+			return e.UnmarshalTag(data)
+		}
+	}
+	return nil, fmt.Errorf("unknown tag mod type: %d", data[0])
+}
+
+func (r *RegistryObj) UnmarshalRelease(data []byte) (ProviderReleaseInterface, error) {
+	if len(data) < 5 {
+		return nil, errors.New("not enough data")
+	}
+
+	m := target.ModType(data[0])
+	for _, e := range r.Entries() {
+		if e.ReleaseMod != 0 && e.ReleaseMod == m {
+			return e.UnmarshalRelease(data)
+		}
+	}
+	return nil, fmt.Errorf("unknown release mod type: %d", data[0])
+}
+
+func (r *RegistryObj) UnmarshalBranch(data []byte) (ProviderBranchInterface, error) {
+	if len(data) < 5 {
+		return nil, errors.New("not enough data")
+	}
+
+	m := target.ModType(data[0])
+	for _, e := range r.Entries() {
+		if e.BranchMod != 0 && e.BranchMod == m {
+			return e.UnmarshalBranch(data)
+		}
+	}
+	return nil, fmt.Errorf("unknown branch mod type: %d", data[0])
+}
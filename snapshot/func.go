@@ -0,0 +1,167 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+func derefURL(u *url.URL) url.URL {
+	if u == nil {
+		return url.URL{}
+	}
+	return *u
+}
+
+func collect[T any](ctx context.Context, stream func(context.Context, chan T, int) error) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := make(chan T)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- stream(ctx, out, 0)
+		close(out)
+	}()
+
+	items := make([]T, 0)
+	for it := range out {
+		items = append(items, it)
+	}
+	return items, <-errCh
+}
+
+// //
+
+func (obj *Obj) addTag(t lightweigit.ProviderTagInterface) *TagObj {
+	return &TagObj{
+		Provider: obj,
+		name:     t.String(),
+		url:      derefURL(t.URL()),
+		zip:      derefURL(t.ZIP()),
+		tar:      derefURL(t.TAR()),
+	}
+}
+
+func (obj *Obj) addRelease(r lightweigit.ProviderReleaseInterface) *ReleaseObj {
+	rel := &ReleaseObj{
+		Provider:     obj,
+		tag:          obj.addTag(r.Tag()),
+		name:         r.Name(),
+		bodyMD:       r.BodyMD(),
+		url:          derefURL(r.URL()),
+		zip:          derefURL(r.ZIP()),
+		tar:          derefURL(r.TAR()),
+		assets:       make([]lightweigit.ProviderReleaseAssetInterface, 0, len(r.Assets())),
+		isPrerelease: r.IsPrerelease(),
+	}
+	if rt, ok := r.(lightweigit.ReleaseTimeInterface); ok {
+		rel.published = rt.Time()
+	}
+	for _, a := range r.Assets() {
+		rel.assets = append(rel.assets, &ReleaseAssetObj{
+			download:    derefURL(a.URL()),
+			contentType: a.ContentType(),
+			size:        a.Size(),
+		})
+	}
+	return rel
+}
+
+// Take drains TagsStream and ReleasesStream of obj and freezes the result.
+// Stream order is kept, so TagLatest/ReleaseLatest of the snapshot answer
+// the same way the provider did at the moment of the call.
+func Take(ctx context.Context, obj lightweigit.ProviderInterface) (*Obj, error) {
+	if obj == nil {
+		return nil, errors.New("nil provider")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tags, err := collect(ctx, obj.TagsStream)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := collect(ctx, obj.ReleasesStream)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Obj{
+		kind:     obj.Type(),
+		domain:   obj.Domain(),
+		name:     obj.String(),
+		url:      derefURL(obj.URL()),
+		taken:    time.Now().UTC(),
+		identity: lightweigit.Identity(obj),
+		tags:     make([]*TagObj, 0, len(tags)),
+		releases: make([]*ReleaseObj, 0, len(releases)),
+	}
+	if c, ok := obj.(lightweigit.ProviderCloneInterface); ok {
+		snap.cloneHTTPS, snap.cloneSSH = c.CloneHTTPS(), c.CloneSSH()
+	}
+	for _, t := range tags {
+		snap.tags = append(snap.tags, snap.addTag(t))
+	}
+	for _, r := range releases {
+		snap.releases = append(snap.releases, snap.addRelease(r))
+	}
+
+	return snap, nil
+}
+
+// // // //
+
+// Type reports the provider the snapshot was taken from, not "snapshot":
+// consumers switching on Type() keep working on loaded snapshots.
+func (obj *Obj) Type() string {
+	return obj.kind
+}
+
+// Identity is the identity of the provider the snapshot was taken from.
+// Snapshots written before identities were stored fall back to the
+// generic one.
+func (obj *Obj) Identity() lightweigit.IdentityObj {
+	if obj.identity.IsZero() {
+		return lightweigit.IdentityObj{
+			Family: obj.kind,
+			Host:   lightweigit.NormalizeHost(obj.domain),
+			Name:   obj.name,
+		}
+	}
+	return obj.identity
+}
+
+// CloneHTTPS is the source provider's clone URL; "" when it had none.
+func (obj *Obj) CloneHTTPS() string {
+	return obj.cloneHTTPS
+}
+
+// CloneSSH is the source provider's SSH clone URL; "" when it had none.
+func (obj *Obj) CloneSSH() string {
+	return obj.cloneSSH
+}
+
+func (obj *Obj) Domain() string {
+	return obj.domain
+}
+
+func (obj *Obj) String() string {
+	return obj.name
+}
+
+func (obj *Obj) URL() *url.URL {
+	u := obj.url
+	return &u
+}
+
+// Time is the moment Take finished collecting.
+func (obj *Obj) Time() time.Time {
+	return obj.taken
+}
@@ -0,0 +1,229 @@
+package snapshot
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+type byteTagObj struct {
+	Name string
+	URL  string
+	ZIP  string
+	TAR  string
+}
+
+type byteAssetObj struct {
+	Size        uint32
+	ContentType string
+	DownloadURL string
+}
+type byteReleaseObj struct {
+	Tag          byteTagObj
+	Name         string
+	BodyMD       string
+	URL          string
+	ZIP          string
+	TAR          string
+	IsPrerelease bool
+	Published    int64
+	Assets       []byteAssetObj
+}
+
+// byteObj is the whole container: the provider identity is written once and
+// every item below carries only its own fields.
+type byteObj struct {
+	Type     string
+	Domain   string
+	Name     string
+	URL      string
+	Taken    int64
+	Identity lightweigit.IdentityObj
+	Clone    [2]string
+	Tags     []byteTagObj
+	Releases []byteReleaseObj
+}
+
+//
+
+func parseURL(s string) url.URL {
+	u, err := url.Parse(s)
+	if err != nil || u == nil {
+		return url.URL{}
+	}
+	return *u
+}
+
+func (tag *TagObj) toByte() byteTagObj {
+	return byteTagObj{
+		Name: tag.name,
+		URL:  tag.url.String(),
+		ZIP:  tag.zip.String(),
+		TAR:  tag.tar.String(),
+	}
+}
+
+func (obj *Obj) fromByteTag(dataObj byteTagObj) *TagObj {
+	return &TagObj{
+		Provider: obj,
+		name:     dataObj.Name,
+		url:      parseURL(dataObj.URL),
+		zip:      parseURL(dataObj.ZIP),
+		tar:      parseURL(dataObj.TAR),
+	}
+}
+
+func (rel *ReleaseObj) toByte() byteReleaseObj {
+	dataObj := byteReleaseObj{
+		Tag:          rel.tag.toByte(),
+		Name:         rel.name,
+		BodyMD:       rel.bodyMD,
+		URL:          rel.url.String(),
+		ZIP:          rel.zip.String(),
+		TAR:          rel.tar.String(),
+		IsPrerelease: rel.isPrerelease,
+		Assets:       make([]byteAssetObj, 0),
+	}
+	if !rel.published.IsZero() {
+		dataObj.Published = rel.published.UnixNano()
+	}
+	for _, asset := range rel.assets {
+		dataObj.Assets = append(dataObj.Assets, byteAssetObj{
+			Size:        asset.Size(),
+			ContentType: asset.ContentType(),
+			DownloadURL: asset.URL().String(),
+		})
+	}
+	return dataObj
+}
+
+func (obj *Obj) fromByteRelease(dataObj byteReleaseObj) *ReleaseObj {
+	release := &ReleaseObj{
+		Provider:     obj,
+		tag:          obj.fromByteTag(dataObj.Tag),
+		name:         dataObj.Name,
+		bodyMD:       dataObj.BodyMD,
+		url:          parseURL(dataObj.URL),
+		zip:          parseURL(dataObj.ZIP),
+		tar:          parseURL(dataObj.TAR),
+		isPrerelease: dataObj.IsPrerelease,
+		assets:       make([]lightweigit.ProviderReleaseAssetInterface, 0),
+	}
+	if dataObj.Published != 0 {
+		release.published = time.Unix(0, dataObj.Published).UTC()
+	}
+	for _, asset := range dataObj.Assets {
+		release.assets = append(release.assets, &ReleaseAssetObj{
+			size:        asset.Size,
+			contentType: asset.ContentType,
+			download:    parseURL(asset.DownloadURL),
+		})
+	}
+	return release
+}
+
+// //
+
+func (obj *Obj) header() byteObj {
+	return byteObj{
+		Type:     obj.kind,
+		Domain:   obj.domain,
+		Name:     obj.name,
+		URL:      obj.url.String(),
+		Taken:    obj.taken.UnixNano(),
+		Identity: obj.identity,
+		Clone:    [2]string{obj.cloneHTTPS, obj.cloneSSH},
+		Tags:     make([]byteTagObj, 0),
+		Releases: make([]byteReleaseObj, 0),
+	}
+}
+
+// Marshal writes the whole snapshot as one lightweigit.Marshal container
+// (deflate + CRC32) tagged with target.ModSnapshot.
+func (obj *Obj) Marshal() []byte {
+	dataObj := obj.header()
+	for _, tag := range obj.tags {
+		dataObj.Tags = append(dataObj.Tags, tag.toByte())
+	}
+	for _, rel := range obj.releases {
+		dataObj.Releases = append(dataObj.Releases, rel.toByte())
+	}
+	return lightweigit.Marshal(target.ModSnapshot, dataObj)
+}
+
+func Unmarshal(data []byte) (*Obj, error) {
+	dataObj := new(byteObj)
+	mod, err := lightweigit.Unmarshal(data, dataObj)
+	if err != nil {
+		return nil, err
+	}
+	if mod != target.ModSnapshot {
+		return nil, lightweigit.ErrModTag
+	}
+
+	obj := &Obj{
+		kind:     dataObj.Type,
+		domain:   dataObj.Domain,
+		name:     dataObj.Name,
+		url:      parseURL(dataObj.URL),
+		taken:    time.Unix(0, dataObj.Taken).UTC(),
+		identity: dataObj.Identity,
+
+		cloneHTTPS: dataObj.Clone[0],
+		cloneSSH:   dataObj.Clone[1],
+
+		tags:     make([]*TagObj, 0, len(dataObj.Tags)),
+		releases: make([]*ReleaseObj, 0, len(dataObj.Releases)),
+	}
+	for _, t := range dataObj.Tags {
+		obj.tags = append(obj.tags, obj.fromByteTag(t))
+	}
+	for _, r := range dataObj.Releases {
+		obj.releases = append(obj.releases, obj.fromByteRelease(r))
+	}
+
+	return obj, nil
+}
+
+// // // //
+
+// A single tag or release is stored as a snapshot holding just that item,
+// so it keeps the provider identity without a per-provider format.
+
+func (tag *TagObj) Marshal() []byte {
+	dataObj := tag.Provider.header()
+	dataObj.Tags = append(dataObj.Tags, tag.toByte())
+	return lightweigit.Marshal(target.ModSnapshot, dataObj)
+}
+
+func UnmarshalTag(data []byte) (lightweigit.ProviderTagInterface, error) {
+	obj, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(obj.tags) == 0 {
+		return nil, lightweigit.ErrModTag
+	}
+	return obj.tags[0], nil
+}
+
+func (rel *ReleaseObj) Marshal() []byte {
+	dataObj := rel.Provider.header()
+	dataObj.Releases = append(dataObj.Releases, rel.toByte())
+	return lightweigit.Marshal(target.ModSnapshot, dataObj)
+}
+
+func UnmarshalRelease(data []byte) (lightweigit.ProviderReleaseInterface, error) {
+	obj, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(obj.releases) == 0 {
+		return nil, lightweigit.ErrModTag
+	}
+	return obj.releases[0], nil
+}
@@ -0,0 +1,163 @@
+package snapshot
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func (tag *TagObj) Mod() target.ModType {
+	return target.ModSnapshot
+}
+
+func (tag *TagObj) String() string {
+	return tag.name
+}
+
+func (tag *TagObj) URL() *url.URL {
+	u := tag.url
+	return &u
+}
+
+func (tag *TagObj) ZIP() *url.URL {
+	u := tag.zip
+	return &u
+}
+
+func (tag *TagObj) TAR() *url.URL {
+	u := tag.tar
+	return &u
+}
+
+// //
+
+func (a *ReleaseAssetObj) Name() string {
+	return path.Base(a.download.Path)
+}
+
+func (a *ReleaseAssetObj) URL() *url.URL {
+	return &a.download
+}
+
+func (a *ReleaseAssetObj) ContentType() string {
+	return a.contentType
+}
+
+func (a *ReleaseAssetObj) Size() uint32 {
+	return a.size
+}
+
+//
+
+func (rel *ReleaseObj) Mod() target.ModType {
+	return target.ModSnapshot
+}
+
+func (rel *ReleaseObj) Name() string {
+	return rel.name
+}
+
+func (rel *ReleaseObj) BodyMD() string {
+	return rel.bodyMD
+}
+
+func (rel *ReleaseObj) URL() *url.URL {
+	u := rel.url
+	return &u
+}
+
+func (rel *ReleaseObj) Tag() lightweigit.ProviderTagInterface {
+	return rel.tag
+}
+
+func (rel *ReleaseObj) ZIP() *url.URL {
+	u := rel.zip
+	return &u
+}
+
+func (rel *ReleaseObj) TAR() *url.URL {
+	u := rel.tar
+	return &u
+}
+
+func (rel *ReleaseObj) Assets() []lightweigit.ProviderReleaseAssetInterface {
+	return rel.assets
+}
+
+func (rel *ReleaseObj) IsPrerelease() bool {
+	return rel.isPrerelease
+}
+
+// Time is the publication time of the source release; zero when its
+// provider did not report one.
+func (rel *ReleaseObj) Time() time.Time {
+	return rel.published
+}
+
+// // // //
+
+func (obj *Obj) TagLatest() (lightweigit.ProviderTagInterface, error) {
+	if len(obj.tags) == 0 {
+		return nil, lightweigit.ErrNotFound
+	}
+	return obj.tags[0], nil
+}
+
+func (obj *Obj) TagFind(findTag string) (lightweigit.ProviderTagInterface, error) {
+	for _, tag := range obj.tags {
+		if tag.name == findTag {
+			return tag, nil
+		}
+	}
+	return nil, lightweigit.ErrNotFound
+}
+
+func (obj *Obj) TagsStream(ctx context.Context, out chan lightweigit.ProviderTagInterface, limit int) error {
+	for i, tag := range obj.tags {
+		if limit > 0 && i >= limit {
+			return nil
+		}
+		if err := lightweigit.Send[lightweigit.ProviderTagInterface](ctx, out, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// //
+
+func (obj *Obj) ReleaseLatest() (lightweigit.ProviderReleaseInterface, error) {
+	for _, rel := range obj.releases {
+		if !rel.isPrerelease {
+			return rel, nil
+		}
+	}
+	return nil, lightweigit.ErrNotFound
+}
+
+func (obj *Obj) ReleaseFind(findRelease string) (lightweigit.ProviderReleaseInterface, error) {
+	for _, rel := range obj.releases {
+		if rel.tag.name == findRelease || rel.name == findRelease {
+			return rel, nil
+		}
+	}
+	return nil, lightweigit.ErrNotFound
+}
+
+func (obj *Obj) ReleasesStream(ctx context.Context, out chan lightweigit.ProviderReleaseInterface, limit int) error {
+	for i, rel := range obj.releases {
+		if limit > 0 && i >= limit {
+			return nil
+		}
+		if err := lightweigit.Send[lightweigit.ProviderReleaseInterface](ctx, out, rel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package snapshot
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// Obj is a frozen copy of a repository's tags and releases. It implements
+// lightweigit.ProviderInterface and answers every lookup from memory, so a
+// loaded snapshot never touches the network.
+type Obj struct {
+	kind   string
+	domain string
+	name   string
+	url    url.URL
+	taken  time.Time
+
+	// identity is the source provider's, so that a snapshot and a live
+	// handle of one repository compare equal in lightweigit.SameRepo.
+	identity lightweigit.IdentityObj
+
+	cloneHTTPS string
+	cloneSSH   string
+
+	tags     []*TagObj
+	releases []*ReleaseObj
+}
+
+type TagObj struct {
+	Provider *Obj
+	name     string
+	url      url.URL
+	zip      url.URL
+	tar      url.URL
+}
+
+type ReleaseAssetObj struct {
+	download    url.URL
+	contentType string
+	size        uint32
+}
+
+type ReleaseObj struct {
+	Provider     *Obj
+	tag          *TagObj
+	name         string
+	bodyMD       string
+	url          url.URL
+	zip          url.URL
+	tar          url.URL
+	assets       []lightweigit.ProviderReleaseAssetInterface
+	isPrerelease bool
+	published    time.Time
+}
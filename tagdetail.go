@@ -0,0 +1,98 @@
+package lightweigit
+
+import (
+	"strings"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+// TagKindType tells lightweight tags, plain refs to a commit, from
+// annotated ones, which point at a tag object of their own.
+type TagKindType byte
+
+const (
+	TagLightweight TagKindType = iota
+	TagAnnotated
+)
+
+func (k TagKindType) String() string {
+	switch k {
+	case TagLightweight:
+		return "lightweight"
+	case TagAnnotated:
+		return "annotated"
+	}
+	return "unknown"
+}
+
+// VerificationObj is the forge's verdict on a signature. Reason is the
+// forge's own wording ("valid", "unknown_key", ...).
+type VerificationObj struct {
+	Verified bool
+	Reason   string
+}
+
+// TagDetailObj describes what a tag points at and, for annotated tags,
+// what the tag object says. Fields a forge does not report stay at their
+// zero value.
+type TagDetailObj struct {
+	Name string
+	Kind TagKindType
+
+	// SHA is the tag object for annotated tags and the commit for
+	// lightweight ones; Commit is the object the tag points at.
+	SHA    string
+	Commit string
+
+	Message     string
+	TaggerName  string
+	TaggerEmail string
+	TaggerDate  time.Time
+
+	// Signature is the armored OpenPGP or SSH signature, cut off the
+	// message; Payload is the signed tag object, where the forge serves it.
+	Signature string
+	Payload   string
+
+	// Verification is nil for unsigned tags and for forges that do not
+	// check signatures.
+	Verification *VerificationObj
+}
+
+// ProviderTagDetailInterface is implemented by providers that expose tag
+// objects: GitHub, GitLab and Gitea / Forgejo.
+type ProviderTagDetailInterface interface {
+	TagDetail(name string) (*TagDetailObj, error)
+}
+
+// //
+
+// IsSigned reports whether the tag carries a signature, or the forge says
+// it does.
+func (d *TagDetailObj) IsSigned() bool {
+	return d.Signature != "" || d.Verification != nil
+}
+
+// signatureMarks open the signatures git appends to tag messages.
+var signatureMarks = []string{
+	"-----BEGIN PGP SIGNATURE-----",
+	"-----BEGIN SSH SIGNATURE-----",
+	"-----BEGIN SIGNED MESSAGE-----",
+}
+
+// SplitTagSignature cuts the signature git appends to a signed tag's
+// message off it. Messages without one come back whole.
+func SplitTagSignature(msg string) (message, signature string) {
+	at := -1
+	for _, mark := range signatureMarks {
+		i := strings.LastIndex(msg, mark)
+		if i > at && (i == 0 || msg[i-1] == '\n') {
+			at = i
+		}
+	}
+	if at < 0 {
+		return msg, ""
+	}
+	return msg[:at], msg[at:]
+}
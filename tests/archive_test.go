@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func TestArchive_Providers(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == "/api/v4/projects/group%2Frepo" {
+			w.Write([]byte(`{"id":7}`))
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	for _, tc := range []struct {
+		raw    string
+		ref    string
+		format lightweigit.ArchiveFormatType
+		want   string
+	}{
+		{"https://github.com/o/r", sha, lightweigit.ArchiveZIP, "https://github.com/o/r/archive/" + sha + ".zip"},
+		{"https://github.com/o/r", sha, lightweigit.ArchiveTarGz, "https://github.com/o/r/archive/" + sha + ".tar.gz"},
+		{"https://github.com/o/r", sha, lightweigit.ArchiveTarBz2, ""},
+		{"https://gitlab.com/group/repo", sha, lightweigit.ArchiveTarBz2, "https://gitlab.com/api/v4/projects/7/repository/archive.tar.bz2?sha=" + sha},
+		{"https://bitbucket.org/ws/repo", sha, lightweigit.ArchiveTarBz2, "https://bitbucket.org/ws/repo/get/" + sha + ".tar.bz2"},
+		{"https://gitea.com/o/r", sha, lightweigit.ArchiveTarGz, "https://gitea.com/o/r/archive/" + sha + ".tar.gz"},
+		{"https://gitea.com/o/r", sha, lightweigit.ArchiveTarBz2, ""},
+		{"https://gitea.com/o/r", "release/1.0", lightweigit.ArchiveZIP, "https://gitea.com/o/r/archive/release/1.0.zip"},
+		{"https://bitbucket.org/ws/repo", "release/1.0", lightweigit.ArchiveTarGz, "https://bitbucket.org/ws/repo/get/release/1.0.tar.gz"},
+		{"https://bitbucket.org/ws/repo", "feature x", lightweigit.ArchiveZIP, "https://bitbucket.org/ws/repo/get/feature%20x.zip"},
+	} {
+		u, err := offlineAs[lightweigit.ProviderArchiveInterface](t, tc.raw).Archive(tc.ref, tc.format)
+		if tc.want == "" {
+			if !errors.Is(err, lightweigit.ErrUnsupported) {
+				t.Fatalf("%s %s: expected ErrUnsupported, got %v", tc.raw, tc.format, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.raw, tc.format, err)
+		}
+		if u.String() != tc.want {
+			t.Fatalf("%s %s: got %s", tc.raw, tc.format, u)
+		}
+	}
+}
+
+func TestArchive_TagLinksUnchanged(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/o/r/git/ref/tags/v1.0.0" {
+			w.Write([]byte(`{"ref":"refs/tags/v1.0.0"}`))
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	p, err := global.ParseOffline("https://github.com/o/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := p.TagFind("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.ZIP().String() != "https://github.com/o/r/archive/refs/tags/v1.0.0.zip" {
+		t.Fatalf("unexpected tag ZIP: %s", tag.ZIP())
+	}
+	if _, err := p.(lightweigit.ProviderArchiveInterface).Archive(" ", lightweigit.ArchiveZIP); err == nil {
+		t.Fatal("expected an error for an empty ref")
+	}
+}
+
+func TestArchive_ParseFormat(t *testing.T) {
+	for _, s := range []string{"zip", "tar.gz", "tar.bz2"} {
+		f, ok := lightweigit.ParseArchiveFormat(s)
+		if !ok || f.String() != s {
+			t.Fatalf("ParseArchiveFormat(%q) = %v %v", s, f, ok)
+		}
+	}
+	if f, ok := lightweigit.ParseArchiveFormat("tgz"); !ok || f != lightweigit.ArchiveTarGz {
+		t.Fatal("tgz not accepted")
+	}
+	if _, ok := lightweigit.ParseArchiveFormat("rar"); ok {
+		t.Fatal("rar accepted")
+	}
+}
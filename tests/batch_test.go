@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/batch"
+)
+
+// // // // // // // // // // // // // // // //
+
+// batchServer answers the latest release of github.com/o/rN with vN.0.0,
+// slowly enough for lookups to overlap, and records how many were in
+// flight at once. The first `limited` requests for o/limited get 429.
+type batchServerObj struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	limited  int
+}
+
+func (s *batchServerObj) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.peak {
+		s.peak = s.inFlight
+	}
+	limited := false
+	if strings.HasPrefix(r.URL.Path, "/repos/o/limited/") && s.limited > 0 {
+		s.limited--
+		limited = true
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if limited {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+		return
+	}
+	var n int
+	if _, err := fmt.Sscanf(r.URL.Path, "/repos/o/r%d/releases/latest", &n); err != nil {
+		if r.URL.Path == "/repos/o/limited/releases/latest" {
+			w.Write([]byte(`{"tag_name":"v9.0.0"}`))
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprintf(w, `{"tag_name":"v%d.0.0"}`, n)
+}
+
+// //
+
+func TestBatch_OrderAndLimits(t *testing.T) {
+	s := &batchServerObj{}
+	recordServer(t, s.serve)
+
+	var urls []string
+	for i := 0; i < 12; i++ {
+		urls = append(urls, fmt.Sprintf("https://github.com/o/r%d", i))
+	}
+	urls = append(urls, "https://github.com/o/missing", "not a repository")
+	items := batch.URLs(urls...)
+	items = append(items, batch.ItemObj{})
+
+	var (
+		mu       sync.Mutex
+		progress []int
+	)
+	res := batch.ReleaseLatest(context.Background(), batch.OptionsObj{
+		PerHost: 3,
+		Offline: true,
+		OnProgress: func(p batch.ProgressObj) {
+			mu.Lock()
+			defer mu.Unlock()
+			if p.Total != len(items) {
+				t.Errorf("progress total %d, want %d", p.Total, len(items))
+			}
+			progress = append(progress, p.Done)
+		},
+	}, items)
+
+	if len(res) != len(items) {
+		t.Fatalf("got %d results for %d items", len(res), len(items))
+	}
+	for i := 0; i < 12; i++ {
+		r := res[i]
+		if r.Err != nil || r.Index != i || r.Value.Tag().String() != fmt.Sprintf("v%d.0.0", i) {
+			t.Fatalf("result %d out of order or failed: %+v", i, r)
+		}
+	}
+	if !errors.Is(res[12].Err, lightweigit.ErrNotFound) || res[12].Provider == nil {
+		t.Fatalf("missing repository: %+v", res[12])
+	}
+	if res[13].Err == nil || res[13].Provider != nil {
+		t.Fatalf("an unparsable URL must fail on its own: %+v", res[13])
+	}
+	if !errors.Is(res[14].Err, batch.ErrEmptyItem) {
+		t.Fatalf("an empty item must fail with ErrEmptyItem: %+v", res[14])
+	}
+
+	if s.peak > 3 {
+		t.Fatalf("%d lookups ran against one host at once, PerHost is 3", s.peak)
+	}
+	if len(progress) != len(items) || progress[len(progress)-1] != len(items) {
+		t.Fatalf("progress reported %v", progress)
+	}
+}
+
+func TestBatch_RateLimit(t *testing.T) {
+	s := &batchServerObj{limited: 2}
+	recordServer(t, s.serve)
+
+	opts := batch.OptionsObj{PerHost: 1, Offline: true, Backoff: 20 * time.Millisecond, MaxBackoff: 40 * time.Millisecond}
+	start := time.Now()
+	res := batch.ReleaseLatest(context.Background(), opts, batch.URLs("https://github.com/o/limited", "https://github.com/o/r1"))
+	if res[0].Err != nil || res[0].Value.Tag().String() != "v9.0.0" || res[1].Err != nil {
+		t.Fatalf("rate-limited lookups must be retried: %v, %v", res[0].Err, res[1].Err)
+	}
+	if d := time.Since(start); d < 60*time.Millisecond {
+		t.Fatalf("the host must pause between retries, took only %v", d)
+	}
+
+	s.mu.Lock()
+	s.limited = 5
+	s.mu.Unlock()
+	opts.Retries = 1
+	res = batch.ReleaseLatest(context.Background(), opts, batch.URLs("https://github.com/o/limited"))
+	if !errors.Is(res[0].Err, lightweigit.ErrTooManyRequests) {
+		t.Fatalf("retries must give up with the rate-limit error, got %v", res[0].Err)
+	}
+}
+
+func TestBatch_Cancel(t *testing.T) {
+	s := &batchServerObj{limited: 100}
+	recordServer(t, s.serve)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	res := batch.TagLatest(ctx, batch.OptionsObj{PerHost: 1, Offline: true, Backoff: time.Hour},
+		batch.URLs("https://github.com/o/limited", "https://github.com/o/r1"))
+	for _, r := range res {
+		if !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Fatalf("lookups held by a paused host must end with the context: %+v", r)
+		}
+	}
+}
+
+// portDomainObj reports its host the way a self-hosted provider would,
+// with the case and port taken from the URL.
+type portDomainObj struct {
+	lightweigit.ProviderInterface
+}
+
+func (portDomainObj) Domain() string { return "GitHub.com:443" }
+
+func TestBatch_OneQueuePerHost(t *testing.T) {
+	s := &batchServerObj{}
+	recordServer(t, s.serve)
+
+	items := batch.URLs("https://GitHub.com/o/r0", "git@github.com:o/r1.git")
+	for i := 2; i < 6; i++ {
+		p := offlineAs[lightweigit.ProviderInterface](t, fmt.Sprintf("https://github.com/o/r%d", i))
+		items = append(items, batch.ItemObj{Provider: portDomainObj{p}})
+	}
+
+	res := batch.ReleaseLatest(context.Background(), batch.OptionsObj{PerHost: 1, Offline: true}, items)
+	for i, r := range res {
+		if r.Err != nil {
+			t.Fatalf("item %d: %v", i, r.Err)
+		}
+	}
+	if s.peak != 1 {
+		t.Fatalf("URL and provider items for one host must share a queue, %d ran at once", s.peak)
+	}
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func streamBranches(t *testing.T, b lightweigit.ProviderBranchesInterface, limit int) []string {
+	t.Helper()
+
+	out := make(chan lightweigit.ProviderBranchInterface)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- b.BranchesStream(context.Background(), out, limit)
+		close(out)
+	}()
+
+	var got []string
+	for br := range out {
+		got = append(got, br.String()+"@"+br.Commit())
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("BranchesStream error: %v", err)
+	}
+	return got
+}
+
+// //
+
+func TestBranch_Providers(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		// GitHub
+		case "/repos/o/r":
+			w.Write([]byte(`{"default_branch":"main"}`))
+		case "/repos/o/r/branches/main":
+			w.Write([]byte(`{"name":"main","commit":{"sha":"aaa"}}`))
+		case "/repos/o/r/branches":
+			w.Write([]byte(`[{"name":"main","commit":{"sha":"aaa"}},{"name":"release-1","commit":{"sha":"bbb"}}]`))
+
+		// GitLab
+		case "/api/v4/projects/group%2Frepo":
+			w.Write([]byte(`{"id":7}`))
+		case "/api/v4/projects/7":
+			w.Write([]byte(`{"default_branch":"main"}`))
+		case "/api/v4/projects/7/repository/branches/main":
+			w.Write([]byte(`{"name":"main","commit":{"id":"aaa"}}`))
+		case "/api/v4/projects/7/repository/branches":
+			w.Write([]byte(`[{"name":"main","commit":{"id":"aaa"}},{"name":"release-1","commit":{"id":"bbb"}}]`))
+
+		// Bitbucket: two pages joined by the next cursor
+		case "/2.0/repositories/ws/repo":
+			w.Write([]byte(`{"mainbranch":{"name":"main"}}`))
+		case "/2.0/repositories/ws/repo/refs/branches/main":
+			w.Write([]byte(`{"name":"main","target":{"hash":"aaa"}}`))
+		case "/2.0/repositories/ws/repo/refs/branches":
+			if r.URL.Query().Get("page") == "2" {
+				w.Write([]byte(`{"values":[{"name":"release-1","target":{"hash":"bbb"}}]}`))
+				return
+			}
+			w.Write([]byte(`{"values":[{"name":"main","target":{"hash":"aaa"}}],"next":"https://api.bitbucket.org/2.0/repositories/ws/repo/refs/branches?page=2"}`))
+
+		// Gitea
+		case "/api/v1/repos/o/r":
+			w.Write([]byte(`{"default_branch":"main"}`))
+		case "/api/v1/repos/o/r/branches/main":
+			w.Write([]byte(`{"name":"main","commit":{"id":"aaa"}}`))
+		case "/api/v1/repos/o/r/branches":
+			w.Write([]byte(`[{"name":"main","commit":{"id":"aaa"}},{"name":"release-1","commit":{"id":"bbb"}}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	for _, tc := range []struct {
+		raw, zip string
+	}{
+		{"https://github.com/o/r", "https://github.com/o/r/archive/refs/heads/main.zip"},
+		{"https://gitlab.com/group/repo", "https://gitlab.com/api/v4/projects/7/repository/archive.zip?sha=main"},
+		{"https://bitbucket.org/ws/repo", "https://bitbucket.org/ws/repo/get/main.zip"},
+		{"https://gitea.com/o/r", "https://gitea.com/o/r/archive/main.zip"},
+	} {
+		b := offlineAs[lightweigit.ProviderBranchesInterface](t, tc.raw)
+
+		def, err := b.BranchDefault()
+		if err != nil {
+			t.Fatalf("%s: BranchDefault error: %v", tc.raw, err)
+		}
+		if def.String() != "main" || def.Commit() != "aaa" {
+			t.Fatalf("%s: default %s@%s", tc.raw, def.String(), def.Commit())
+		}
+		if def.ZIP().String() != tc.zip {
+			t.Fatalf("%s: ZIP %s", tc.raw, def.ZIP())
+		}
+
+		back, err := global.UnmarshalBranch(def.Marshal())
+		if err != nil {
+			t.Fatalf("%s: UnmarshalBranch error: %v", tc.raw, err)
+		}
+		if back.Mod() != def.Mod() || back.String() != "main" || back.Commit() != "aaa" || back.TAR().String() != def.TAR().String() {
+			t.Fatalf("%s: round trip lost data: %s@%s %s", tc.raw, back.String(), back.Commit(), back.TAR())
+		}
+
+		got := strings.Join(streamBranches(t, b, 0), ",")
+		if got != "main@aaa,release-1@bbb" {
+			t.Fatalf("%s: stream %s", tc.raw, got)
+		}
+		if got := streamBranches(t, b, 1); len(got) != 1 {
+			t.Fatalf("%s: limit ignored: %v", tc.raw, got)
+		}
+	}
+}
+
+func TestBranch_GogsUnpaged(t *testing.T) {
+	var all []string
+	for i := 0; i < 60; i++ {
+		all = append(all, fmt.Sprintf(`{"name":"b%d","commit":{"id":"c%d"}}`, i, i))
+	}
+	paths := recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/repos/o/r/branches" {
+			w.Write([]byte("[" + strings.Join(all, ",") + "]"))
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	got := streamBranches(t, offlineAs[lightweigit.ProviderBranchesInterface](t, "https://gitea.com/o/r"), 0)
+	if len(got) != 60 {
+		t.Fatalf("got %d branches, want 60", len(got))
+	}
+	n := 0
+	for _, p := range paths() {
+		if p == "/api/v1/repos/o/r/branches" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("got %d branch requests, want 1", n)
+	}
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/changelog"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+type changelogReleaseObj struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// changelogServer serves the releases newest first, as GitHub does.
+func changelogServer(t *testing.T) func() []string {
+	t.Helper()
+
+	rels := []changelogReleaseObj{
+		{"v1.4.0", "v1.4.0", "## Features\n\n- later\n", false},
+		{"v1.3.0", "Big one", "## What's Changed\n\n* feat(api): new endpoint\n  with details\n* fix: crash on start\n* chore: bump deps\n* Plain bullet\n\n## New Contributors\n\n* @someone\n", false},
+		{"v1.3.0-rc.1", "", "# RC\n\nTry it.\n", true},
+		{"v1.2.1", "", "### Bug Fixes\n\n- **parser:** handle `nil`\n\n```go\n# not a heading\n```\n", false},
+		{"v1.2.0", "", "Initial.\n", false},
+		{"nightly", "", "Nightly build.\n", true},
+	}
+	return recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/r/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") != "1" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		json.NewEncoder(w).Encode(rels)
+	})
+}
+
+func versionsOf(c *changelog.ChangelogObj) string {
+	var v []string
+	for _, e := range c.Entries {
+		v = append(v, e.Version)
+	}
+	return strings.Join(v, ",")
+}
+
+// //
+
+func TestChangelog_Select(t *testing.T) {
+	changelogServer(t)
+	p, err := global.ParseOffline("https://github.com/o/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		opts changelog.OptionsObj
+		from string
+		want string
+	}{
+		{changelog.OptionsObj{}, "v1.2.0", "v1.3.0,v1.3.0-rc.1,v1.2.1"},
+		{changelog.OptionsObj{SkipPrereleases: true}, "v1.2.0", "v1.3.0,v1.2.1"},
+		{changelog.OptionsObj{ExcludeTo: true, Ascending: true}, "v1.2.0", "v1.2.1,v1.3.0-rc.1"},
+		{changelog.OptionsObj{Order: changelog.OrderDate}, "v1.2.0", "v1.3.0,v1.3.0-rc.1,v1.2.1"},
+		// A tag without a release is a valid lower bound for semver.
+		{changelog.OptionsObj{}, "v1.2.0-beta", "v1.3.0,v1.3.0-rc.1,v1.2.1,v1.2.0"},
+	} {
+		c, err := changelog.Build(context.Background(), p, tc.from, "v1.3.0", tc.opts)
+		if err != nil {
+			t.Fatalf("%+v: %v", tc.opts, err)
+		}
+		if got := versionsOf(c); got != tc.want {
+			t.Fatalf("%+v: got %s, want %s", tc.opts, got, tc.want)
+		}
+	}
+
+	if _, err := changelog.Build(context.Background(), p, "v0.9.0", "v1.3.0", changelog.OptionsObj{Order: changelog.OrderDate}); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := changelog.Build(context.Background(), p, "nightly", "v1.3.0", changelog.OptionsObj{}); err == nil {
+		t.Fatal("expected an error for a non-version bound")
+	}
+}
+
+func TestChangelog_Render(t *testing.T) {
+	changelogServer(t)
+	p, _ := global.ParseOffline("https://github.com/o/r")
+
+	c, err := changelog.Build(context.Background(), p, "v1.2.0", "v1.3.0", changelog.OptionsObj{SkipPrereleases: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := c.Markdown()
+	for _, want := range []string{
+		"## [v1.3.0](https://github.com/o/r/releases/tag/v1.3.0) - Big one\n\n### What's Changed\n",
+		"## [v1.2.1](https://github.com/o/r/releases/tag/v1.2.1)\n\n### Bug Fixes\n",
+		"```go\n# not a heading\n```",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("Markdown lacks %q:\n%s", want, md)
+		}
+	}
+
+	text := c.Text()
+	for _, want := range []string{
+		"v1.3.0 - Big one\n================\n",
+		"What's Changed\n--------------\n",
+		"- parser: handle nil",
+		"    # not a heading",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("Text lacks %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestChangelog_Normalize(t *testing.T) {
+	changelogServer(t)
+	p, _ := global.ParseOffline("https://github.com/o/r")
+
+	c, err := changelog.Build(context.Background(), p, "v1.2.0", "v1.3.0", changelog.OptionsObj{SkipPrereleases: true, Normalize: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := c.Markdown()
+	for _, want := range []string{
+		"### Added\n\n- **api:** new endpoint\n  with details\n",
+		"### Changed\n\n* Plain bullet\n",
+		"### Fixed\n\n- crash on start\n",
+		"### Other\n\n- bump deps\n\n* @someone\n",
+		"### Fixed\n\n- **parser:** handle `nil`\n\n```go\n# not a heading\n```\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("Markdown lacks %q:\n%s", want, md)
+		}
+	}
+	if strings.Index(md, "### Added") > strings.Index(md, "### Changed") {
+		t.Fatalf("sections out of order:\n%s", md)
+	}
+}
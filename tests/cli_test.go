@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader/cli"
+)
+
+// // // // // // // // // // // // // // // //
+
+// cliServer serves the GitHub API of o/r with two releases, and answers
+// o/forbidden with 403, o/limited with 429 and o/primary with GitHub's
+// 403 for its primary rate limit.
+func cliServer(t *testing.T) {
+	t.Helper()
+	const (
+		v2 = `{"tag_name":"v2.0.0","name":"Two","html_url":"https://github.com/o/r/releases/tag/v2.0.0","published_at":"2024-03-01T10:00:00Z",
+			"assets":[{"name":"tool_linux.tar.gz","browser_download_url":"https://github.com/o/r/releases/download/v2.0.0/tool_linux.tar.gz","content_type":"application/gzip","size":7},
+				{"name":"tool_darwin.tar.gz","browser_download_url":"https://github.com/o/r/releases/download/v2.0.0/tool_darwin.tar.gz","size":7}]}`
+		v1 = `{"tag_name":"v1.0.0","name":"One","prerelease":true}`
+	)
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		switch p := r.URL.Path; {
+		case strings.HasPrefix(p, "/repos/o/forbidden/"):
+			http.Error(w, `{"message":"Resource not accessible"}`, http.StatusForbidden)
+		case strings.HasPrefix(p, "/repos/o/primary/"):
+			http.Error(w, `{"message":"API rate limit exceeded for 192.0.2.1."}`, http.StatusForbidden)
+		case strings.HasPrefix(p, "/repos/o/limited/"):
+			http.Error(w, `{"message":"API rate limit exceeded"}`, http.StatusTooManyRequests)
+		case p == "/repos/o/r/releases/latest", p == "/repos/o/r/releases/tags/v2.0.0":
+			w.Write([]byte(v2))
+		case p == "/repos/o/r/releases":
+			if page == "1" {
+				w.Write([]byte("[" + v2 + "," + v1 + "]"))
+			} else {
+				w.Write([]byte(`[]`))
+			}
+		case p == "/repos/o/r/tags":
+			if page == "1" {
+				w.Write([]byte(`[{"name":"v2.0.0"},{"name":"v1.0.0"}]`))
+			} else {
+				w.Write([]byte(`[]`))
+			}
+		case strings.HasPrefix(p, "/o/r/releases/download/v2.0.0/"):
+			w.Write([]byte("payload"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := cli.Run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// //
+
+func TestCLI_Output(t *testing.T) {
+	cliServer(t)
+	const repo = "https://github.com/o/r"
+
+	code, out, errOut := runCLI(t, "latest", "-json", repo)
+	if code != cli.ExitOK {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var rel struct {
+		Tag       string `json:"tag"`
+		Published string `json:"published"`
+		Assets    []struct {
+			Name string `json:"name"`
+			Size int    `json:"size"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal([]byte(out), &rel); err != nil {
+		t.Fatalf("latest -json: %v\n%s", err, out)
+	}
+	if rel.Tag != "v2.0.0" || rel.Published != "2024-03-01T10:00:00Z" || len(rel.Assets) != 2 || rel.Assets[0].Size != 7 {
+		t.Fatalf("unexpected release %+v", rel)
+	}
+
+	_, out, _ = runCLI(t, "releases", "-limit", "5", repo)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "TAG") || !strings.Contains(lines[2], "v1.0.0") || !strings.Contains(lines[2], "yes") {
+		t.Fatalf("unexpected releases table:\n%s", out)
+	}
+
+	_, out, _ = runCLI(t, "tags", "-json", "-limit", "1", repo)
+	var tags []struct{ Name string }
+	if err := json.Unmarshal([]byte(out), &tags); err != nil || len(tags) != 1 || tags[0].Name != "v2.0.0" {
+		t.Fatalf("tags -json -limit 1 must give an array of one, got %s (%v)", out, err)
+	}
+
+	_, out, _ = runCLI(t, "archive-url", "-format", "tar.gz", repo, "v1.0.0")
+	if strings.TrimSpace(out) != "https://github.com/o/r/archive/v1.0.0.tar.gz" {
+		t.Fatalf("unexpected archive URL %q", out)
+	}
+
+	_, out, _ = runCLI(t, "assets", repo, "v2.0.0")
+	if !strings.Contains(out, "tool_linux.tar.gz") || !strings.Contains(out, "application/gzip") {
+		t.Fatalf("unexpected assets table:\n%s", out)
+	}
+}
+
+func TestCLI_Download(t *testing.T) {
+	cliServer(t)
+	dir := t.TempDir()
+
+	code, out, errOut := runCLI(t, "download", "-asset", "*linux*", "-dir", dir, "https://github.com/o/r")
+	if code != cli.ExitOK {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "tool_linux.tar.gz"))
+	if err != nil || string(data) != "payload" {
+		t.Fatalf("asset not saved: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tool_darwin.tar.gz")); err == nil {
+		t.Fatalf("an asset not matching the pattern was downloaded")
+	}
+	if !strings.Contains(out, "tool_linux.tar.gz") {
+		t.Fatalf("download must list what it saved:\n%s", out)
+	}
+
+	if code, _, _ := runCLI(t, "download", "-asset", "*.exe", "-dir", dir, "https://github.com/o/r"); code != cli.ExitNotFound {
+		t.Fatalf("no matching asset must exit %d, got %d", cli.ExitNotFound, code)
+	}
+}
+
+func TestCLI_ExitCodes(t *testing.T) {
+	cliServer(t)
+
+	for _, tc := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"find", "https://github.com/o/r", "v9"}, cli.ExitNotFound},
+		{[]string{"latest", "https://github.com/o/forbidden"}, cli.ExitForbidden},
+		{[]string{"tags", "https://github.com/o/limited"}, cli.ExitRateLimited},
+		{[]string{"latest", "https://github.com/o/primary"}, cli.ExitRateLimited},
+		{[]string{"latest"}, cli.ExitUsage},
+		{[]string{"latest", "-nope", "https://github.com/o/r"}, cli.ExitUsage},
+		{[]string{"archive-url", "-format", "rar", "https://github.com/o/r"}, cli.ExitUsage},
+		{[]string{"frobnicate"}, cli.ExitUsage},
+		{[]string{"latest", "-proxy", "::", "https://github.com/o/r"}, cli.ExitUsage},
+		{[]string{"latest", "-h"}, cli.ExitOK},
+	} {
+		code, _, errOut := runCLI(t, tc.args...)
+		if code != tc.want {
+			t.Errorf("%v: exit %d, want %d\n%s", tc.args, code, tc.want, errOut)
+		}
+	}
+}
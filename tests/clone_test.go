@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/gitlab"
+	"github.com/voluminor/lightweigit-loader/gogsFamily"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func cloneOf(t *testing.T, p lightweigit.ProviderInterface) lightweigit.ProviderCloneInterface {
+	t.Helper()
+
+	c, ok := p.(lightweigit.ProviderCloneInterface)
+	if !ok {
+		t.Fatalf("%T does not implement ProviderCloneInterface", p)
+	}
+	return c
+}
+
+// //
+
+func TestClone_ConventionsRoundTrip(t *testing.T) {
+	recordServer(t, http.NotFound)
+
+	for _, tc := range []struct {
+		raw, https, ssh string
+	}{
+		{"https://github.com/o/r/tree/main", "https://github.com/o/r.git", "git@github.com:o/r.git"},
+		{"https://bitbucket.org/ws/repo", "https://bitbucket.org/ws/repo.git", "git@bitbucket.org:ws/repo.git"},
+		{"https://gitlab.com/group/sub/repo/-/tags", "https://gitlab.com/group/sub/repo.git", "git@gitlab.com:group/sub/repo.git"},
+		{"https://gitea.com/o/r/src/branch/main", "https://gitea.com/o/r.git", "git@gitea.com:o/r.git"},
+	} {
+		p, err := global.ParseOffline(tc.raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.raw, err)
+		}
+		c := cloneOf(t, p)
+		if c.CloneHTTPS() != tc.https || c.CloneSSH() != tc.ssh {
+			t.Fatalf("%s: got %s %s", tc.raw, c.CloneHTTPS(), c.CloneSSH())
+		}
+
+		for _, u := range []string{c.CloneHTTPS(), c.CloneSSH()} {
+			back, err := global.ParseOffline(u)
+			if err != nil {
+				t.Fatalf("%s: %v", u, err)
+			}
+			if !lightweigit.SameRepo(p, back) {
+				t.Fatalf("%s does not round-trip: %s != %s", u, lightweigit.Identity(back), lightweigit.Identity(p))
+			}
+		}
+	}
+}
+
+func TestClone_GitLabFromProjectAPI(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == "/api/v4/projects/group%2Fsub%2Frepo" {
+			w.Write([]byte(`{"id":7,"ssh_url_to_repo":"ssh://git@gitlab.example.org:2222/group/sub/repo.git","http_url_to_repo":"https://gitlab.example.org/group/sub/repo.git"}`))
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	obj, err := gitlab.Parse("https://gitlab.example.org/group/sub/repo")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if obj.CloneSSH() != "ssh://git@gitlab.example.org:2222/group/sub/repo.git" {
+		t.Fatalf("unexpected SSH URL: %s", obj.CloneSSH())
+	}
+
+	back, err := gitlab.ParseOffline(obj.CloneSSH())
+	if err != nil {
+		t.Fatalf("ParseOffline error: %v", err)
+	}
+	if !lightweigit.SameRepo(obj, back) || back.Domain() != "gitlab.example.org" {
+		t.Fatalf("SSH URL does not round-trip: %s %s", lightweigit.Identity(back), back.Domain())
+	}
+}
+
+func TestClone_GiteaSSHPort(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/repos/o/r" {
+			w.Write([]byte(`{"clone_url":"https://gitea.example.org/o/r.git","ssh_url":"ssh://git@gitea.example.org:2222/o/r.git"}`))
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	obj, err := gogsFamily.ParsePinned("https://gitea.example.org/o/r", lightweigit.HostPinObj{Provider: "gogsFamily"})
+	if err != nil {
+		t.Fatalf("ParsePinned error: %v", err)
+	}
+	if obj.CloneSSH() != "git@gitea.example.org:o/r.git" {
+		t.Fatalf("unexpected conventional SSH URL: %s", obj.CloneSSH())
+	}
+	if err := obj.FetchCloneURLs(context.Background()); err != nil {
+		t.Fatalf("FetchCloneURLs error: %v", err)
+	}
+	if obj.CloneSSH() != "ssh://git@gitea.example.org:2222/o/r.git" {
+		t.Fatalf("SSH port not applied: %s", obj.CloneSSH())
+	}
+
+	back, err := gogsFamily.ParseOffline(obj.CloneSSH())
+	if err != nil {
+		t.Fatalf("ParseOffline error: %v", err)
+	}
+	if !lightweigit.SameRepo(obj, back) || back.Domain() != "gitea.example.org" {
+		t.Fatalf("SSH URL does not round-trip: %s %s", lightweigit.Identity(back), back.Domain())
+	}
+}
+
+func TestClone_GitLabProbeMiss(t *testing.T) {
+	recordServer(t, http.NotFound)
+
+	_, err := gitlab.Parse("https://code.example.org/o/r")
+	if err == nil || errors.Is(err, lightweigit.ErrNotFound) || !errors.Is(err, lightweigit.ErrHostMismatch) {
+		t.Fatalf("a parse-time 404 must read as a host mismatch, got: %v", err)
+	}
+
+	obj, err := gitlab.ParseOffline("https://gitlab.example.org/o/r")
+	if err != nil {
+		t.Fatalf("ParseOffline error: %v", err)
+	}
+	if err := obj.Resolve(context.Background()); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("resolving a known GitLab handle keeps ErrNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func githubCommits(from, to int) string {
+	var items []string
+	for i := from; i < to; i++ {
+		items = append(items, fmt.Sprintf(`{"sha":"c%d","commit":{"message":"change %d\n\nbody","author":{"name":"A","email":"a@example.org","date":"2024-01-01T00:00:%02dZ"}}}`, i, i, i%60))
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+// //
+
+func TestCompare_GitHubPages(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/r/compare/v1.2.0...v1.3.0" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprintf(w, `{"commits":%s,"files":[{"filename":"b.go","previous_filename":"a.go","status":"renamed","additions":1,"deletions":2}]}`, githubCommits(0, 100))
+		case "2":
+			fmt.Fprintf(w, `{"commits":%s,"files":[{"filename":"b.go","status":"renamed"}]}`, githubCommits(100, 101))
+		default:
+			w.Write([]byte(`{"commits":[]}`))
+		}
+	})
+
+	res, err := offlineAs[lightweigit.ProviderCompareInterface](t, "https://github.com/o/r").Compare("v1.2.0", "v1.3.0")
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if len(res.Commits) != 101 || res.Commits[100].SHA != "c100" {
+		t.Fatalf("got %d commits", len(res.Commits))
+	}
+	if c := res.Commits[0]; c.Title() != "change 0" || c.Author != "A" || c.Email != "a@example.org" || c.Date.IsZero() {
+		t.Fatalf("unexpected commit %+v", c)
+	}
+	if len(res.Files) != 1 || res.Files[0] != (lightweigit.ChangedFileObj{Path: "b.go", OldPath: "a.go", Status: "renamed", Additions: 1, Deletions: 2}) {
+		t.Fatalf("unexpected files %+v", res.Files)
+	}
+}
+
+func TestCompare_Providers(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.EscapedPath() {
+		// GitLab
+		case "/api/v4/projects/group%2Frepo":
+			w.Write([]byte(`{"id":7}`))
+		case "/api/v4/projects/7/repository/compare":
+			if q.Get("from") != "v1.2.0" || q.Get("to") != "v1.3.0" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(`{"commits":[
+				{"id":"c1","author_name":"A","author_email":"a@example.org","authored_date":"2024-01-01T00:00:00.000+01:00","message":"one\n"},
+				{"id":"c2","author_name":"B","author_email":"b@example.org","authored_date":"2024-01-02T00:00:00.000+01:00","message":"two\n"}],
+				"diffs":[
+				{"old_path":"a.go","new_path":"a.go","diff":"@@ -1,2 +1,2 @@\n-x\n+y\n+z\n"},
+				{"old_path":"old.go","new_path":"new.go","renamed_file":true,"diff":""},
+				{"old_path":"gone.go","new_path":"gone.go","deleted_file":true,"diff":"@@ -1 +0,0 @@\n-gone\n"}]}`))
+
+		// Bitbucket
+		case "/2.0/repositories/ws/repo/commits/v1.3.0":
+			if q.Get("page") == "2" {
+				w.Write([]byte(`{"values":[{"hash":"c1","message":"one\n","date":"2024-01-01T00:00:00+00:00","author":{"raw":"A <a@example.org>"}}]}`))
+				return
+			}
+			w.Write([]byte(`{"values":[{"hash":"c2","message":"two\n","date":"2024-01-02T00:00:00+00:00","author":{"raw":"B <b@example.org>"}}],
+				"next":"https://api.bitbucket.org/2.0/repositories/ws/repo/commits/v1.3.0?page=2"}`))
+		case "/2.0/repositories/ws/repo/diffstat/v1.3.0..v1.2.0":
+			w.Write([]byte(`{"values":[
+				{"status":"modified","lines_added":2,"lines_removed":1,"old":{"path":"a.go"},"new":{"path":"a.go"}},
+				{"status":"renamed","old":{"path":"old.go"},"new":{"path":"new.go"}},
+				{"status":"removed","lines_removed":1,"old":{"path":"gone.go"},"new":null}]}`))
+
+		// Gitea, newest commit first
+		case "/api/v1/repos/o/r":
+			w.Write([]byte(`{"full_name":"o/r"}`))
+		case "/api/v1/repos/o/r/compare/v1.2.0...v1.3.0":
+			w.Write([]byte(`{"commits":[
+				{"sha":"c2","commit":{"message":"two\n","author":{"name":"B","email":"b@example.org","date":"2024-01-02T00:00:00Z"}},"files":[{"filename":"a.go","status":"modified"},{"filename":"gone.go","status":"removed"}]},
+				{"sha":"c1","commit":{"message":"one\n","author":{"name":"A","email":"a@example.org","date":"2024-01-01T00:00:00Z"}},"files":[{"filename":"a.go","status":"added"}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	for _, tc := range []struct {
+		raw   string
+		files string
+	}{
+		{"https://gitlab.com/group/repo", "a.go:modified:+2-1,new.go:renamed:+0-0,gone.go:removed:+0-1"},
+		{"https://bitbucket.org/ws/repo", "a.go:modified:+2-1,new.go:renamed:+0-0,gone.go:removed:+0-1"},
+		{"https://gitea.com/o/r", "a.go:modified:+0-0,gone.go:removed:+0-0"},
+	} {
+		res, err := offlineAs[lightweigit.ProviderCompareInterface](t, tc.raw).Compare("v1.2.0", "v1.3.0")
+		if err != nil {
+			t.Fatalf("%s: Compare error: %v", tc.raw, err)
+		}
+		if res.Base != "v1.2.0" || res.Head != "v1.3.0" {
+			t.Fatalf("%s: sides %s %s", tc.raw, res.Base, res.Head)
+		}
+		if len(res.Commits) != 2 || res.Commits[0].SHA != "c1" || res.Commits[1].Author != "B" || res.Commits[1].Email != "b@example.org" {
+			t.Fatalf("%s: unexpected commits %+v", tc.raw, res.Commits)
+		}
+
+		var files []string
+		for _, f := range res.Files {
+			files = append(files, fmt.Sprintf("%s:%s:+%d-%d", f.Path, f.Status, f.Additions, f.Deletions))
+		}
+		if got := strings.Join(files, ","); got != tc.files {
+			t.Fatalf("%s: files %s", tc.raw, got)
+		}
+		if len(res.Files) > 1 && res.Files[1].Status == "renamed" && res.Files[1].OldPath != "old.go" {
+			t.Fatalf("%s: rename lost its old path", tc.raw)
+		}
+	}
+}
+
+func TestCompare_Tags(t *testing.T) {
+	gh, _ := global.ParseOffline("https://github.com/o/r")
+	gl, _ := global.ParseOffline("https://gitlab.com/group/repo")
+
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/repos/o/r/git/ref/tags/v1", "/repos/o/r/git/ref/tags/v2":
+			w.Write([]byte(`{}`))
+		case "/api/v4/projects/group%2Frepo":
+			w.Write([]byte(`{"id":7}`))
+		case "/api/v4/projects/7/repository/tags/v1":
+			w.Write([]byte(`{"name":"v1"}`))
+		case "/repos/o/r/compare/v1...v2":
+			w.Write([]byte(`{"commits":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	a, err := gh.TagFind("v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := gh.TagFind("v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := lightweigit.CompareTags(gh, a, b)
+	if err != nil || res.Base != "v1" || res.Head != "v2" {
+		t.Fatalf("CompareTags: %+v %v", res, err)
+	}
+
+	other, err := gl.TagFind("v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lightweigit.CompareTags(gh, a, other); err == nil {
+		t.Fatal("expected an error for tags of different providers")
+	}
+}
+
+func TestCompare_DiffStat(t *testing.T) {
+	diff := "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n ctx\n--- sql comment\n+++ new\n-old\ndiff --git a/y b/y\n--- a/y\n+++ b/y\n@@ -0,0 +1 @@\n+y\n"
+	if add, del := lightweigit.DiffStat(diff); add != 2 || del != 2 {
+		t.Fatalf("DiffStat = +%d -%d", add, del)
+	}
+}
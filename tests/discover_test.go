@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/discover"
+)
+
+// // // // // // // // // // // // // // // //
+
+func writeFile(t *testing.T, p, body string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeHome points HOME and XDG_CONFIG_HOME at an empty temp directory.
+func fakeHome(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	return home
+}
+
+// //
+
+func TestDiscover_OriginFromSubdir(t *testing.T) {
+	fakeHome(t)
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "config"), `[core]
+	bare = false
+[remote "upstream"]
+	url = https://github.com/upstream/repo.git
+[remote "origin"]
+	url = "https://github.com/owner/repo.git" ; fork
+	fetch = +refs/heads/*:refs/remotes/origin/*
+`)
+	sub := filepath.Join(repo, "cmd", "tool")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	p, r, err := discover.Parse(sub, "")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if r.Name() != "origin" || p.Type() != "github" || p.String() != "owner/repo" {
+		t.Fatalf("unexpected result: %s %s %s", r, p.Type(), p.String())
+	}
+
+	if p, _, err = discover.Parse(repo, "upstream"); err != nil || p.String() != "upstream/repo" {
+		t.Fatalf("unexpected named remote: %v, %v", p, err)
+	}
+	if _, _, err = discover.Parse(repo, "missing"); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestDiscover_WorktreeAndRewrites(t *testing.T) {
+	home := fakeHome(t)
+	writeFile(t, filepath.Join(home, ".gitconfig"), `[url "git@gitlab.com:"]
+	insteadOf = gl:
+[url "git@gitlab.com:team/"]
+	insteadOf = gl:team/
+`)
+
+	main := t.TempDir()
+	writeFile(t, filepath.Join(main, ".git", "config"), "[remote \"origin\"]\n\turl = gl:team/repo.git\n")
+	writeFile(t, filepath.Join(main, ".git", "worktrees", "wt", "commondir"), "../..\n")
+
+	wt := t.TempDir()
+	writeFile(t, filepath.Join(wt, ".git"), "gitdir: "+filepath.Join(main, ".git", "worktrees", "wt")+"\n")
+
+	r, err := discover.Remote(wt, "")
+	if err != nil {
+		t.Fatalf("Remote error: %v", err)
+	}
+	if r.Raw() != "gl:team/repo.git" || r.URL() != "git@gitlab.com:team/repo.git" {
+		t.Fatalf("unexpected rewrite: %s -> %s", r.Raw(), r.URL())
+	}
+	if r.GitDir() != filepath.Join(main, ".git", "worktrees", "wt") {
+		t.Fatalf("unexpected git dir: %s", r.GitDir())
+	}
+}
+
+func TestDiscover_SSHAlias(t *testing.T) {
+	home := fakeHome(t)
+	writeFile(t, filepath.Join(home, ".ssh", "config"), `Host work-gh
+	HostName github.com
+	IdentityFile ~/.ssh/work
+
+Host forge !forge.example.org
+	HostName forge.example.org
+	User gitea
+	Port 2222
+`)
+
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "config"), `[remote "origin"]
+	url = work-gh:owner/repo.git
+[remote "forge"]
+	url = forge:org/repo.git
+[remote "plain"]
+	url = ssh://git@example.org/org/repo.git
+`)
+
+	for name, want := range map[string]string{
+		"origin": "git@github.com:owner/repo.git",
+		"forge":  "ssh://gitea@forge.example.org:2222/org/repo.git",
+		"plain":  "git@example.org:org/repo.git",
+	} {
+		r, err := discover.Remote(repo, name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if r.URL() != want {
+			t.Fatalf("%s: got %s, want %s", name, r.URL(), want)
+		}
+	}
+
+	p, _, err := discover.Parse(repo, "")
+	if err != nil || p.Type() != "github" || p.String() != "owner/repo" {
+		t.Fatalf("unexpected result: %v, %v", p, err)
+	}
+}
+
+func TestDiscover_NoCheckout(t *testing.T) {
+	fakeHome(t)
+	if _, err := discover.GitDir(t.TempDir()); err == nil {
+		t.Fatal("expected error outside a checkout")
+	}
+}
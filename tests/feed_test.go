@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/feed"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+// feedServer serves the GitHub release lists of o/a and o/b and counts
+// the list requests.
+func feedServer(t *testing.T) *int32 {
+	t.Helper()
+	var calls int32
+	lists := map[string]string{
+		"/repos/o/a/releases": `[
+			{"tag_name":"v2.0.0","name":"Two","body":"**bold** <script>x</script>","html_url":"https://github.com/o/a/releases/tag/v2.0.0","published_at":"2024-03-01T10:00:00Z"},
+			{"tag_name":"v1.0.0","name":"","body":"first","html_url":"https://github.com/o/a/releases/tag/v1.0.0","published_at":"2024-01-01T10:00:00Z"}]`,
+		"/repos/o/b/releases": `[
+			{"tag_name":"v0.2.0-rc1","name":"RC","prerelease":true,"html_url":"https://github.com/o/b/releases/tag/v0.2.0-rc1","published_at":"2024-04-01T10:00:00Z"},
+			{"tag_name":"v0.1.0","name":"Zero one","html_url":"https://github.com/o/b/releases/tag/v0.1.0","published_at":"2024-02-01T10:00:00Z"}]`,
+	}
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, ok := lists[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") != "1" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(body))
+	})
+	return &calls
+}
+
+func feedRepos(t *testing.T) []lightweigit.ProviderInterface {
+	t.Helper()
+	a, err := global.ParseOffline("https://github.com/o/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := global.ParseOffline("https://github.com/o/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []lightweigit.ProviderInterface{a, b}
+}
+
+// //
+
+func TestFeed_Build(t *testing.T) {
+	feedServer(t)
+	repos := feedRepos(t)
+
+	f, err := feed.Build(context.Background(), feed.OptionsObj{}, repos...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, e := range f.Entries {
+		titles = append(titles, e.Title)
+	}
+	want := "o/b: RC; o/a: Two; o/b: Zero one; o/a: v1.0.0"
+	if got := strings.Join(titles, "; "); got != want {
+		t.Fatalf("entries:\n got  %q\n want %q", got, want)
+	}
+	if !f.Updated.Equal(time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("feed updated %v, want the newest release", f.Updated)
+	}
+
+	two := f.Entries[1]
+	if two.ID != "https://github.com/o/a/releases/tag/v2.0.0" || two.Tag != "v2.0.0" || two.Repo != "o/a" {
+		t.Fatalf("unexpected entry %+v", two)
+	}
+	if !strings.Contains(two.HTML, "<strong>bold</strong>") || strings.Contains(two.HTML, "<script") {
+		t.Fatalf("notes not rendered and sanitised: %q", two.HTML)
+	}
+
+	f, err = feed.Build(context.Background(), feed.OptionsObj{SkipPrereleases: true, Limit: 2}, repos...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Entries) != 2 || f.Entries[0].Title != "o/a: Two" {
+		t.Fatalf("pre-releases must be skipped and the feed limited, got %+v", f.Entries)
+	}
+
+	f, _ = feed.Build(context.Background(), feed.OptionsObj{}, repos[0])
+	if f.Title != "Releases of o/a" || f.Link != "https://github.com/o/a" || f.Entries[0].Title != "Two" {
+		t.Fatalf("single repository feed: %q %q %q", f.Title, f.Link, f.Entries[0].Title)
+	}
+}
+
+func TestFeed_Render(t *testing.T) {
+	feedServer(t)
+	f, err := feed.Build(context.Background(), feed.OptionsObj{Self: "https://example.org/feed"}, feedRepos(t)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var atom struct {
+		ID      string `xml:"id"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Published string `xml:"published"`
+			Content   string `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(f.Atom(), &atom); err != nil {
+		t.Fatalf("atom: %v", err)
+	}
+	if atom.ID != "https://example.org/feed" || len(atom.Entries) != 4 || atom.Entries[0].Published != "2024-04-01T10:00:00Z" {
+		t.Fatalf("unexpected atom feed %+v", atom)
+	}
+	if !strings.Contains(atom.Entries[1].Content, "<strong>bold</strong>") {
+		t.Fatalf("atom content must carry the HTML notes: %q", atom.Entries[1].Content)
+	}
+
+	var rss struct {
+		Version string `xml:"version,attr"`
+		Items   []struct {
+			GUID    string `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(f.RSS(), &rss); err != nil {
+		t.Fatalf("rss: %v", err)
+	}
+	if rss.Version != "2.0" || len(rss.Items) != 4 || rss.Items[3].PubDate != "Mon, 01 Jan 2024 10:00:00 +0000" {
+		t.Fatalf("unexpected rss feed %+v", rss)
+	}
+
+	var jf struct {
+		Version string `json:"version"`
+		FeedURL string `json:"feed_url"`
+		Items   []struct {
+			ID   string   `json:"id"`
+			Text string   `json:"content_text"`
+			Tags []string `json:"tags"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(f.JSON(), &jf); err != nil {
+		t.Fatalf("json feed: %v", err)
+	}
+	if jf.Version != "https://jsonfeed.org/version/1.1" || jf.FeedURL != "https://example.org/feed" || len(jf.Items) != 4 {
+		t.Fatalf("unexpected json feed %+v", jf)
+	}
+	if len(jf.Items[0].Tags) != 1 || jf.Items[0].Tags[0] != "prerelease" || jf.Items[3].Text != "first" {
+		t.Fatalf("unexpected json items %+v", jf.Items)
+	}
+}
+
+func TestFeed_Handler(t *testing.T) {
+	calls := feedServer(t)
+	h := feed.NewHandler(feed.OptionsObj{TTL: time.Hour}, feedRepos(t)...)
+
+	get := func(target string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/feed", nil)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/atom+xml") {
+		t.Fatalf("got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), `href="http://example.com/feed"`) {
+		t.Fatalf("the self link must come from the request")
+	}
+	if rec.Header().Get("Cache-Control") != "public, max-age=3600" || rec.Header().Get("Last-Modified") != "Mon, 01 Apr 2024 10:00:00 GMT" {
+		t.Fatalf("caching headers: %v", rec.Header())
+	}
+
+	etag := rec.Header().Get("ETag")
+	if rec := get("/feed", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified {
+		t.Fatalf("a matching ETag must give 304, got %d", rec.Code)
+	}
+	if rec := get("/feed?format=json", nil); !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/feed+json") {
+		t.Fatalf("format=json gave %q", rec.Header().Get("Content-Type"))
+	}
+	if rec := get("/feed", map[string]string{"Accept": "application/rss+xml"}); !strings.Contains(rec.Body.String(), `<rss version="2.0">`) {
+		t.Fatalf("Accept must select RSS")
+	}
+	if n := atomic.LoadInt32(calls); n != 2 {
+		t.Fatalf("the feed must be built once within the TTL, got %d list requests", n)
+	}
+
+	h.Invalidate()
+	get("/feed", nil)
+	if n := atomic.LoadInt32(calls); n != 4 {
+		t.Fatalf("Invalidate must force a rebuild, got %d list requests", n)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/feed", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST must be refused, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/local"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func treeObj(entries ...string) gitObjectObj {
+	var b []byte
+	for i := 0; i < len(entries); i += 3 {
+		raw, _ := hex.DecodeString(entries[i+2])
+		b = append(b, []byte(entries[i]+" "+entries[i+1]+"\x00")...)
+		b = append(b, raw...)
+	}
+	return gitObjectObj{"tree", b}
+}
+
+// //
+
+func TestFile_Providers(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/o/r/v1.0.0/go.mod",
+			"/api/v4/projects/7/repository/files/go.mod/raw",
+			"/2.0/repositories/ws/repo/src/v1.0.0/go.mod",
+			"/api/v1/repos/o/r/raw/v1.0.0/go.mod":
+			if strings.Contains(r.URL.Path, "/files/") && r.URL.Query().Get("ref") != "v1.0.0" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte("module example.org/m\n"))
+		case "/api/v4/projects/group%2Frepo":
+			w.Write([]byte(`{"id":7}`))
+		case "/api/v1/repos/o/r":
+			w.Write([]byte(`{"full_name":"o/r"}`))
+		case "/o/r/v1.0.0/big.bin":
+			w.Write(make([]byte, lightweigit.MaxFileSize+1))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	want := lightweigit.BlobSHA([]byte("module example.org/m\n"))
+	for _, raw := range []string{
+		"https://github.com/o/r",
+		"https://gitlab.com/group/repo",
+		"https://bitbucket.org/ws/repo",
+		"https://gitea.com/o/r",
+	} {
+		fp := offlineAs[lightweigit.ProviderFileInterface](t, raw)
+		f, err := fp.File("v1.0.0", "/go.mod")
+		if err != nil {
+			t.Fatalf("%s: File error: %v", raw, err)
+		}
+		if f.Path != "go.mod" || f.Ref != "v1.0.0" || f.SHA != want || f.String() != "module example.org/m\n" {
+			t.Fatalf("%s: unexpected file %+v", raw, *f)
+		}
+
+		if _, err := fp.File("v1.0.0", "missing.txt"); !errors.Is(err, lightweigit.ErrNotFound) {
+			t.Fatalf("%s: expected ErrNotFound, got %v", raw, err)
+		}
+	}
+
+	p, _ := global.ParseOffline("https://github.com/o/r")
+	if _, err := p.(lightweigit.ProviderFileInterface).File("v1.0.0", "big.bin"); !errors.Is(err, lightweigit.ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestFile_Local(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "repo.git")
+	writeFile(t, filepath.Join(dir, "HEAD"), "ref: refs/heads/main\n")
+	if err := os.MkdirAll(filepath.Join(dir, "refs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	mod := writeLoose(t, dir, gitObjectObj{"blob", []byte("module example.org/m\n")})
+	version := writeLoose(t, dir, gitObjectObj{"blob", []byte("1.2.3\n")})
+	sub := writeLoose(t, dir, treeObj("100644", "VERSION", version))
+	root := writeLoose(t, dir, treeObj("100644", "go.mod", mod, "40000", "sub", sub))
+	commit := writeLoose(t, dir, gitObjectObj{"commit", []byte(fmt.Sprintf(
+		"tree %s\nauthor A <a@example.org> 1700000000 +0000\ncommitter A <a@example.org> 1700000000 +0000\n\nfirst\n", root))})
+	tag := writeLoose(t, dir, tagObj("v1.0.0", commit, 1700000100, "Release\n"))
+
+	writeFile(t, filepath.Join(dir, "packed-refs"), fmt.Sprintf("%s refs/heads/main\n%s refs/tags/v1.0.0\n", commit, tag))
+
+	obj, err := local.Parse("file://" + filepath.ToSlash(dir))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	for _, ref := range []string{"HEAD", "main", "v1.0.0", commit, tag} {
+		f, err := obj.File(ref, "sub/VERSION")
+		if err != nil {
+			t.Fatalf("%s: File error: %v", ref, err)
+		}
+		if f.String() != "1.2.3\n" || f.SHA != version {
+			t.Fatalf("%s: unexpected file %+v", ref, *f)
+		}
+	}
+
+	tg, err := obj.TagFind("v1.0.0")
+	if err != nil {
+		t.Fatalf("TagFind error: %v", err)
+	}
+	f, err := tg.(lightweigit.TagFileInterface).File("go.mod")
+	if err != nil {
+		t.Fatalf("tag File error: %v", err)
+	}
+	if f.Ref != "v1.0.0" || f.SHA != lightweigit.BlobSHA(f.Content) {
+		t.Fatalf("unexpected tag file %+v", *f)
+	}
+
+	for _, tc := range []struct{ ref, path string }{
+		{"main", "missing"},
+		{"main", "go.mod/x"},
+		{"main", "sub"},
+		{"nope", "go.mod"},
+	} {
+		if _, err := obj.File(tc.ref, tc.path); !errors.Is(err, lightweigit.ErrNotFound) {
+			t.Fatalf("%s %s: expected ErrNotFound, got %v", tc.ref, tc.path, err)
+		}
+	}
+}
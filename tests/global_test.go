@@ -2,6 +2,8 @@ package tests
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -24,12 +26,7 @@ func TestGlobal(t *testing.T) {
 	} {
 		obj, err := global.Parse(url)
 		if err != nil {
-			// global.Parse returns only the last provider's error, so the
-			// rate-limit sentinel may be lost along the chain; fall back to
-			// matching the status text.
-			msg := err.Error()
-			if errors.Is(err, lightweigit.ErrForbidden) || errors.Is(err, lightweigit.ErrTooManyRequests) ||
-				strings.Contains(msg, "403") || strings.Contains(msg, "429") || strings.Contains(msg, "rate limit") {
+			if errors.Is(err, lightweigit.ErrForbidden) || errors.Is(err, lightweigit.ErrTooManyRequests) {
 				t.Skipf("provider blocked the request: %v", err)
 			}
 			t.Error(err)
@@ -38,3 +35,60 @@ func TestGlobal(t *testing.T) {
 		}
 	}
 }
+
+func TestGlobal_ParseErrorListsEveryProvider(t *testing.T) {
+	recordServer(t, http.NotFound)
+
+	_, err := global.Parse("https://example.org/lonely")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	var perr *lightweigit.ParseErrorObj
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *ParseErrorObj, got %T: %v", err, err)
+	}
+
+	want := map[string]bool{"local": true, "bitbucket": true, "github": true, "gitlab": true, "gogsFamily": true}
+	got := make(map[string]error)
+	for _, a := range perr.Attempts {
+		if a.Err == nil {
+			t.Fatalf("%s: attempt without error", a.Provider)
+		}
+		if _, dup := got[a.Provider]; dup {
+			t.Fatalf("%s tried twice", a.Provider)
+		}
+		got[a.Provider] = a.Err
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected providers: %v", perr.Attempts)
+	}
+	for name := range want {
+		if _, ok := got[name]; !ok {
+			t.Fatalf("%s missing from the attempts: %v", name, perr.Attempts)
+		}
+	}
+	if !errors.Is(got["github"], lightweigit.ErrHostMismatch) {
+		t.Fatalf("github should reject the host by matcher: %v", got["github"])
+	}
+	if trace := perr.Trace(); !strings.Contains(trace, "gitlab: expected a path") {
+		t.Fatalf("trace lacks the gitlab reason:\n%s", trace)
+	}
+}
+
+func TestGlobal_ParseErrorIsSentinel(t *testing.T) {
+	perr := &lightweigit.ParseErrorObj{Raw: "x"}
+	perr.Add("a", errors.New("not mine"))
+	perr.Add("b", fmt.Errorf("metadata endpoint returned 429: %w", lightweigit.ErrTooManyRequests))
+
+	var err error = perr
+	if !errors.Is(err, lightweigit.ErrTooManyRequests) {
+		t.Fatal("ErrTooManyRequests not visible through ParseErrorObj")
+	}
+	if errors.Is(err, lightweigit.ErrForbidden) {
+		t.Fatal("unexpected ErrForbidden match")
+	}
+	if !errors.Is(fmt.Errorf("wrapped: %w", err), lightweigit.ErrTooManyRequests) {
+		t.Fatal("sentinel lost behind an outer wrap")
+	}
+}
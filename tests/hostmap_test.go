@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func pinHosts(t *testing.T, text string, hosts ...string) {
+	t.Helper()
+
+	if err := lightweigit.HostMap.Parse(text); err != nil {
+		t.Fatalf("HostMap.Parse error: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, h := range hosts {
+			lightweigit.HostMap.Remove(h)
+		}
+	})
+}
+
+// //
+
+func TestHostMap_Parse(t *testing.T) {
+	m := new(lightweigit.HostMapObj)
+	err := m.Parse("# pins\nGit.Example.org:8443 gitlab /gitlab/api/v4/ ; code.example.org gogsFamily:forgejo\n")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	pin, ok := m.Get("https://git.example.org/group/repo")
+	if !ok || pin.Provider != "gitlab" || pin.APIBase != "/gitlab/api/v4" {
+		t.Fatalf("unexpected pin: %+v, %v", pin, ok)
+	}
+	if pin, ok = m.Get("code.example.org"); !ok || pin.Provider != "gogsFamily" || pin.Kind != "forgejo" {
+		t.Fatalf("unexpected pin: %+v, %v", pin, ok)
+	}
+
+	for _, bad := range []string{"lonely.example.org", "a.example.org gitlab api/v4", "a b c d"} {
+		if err := new(lightweigit.HostMapObj).Parse(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+
+	if err := m.Parse("x.example.org gitlab\ny.example.org gitlab v4"); err == nil {
+		t.Fatal("expected error for a bad api base")
+	}
+	if _, ok := m.Get("x.example.org"); ok {
+		t.Fatal("partial host map applied")
+	}
+}
+
+func TestHostMap_LoadEnv(t *testing.T) {
+	t.Setenv(lightweigit.HostMapEnv, "env.example.org gitlab")
+
+	m := new(lightweigit.HostMapObj)
+	if err := m.LoadEnv(""); err != nil {
+		t.Fatalf("LoadEnv error: %v", err)
+	}
+	if _, ok := m.Get("env.example.org"); !ok {
+		t.Fatal("pin from the environment not loaded")
+	}
+	if err := m.LoadEnv("LIGHTWEIGIT_TEST_UNSET"); err != nil {
+		t.Fatalf("unset variable should not fail: %v", err)
+	}
+}
+
+func TestHostMap_PinnedGitLabSubPath(t *testing.T) {
+	paths := recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/gitlab/api/v4/projects/group%2Frepo":
+			w.Write([]byte(`{"id":7}`))
+		case "/gitlab/api/v4/projects/7/repository/tags":
+			w.Write([]byte(`[{"name":"v2"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	pinHosts(t, "git.example.org gitlab /gitlab/api/v4", "git.example.org")
+
+	obj, err := global.Parse("https://git.example.org/gitlab/group/repo/-/tags")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(paths()) != 0 {
+		t.Fatalf("pinned parse sent requests: %v", paths())
+	}
+	if obj.Type() != "gitlab" || obj.String() != "group/repo" {
+		t.Fatalf("unexpected result: %s %s", obj.Type(), obj.String())
+	}
+	if obj.URL().String() != "https://git.example.org/gitlab/group/repo" {
+		t.Fatalf("unexpected URL: %s", obj.URL())
+	}
+
+	tag, err := obj.TagLatest()
+	if err != nil {
+		t.Fatalf("TagLatest error: %v", err)
+	}
+	if tag.ZIP().String() != "https://git.example.org/gitlab/api/v4/projects/7/repository/archive.zip?sha=v2" {
+		t.Fatalf("unexpected ZIP: %s", tag.ZIP())
+	}
+	want := []string{"/gitlab/api/v4/projects/group%2Frepo", "/gitlab/api/v4/projects/7/repository/tags"}
+	if got := paths(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("unexpected requests:\n got=%v\nwant=%v", got, want)
+	}
+
+	back, err := global.UnmarshalTag(tag.Marshal())
+	if err != nil {
+		t.Fatalf("UnmarshalTag error: %v", err)
+	}
+	if back.ZIP().String() != tag.ZIP().String() {
+		t.Fatalf("API base lost in marshal: %s", back.ZIP())
+	}
+}
+
+func TestHostMap_PinnedGogsFamily(t *testing.T) {
+	paths := recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/forge/api/v1/repos/owner/repo/tags":
+			w.Write([]byte(`[{"name":"v1"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	pinHosts(t, "code.example.org gogsFamily:forgejo /forge/api/v1", "code.example.org")
+
+	obj, err := global.Parse("https://code.example.org/forge/owner/repo/src/branch/main")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(paths()) != 0 {
+		t.Fatalf("pinned parse sent requests: %v", paths())
+	}
+	if obj.Type() != "forgejo" || obj.String() != "owner/repo" {
+		t.Fatalf("unexpected result: %s %s", obj.Type(), obj.String())
+	}
+	if obj.URL().String() != "https://code.example.org/forge/owner/repo" {
+		t.Fatalf("unexpected URL: %s", obj.URL())
+	}
+	if _, err := obj.TagLatest(); err != nil {
+		t.Fatalf("TagLatest error: %v", err)
+	}
+}
+
+func TestHostMap_PinnedUnsupported(t *testing.T) {
+	recordServer(t, http.NotFound)
+	pinHosts(t, "github.com github; nowhere.example.org missing", "github.com", "nowhere.example.org")
+
+	if _, err := global.Parse("https://github.com/owner/repo"); err == nil {
+		t.Fatal("expected error for a provider without pinning support")
+	}
+	if _, err := global.Parse("https://nowhere.example.org/owner/repo"); err == nil {
+		t.Fatal("expected error for an unregistered provider")
+	}
+}
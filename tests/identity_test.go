@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/snapshot"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func TestIdentity_SpellingsOfOneRepo(t *testing.T) {
+	for key, spellings := range map[string][]string{
+		"github:github.com/o/r": {
+			"git@github.com:o/r.git",
+			"https://www.github.com/o/r/tree/main",
+			"github.com/O/R",
+		},
+		"bitbucket:bitbucket.org/ws/repo": {
+			"git@bitbucket.org:ws/repo.git",
+			"https://www.bitbucket.org/WS/Repo/src/main/",
+		},
+		"gitlab:gitlab.com/group/sub/repo": {
+			"git@gitlab.com:group/sub/repo.git",
+			"https://www.gitlab.com:443/Group/Sub/Repo/-/tags",
+		},
+		"gogsFamily:codeberg.org/owner/repo": {
+			"git@codeberg.org:owner/repo.git",
+			"https://Codeberg.org:3000/Owner/Repo",
+		},
+	} {
+		var first lightweigit.ProviderInterface
+		for _, raw := range spellings {
+			p, err := global.ParseOffline(raw)
+			if err != nil {
+				t.Fatalf("%s: %v", raw, err)
+			}
+			if got := lightweigit.Identity(p).Key(); got != key {
+				t.Fatalf("%s: key %s, want %s", raw, got, key)
+			}
+			if first == nil {
+				first = p
+				continue
+			}
+			if !lightweigit.SameRepo(first, p) {
+				t.Fatalf("%s: not the same repository as %s", raw, spellings[0])
+			}
+		}
+	}
+}
+
+func TestIdentity_DifferentRepos(t *testing.T) {
+	a, _ := global.ParseOffline("https://github.com/o/r")
+	b, _ := global.ParseOffline("https://gitlab.com/o/r")
+	c, _ := global.ParseOffline("https://github.com/o/r2")
+	if lightweigit.SameRepo(a, b) || lightweigit.SameRepo(a, c) || lightweigit.SameRepo(a, nil) {
+		t.Fatal("distinct repositories compare equal")
+	}
+
+	// Gitea and Forgejo guesses for one host stay one repository.
+	d, _ := global.ParseOffline("https://gitea.example.org/o/r")
+	e, _ := global.ParseOffline("git@gitea.example.org:o/r.git")
+	if !lightweigit.SameRepo(d, e) {
+		t.Fatalf("%s != %s", lightweigit.Identity(d), lightweigit.Identity(e))
+	}
+}
+
+func TestIdentity_SnapshotKeepsSource(t *testing.T) {
+	snapshotServer(t)
+
+	obj := githubObj(t)
+	snap, err := snapshot.Take(context.Background(), obj)
+	if err != nil {
+		t.Fatalf("Take error: %v", err)
+	}
+	loaded, err := snapshot.Unmarshal(snap.Marshal())
+	if err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !lightweigit.SameRepo(obj, loaded) {
+		t.Fatalf("%s != %s", lightweigit.Identity(obj), lightweigit.Identity(loaded))
+	}
+}
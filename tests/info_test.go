@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/local"
+)
+
+// // // // // // // // // // // // // // // //
+
+// //
+
+func TestInfo_Providers(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/repos/o/r":
+			w.Write([]byte(`{"full_name":"o/r","description":"GitHub repo","default_branch":"main","archived":false,"disabled":true,
+				"private":false,"visibility":"public","stargazers_count":42,"pushed_at":"2024-05-01T10:00:00Z",
+				"parent":{"html_url":"https://github.com/up/r"},"license":{"spdx_id":"NOASSERTION"}}`))
+		case "/api/v4/projects/group%2Frepo":
+			w.Write([]byte(`{"id":7}`))
+		case "/api/v4/projects/7":
+			if r.URL.Query().Get("license") != "true" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(`{"path_with_namespace":"group/repo","description":"GitLab repo","default_branch":"develop","archived":true,
+				"visibility":"internal","star_count":3,"last_activity_at":"2024-05-02T10:00:00.000Z",
+				"forked_from_project":{"web_url":"https://gitlab.com/up/repo"},"license":{"key":"apache-2.0"}}`))
+		case "/2.0/repositories/ws/repo":
+			w.Write([]byte(`{"full_name":"ws/repo","description":"Bitbucket repo","is_private":true,"updated_on":"2024-05-03T10:00:00.000000+00:00",
+				"mainbranch":{"name":"master"}}`))
+		case "/api/v1/repos/o/r":
+			w.Write([]byte(`{"full_name":"o/r","description":"Gitea repo","default_branch":"main","private":true,"internal":false,
+				"stars_count":5,"updated_at":"2024-05-04T10:00:00Z","licenses":["MIT"],"parent":null}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	for _, tc := range []struct {
+		raw  string
+		want lightweigit.RepositoryInfoObj
+	}{
+		{"https://github.com/o/r", lightweigit.RepositoryInfoObj{
+			Name: "o/r", Description: "GitHub repo", DefaultBranch: "main", Archived: true,
+			Parent: "https://github.com/up/r", Visibility: "public", Stars: 42,
+			Pushed: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		}},
+		{"https://gitlab.com/group/repo", lightweigit.RepositoryInfoObj{
+			Name: "group/repo", Description: "GitLab repo", DefaultBranch: "develop", Archived: true,
+			Parent: "https://gitlab.com/up/repo", Visibility: "internal", License: "Apache-2.0", Stars: 3,
+			Pushed: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC),
+		}},
+		{"https://bitbucket.org/ws/repo", lightweigit.RepositoryInfoObj{
+			Name: "ws/repo", Description: "Bitbucket repo", DefaultBranch: "master", Visibility: "private",
+			Pushed: time.Date(2024, 5, 3, 10, 0, 0, 0, time.UTC),
+		}},
+		{"https://gitea.com/o/r", lightweigit.RepositoryInfoObj{
+			Name: "o/r", Description: "Gitea repo", DefaultBranch: "main", Visibility: "private", License: "MIT", Stars: 5,
+			Pushed: time.Date(2024, 5, 4, 10, 0, 0, 0, time.UTC),
+		}},
+	} {
+		got, err := offlineAs[lightweigit.ProviderInfoInterface](t, tc.raw).RepositoryInfo()
+		if err != nil {
+			t.Fatalf("%s: RepositoryInfo error: %v", tc.raw, err)
+		}
+		if !got.Pushed.Equal(tc.want.Pushed) {
+			t.Fatalf("%s: pushed %v, want %v", tc.raw, got.Pushed, tc.want.Pushed)
+		}
+		got.Pushed = tc.want.Pushed
+		if *got != tc.want {
+			t.Fatalf("%s:\n got %+v\nwant %+v", tc.raw, *got, tc.want)
+		}
+		if got.IsFork() != (tc.want.Parent != "") {
+			t.Fatalf("%s: IsFork %v", tc.raw, got.IsFork())
+		}
+	}
+}
+
+func TestInfo_Local(t *testing.T) {
+	dir := localRepo(t)
+	writeFile(t, filepath.Join(dir, "description"), "Mirror of things\n")
+
+	obj, err := local.Parse("file://" + filepath.ToSlash(dir))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	info, err := obj.RepositoryInfo()
+	if err != nil {
+		t.Fatalf("RepositoryInfo error: %v", err)
+	}
+	if info.Name != "mirror" || info.Description != "Mirror of things" || info.DefaultBranch != "main" {
+		t.Fatalf("unexpected info: %+v", *info)
+	}
+}
+
+func TestInfo_SPDX(t *testing.T) {
+	for in, want := range map[string]string{
+		"mit":          "MIT",
+		"Apache-2.0":   "Apache-2.0",
+		"NOASSERTION":  "",
+		"other":        "",
+		"LicenseRef-x": "LicenseRef-x",
+	} {
+		if got := lightweigit.SPDX(in); got != want {
+			t.Fatalf("SPDX(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/local"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+type gitObjectObj struct {
+	typ  string
+	body []byte
+}
+
+func (o gitObjectObj) sha() string {
+	h := sha1.Sum(append([]byte(fmt.Sprintf("%s %d\x00", o.typ, len(o.body))), o.body...))
+	return hex.EncodeToString(h[:])
+}
+
+func zlibBytes(t *testing.T, b []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(b)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeLoose(t *testing.T, gitDir string, o gitObjectObj) string {
+	t.Helper()
+
+	sha := o.sha()
+	raw := append([]byte(fmt.Sprintf("%s %d\x00", o.typ, len(o.body))), o.body...)
+	writeFile(t, filepath.Join(gitDir, "objects", sha[:2], sha[2:]), string(zlibBytes(t, raw)))
+	return sha
+}
+
+// packEntryObj is an object's name and its offset in a pack.
+type packEntryObj struct {
+	sha []byte
+	off uint32
+}
+
+// packHeader is a pack object header: the kind and the size varint.
+func packHeader(kind byte, size int) []byte {
+	b := []byte{kind<<4 | byte(size&15)}
+	size >>= 4
+	for size > 0 {
+		b[len(b)-1] |= 0x80
+		b = append(b, byte(size&0x7f))
+		size >>= 7
+	}
+	return b
+}
+
+// writePack stores objs in one pack with a version 2 index. The last
+// object is stored as an offset delta against the first one.
+func writePack(t *testing.T, gitDir string, objs ...gitObjectObj) {
+	t.Helper()
+
+	packTypes := map[string]byte{"commit": 1, "tree": 2, "blob": 3, "tag": 4}
+	header := packHeader
+	varint := func(n int) []byte {
+		var b []byte
+		for {
+			c := byte(n & 0x7f)
+			n >>= 7
+			if n > 0 {
+				b = append(b, c|0x80)
+				continue
+			}
+			return append(b, c)
+		}
+	}
+
+	var pack bytes.Buffer
+	pack.WriteString("PACK")
+	binary.Write(&pack, binary.BigEndian, uint32(2))
+	binary.Write(&pack, binary.BigEndian, uint32(len(objs)))
+
+	var entries []packEntryObj
+	for i, o := range objs {
+		sha, _ := hex.DecodeString(o.sha())
+		off := pack.Len()
+		entries = append(entries, packEntryObj{sha, uint32(off)})
+
+		if i > 0 && i == len(objs)-1 {
+			// Delta: copy nothing from the base, insert the whole body.
+			var delta []byte
+			delta = append(delta, varint(len(objs[0].body))...)
+			delta = append(delta, varint(len(o.body))...)
+			for rest := o.body; len(rest) > 0; {
+				n := len(rest)
+				if n > 127 {
+					n = 127
+				}
+				delta = append(delta, byte(n))
+				delta = append(delta, rest[:n]...)
+				rest = rest[n:]
+			}
+			pack.Write(header(6, len(delta)))
+			dist := off - int(entries[0].off)
+			// Offset encoding of pack files: big-endian, each continuation +1.
+			enc := []byte{byte(dist & 0x7f)}
+			for dist >>= 7; dist > 0; dist >>= 7 {
+				dist--
+				enc = append([]byte{0x80 | byte(dist&0x7f)}, enc...)
+			}
+			pack.Write(enc)
+			pack.Write(zlibBytes(t, delta))
+			continue
+		}
+
+		pack.Write(header(packTypes[o.typ], len(o.body)))
+		pack.Write(zlibBytes(t, o.body))
+	}
+	writePackFiles(t, gitDir, "pack-test", &pack, entries)
+}
+
+// writePackFiles ends pack with its checksum and writes it as name.pack
+// with a version 2 index of entries.
+func writePackFiles(t *testing.T, gitDir, name string, pack *bytes.Buffer, entries []packEntryObj) {
+	t.Helper()
+
+	sum := sha1.Sum(pack.Bytes())
+	pack.Write(sum[:])
+
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].sha, entries[j].sha) < 0 })
+	var idx bytes.Buffer
+	idx.Write([]byte{0xff, 't', 'O', 'c'})
+	binary.Write(&idx, binary.BigEndian, uint32(2))
+	for b := 0; b < 256; b++ {
+		n := 0
+		for _, e := range entries {
+			if int(e.sha[0]) <= b {
+				n++
+			}
+		}
+		binary.Write(&idx, binary.BigEndian, uint32(n))
+	}
+	for _, e := range entries {
+		idx.Write(e.sha)
+	}
+	for range entries {
+		binary.Write(&idx, binary.BigEndian, uint32(0))
+	}
+	for _, e := range entries {
+		binary.Write(&idx, binary.BigEndian, e.off)
+	}
+	idx.Write(sum[:])
+	isum := sha1.Sum(idx.Bytes())
+	idx.Write(isum[:])
+
+	writeFile(t, filepath.Join(gitDir, "objects", "pack", name+".pack"), pack.String())
+	writeFile(t, filepath.Join(gitDir, "objects", "pack", name+".idx"), idx.String())
+}
+
+func commitObj(msg string, ts int64) gitObjectObj {
+	return gitObjectObj{"commit", []byte(fmt.Sprintf(
+		"tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor A <a@example.org> %d +0000\ncommitter A <a@example.org> %d +0000\n\n%s\n", ts, ts, msg))}
+}
+
+func tagObj(name, commit string, ts int64, msg string) gitObjectObj {
+	return gitObjectObj{"tag", []byte(fmt.Sprintf(
+		"object %s\ntype commit\ntag %s\ntagger Tess Tagger <tess@example.org> %d +0200\n\n%s", commit, name, ts, msg))}
+}
+
+// localRepo lays out a bare repository with:
+//   - v1.0.0: lightweight, loose ref, loose commit (oldest)
+//   - v1.1.0: annotated, loose ref and loose tag object
+//   - v2.0.0: annotated, packed-refs with a peeled line, tag object packed
+//     as a delta against an unreferenced tag object
+func localRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "mirror.git")
+	writeFile(t, filepath.Join(dir, "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, "config"), "[core]\n\tbare = true\n")
+
+	c1 := writeLoose(t, dir, commitObj("one", 1600000000))
+	c2 := writeLoose(t, dir, commitObj("two", 1650000000))
+	c3 := commitObj("three", 1700000000)
+
+	writeFile(t, filepath.Join(dir, "refs", "tags", "v1.0.0"), c1+"\n")
+	t11 := writeLoose(t, dir, tagObj("v1.1.0", c2, 1650000100, "Release 1.1\n"))
+	writeFile(t, filepath.Join(dir, "refs", "tags", "v1.1.0"), t11+"\n")
+
+	t2 := tagObj("v2.0.0", c3.sha(), 1700000100, "Release 2.0\n\nBig one.\n")
+	// Git only deltifies objects of one type: the base is another tag.
+	writePack(t, dir, tagObj("v2.0.0-rc1", c3.sha(), 1690000000, "RC\n"), c3, t2)
+	writeFile(t, filepath.Join(dir, "packed-refs"), fmt.Sprintf(
+		"# pack-refs with: peeled fully-peeled sorted \n%s refs/heads/main\n%s refs/tags/v1.1.0\n^%s\n%s refs/tags/v2.0.0\n^%s\n",
+		c3.sha(), "0000000000000000000000000000000000000000", c2, t2.sha(), c3.sha()))
+	return dir
+}
+
+// //
+
+func TestLocal_Tags(t *testing.T) {
+	dir := localRepo(t)
+	paths := recordServer(t, http.NotFound)
+
+	p, err := global.Parse("file://" + filepath.ToSlash(dir))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if p.Type() != "local" || p.String() != dir {
+		t.Fatalf("unexpected provider: %s %s", p.Type(), p.String())
+	}
+
+	latest, err := p.TagLatest()
+	if err != nil {
+		t.Fatalf("TagLatest error: %v", err)
+	}
+	tag := latest.(*local.TagObj)
+	if tag.String() != "v2.0.0" || !tag.Annotated() || tag.Message() != "Release 2.0\n\nBig one.\n" {
+		t.Fatalf("unexpected latest tag: %s %v %q", tag, tag.Annotated(), tag.Message())
+	}
+	if tag.Tagger() != "Tess Tagger <tess@example.org>" || tag.Time().Unix() != 1700000100 || tag.Commit() != commitObj("three", 1700000000).sha() {
+		t.Fatalf("unexpected tag details: %q %v %s", tag.Tagger(), tag.Time(), tag.Commit())
+	}
+	if _, off := tag.Time().Zone(); off != 2*3600 {
+		t.Fatalf("tagger zone lost: %v", tag.Time())
+	}
+
+	// The loose ref wins over the stale packed entry.
+	found, err := p.TagFind("v1.1.0")
+	if err != nil {
+		t.Fatalf("TagFind error: %v", err)
+	}
+	if ft := found.(*local.TagObj); !ft.Annotated() || ft.Message() != "Release 1.1\n" || ft.Commit() == "" {
+		t.Fatalf("unexpected v1.1.0: %v %q %s", ft.Annotated(), ft.Message(), ft.Commit())
+	}
+	found, _ = p.TagFind("v1.0.0")
+	if ft := found.(*local.TagObj); ft.Annotated() || ft.Time().Unix() != 1600000000 {
+		t.Fatalf("unexpected v1.0.0: %v %v", ft.Annotated(), ft.Time())
+	}
+	if _, err := p.TagFind("v9"); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+
+	out := make(chan lightweigit.ProviderTagInterface, 8)
+	if err := p.TagsStream(context.Background(), out, 0); err != nil {
+		t.Fatalf("TagsStream error: %v", err)
+	}
+	close(out)
+	var names []string
+	for tag := range out {
+		names = append(names, tag.String())
+	}
+	if fmt.Sprint(names) != "[v2.0.0 v1.1.0 v1.0.0]" {
+		t.Fatalf("unexpected order: %v", names)
+	}
+
+	if _, err := p.ReleaseLatest(); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for releases, got: %v", err)
+	}
+	if got := paths(); len(got) != 0 {
+		t.Fatalf("local provider sent requests: %v", got)
+	}
+}
+
+func TestLocal_CheckoutAndMarshal(t *testing.T) {
+	mirror := localRepo(t)
+
+	// A checkout whose .git points at the mirror, as a worktree would.
+	work := t.TempDir()
+	writeFile(t, filepath.Join(work, ".git"), "gitdir: "+mirror+"\n")
+
+	obj, err := local.Parse("file://" + filepath.ToSlash(work))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	tag, err := obj.TagFind("v2.0.0")
+	if err != nil {
+		t.Fatalf("TagFind error: %v", err)
+	}
+
+	back, err := global.UnmarshalTag(tag.Marshal())
+	if err != nil {
+		t.Fatalf("UnmarshalTag error: %v", err)
+	}
+	bt := back.(*local.TagObj)
+	if bt.URL().String() != tag.URL().String() || bt.Message() != "Release 2.0\n\nBig one.\n" || !bt.Time().Equal(tag.(*local.TagObj).Time()) {
+		t.Fatalf("round trip mismatch: %s %q %v", bt.URL(), bt.Message(), bt.Time())
+	}
+
+	if _, err := local.Parse("file://" + filepath.ToSlash(t.TempDir())); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a plain directory, got: %v", err)
+	}
+	if _, err := local.Parse("https://github.com/o/r"); err == nil {
+		t.Fatal("expected error for a non-file URL")
+	}
+}
+
+func TestLocal_PackIndexReadOnce(t *testing.T) {
+	dir := localRepo(t)
+	p, err := local.Parse("file://" + filepath.ToSlash(dir))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if _, err := p.TagFind("v2.0.0"); err != nil {
+		t.Fatalf("TagFind error: %v", err)
+	}
+
+	// A handle keeps the index it parsed; only a new one reads the file.
+	writeFile(t, filepath.Join(dir, "objects", "pack", "pack-test.idx"), "garbage")
+	if tag, err := p.TagFind("v2.0.0"); err != nil || !tag.(*local.TagObj).Annotated() {
+		t.Fatalf("the parsed index must be reused: %v", err)
+	}
+	fresh, _ := local.Parse("file://" + filepath.ToSlash(dir))
+	if tag, _ := fresh.TagFind("v2.0.0"); tag != nil && tag.(*local.TagObj).Annotated() {
+		t.Fatal("a new handle must read the index again")
+	}
+}
+
+func TestLocal_PackLimits(t *testing.T) {
+	dir := localRepo(t)
+	p, err := local.Parse("file://" + filepath.ToSlash(dir))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var fp lightweigit.ProviderFileInterface = p
+
+	// Two packs whose ref deltas name each other as their base.
+	a, b := bytes.Repeat([]byte{0xaa}, 20), bytes.Repeat([]byte{0xbb}, 20)
+	for _, o := range []struct {
+		name      string
+		sha, base []byte
+	}{{"pack-a", a, b}, {"pack-b", b, a}} {
+		var pack bytes.Buffer
+		pack.WriteString("PACK")
+		binary.Write(&pack, binary.BigEndian, uint32(2))
+		binary.Write(&pack, binary.BigEndian, uint32(1))
+		delta := []byte{0, 1, 1, 'x'}
+		pack.Write(packHeader(7, len(delta)))
+		pack.Write(o.base)
+		pack.Write(zlibBytes(t, delta))
+		writePackFiles(t, dir, o.name, &pack, []packEntryObj{{o.sha, 12}})
+	}
+	if _, err := fp.File(hex.EncodeToString(a), "x"); err == nil || !strings.Contains(err.Error(), "too deep") {
+		t.Fatalf("a delta cycle across packs must end at the depth limit, got %v", err)
+	}
+
+	// A packed blob one byte over the limit is refused, not cut short.
+	big := gitObjectObj{"blob", make([]byte, 16<<20+1)}
+	var pack bytes.Buffer
+	pack.WriteString("PACK")
+	binary.Write(&pack, binary.BigEndian, uint32(2))
+	binary.Write(&pack, binary.BigEndian, uint32(1))
+	pack.Write(packHeader(3, len(big.body)))
+	pack.Write(zlibBytes(t, big.body))
+	bigSHA, _ := hex.DecodeString(big.sha())
+	writePackFiles(t, dir, "pack-big", &pack, []packEntryObj{{bigSHA, 12}})
+
+	tree := writeLoose(t, dir, gitObjectObj{"tree", append([]byte("100644 big\x00"), bigSHA...)})
+	commit := writeLoose(t, dir, gitObjectObj{"commit", []byte("tree " + tree + "\nauthor A <a@example.org> 1 +0000\ncommitter A <a@example.org> 1 +0000\n\nbig\n")})
+	if _, err := fp.File(commit, "big"); !errors.Is(err, lightweigit.ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader/markdown"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func TestMarkdown_HTML(t *testing.T) {
+	for _, tc := range []struct {
+		md, want string
+	}{
+		{"# Title #\n\nSetext\n---", "<h1>Title</h1>\n<h2>Setext</h2>\n"},
+		{"*em* **strong** ***both*** ~~gone~~ `a<b` snake_case_name", "<p><em>em</em> <strong>strong</strong> <strong><em>both</em></strong> <del>gone</del> <code>a&lt;b</code> snake_case_name</p>\n"},
+		{"*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>\n"},
+		{"line one  \nline two\\\nthree", "<p>line one<br>\nline two<br>\nthree</p>\n"},
+		{"- one\n- two\n  - nested\n- three", "<ul>\n<li>one</li>\n<li>two\n<ul>\n<li>nested</li>\n</ul>\n</li>\n<li>three</li>\n</ul>\n"},
+		{"3. c\n\n4. d", "<ol start=\"3\">\n<li>\n<p>c</p>\n</li>\n<li>\n<p>d</p>\n</li>\n</ol>\n"},
+		{"```go\nif a < b {}\n```", "<pre><code class=\"language-go\">if a &lt; b {}\n</code></pre>\n"},
+		{"    indented\n\n> quoted\nlazy", "<pre><code>indented\n</code></pre>\n<blockquote>\n<p>quoted\nlazy</p>\n</blockquote>\n"},
+		{"| a | b |\n|:--|--:|\n| `x\\|y` | 2 |", "<table>\n<thead>\n<tr>\n<th align=\"left\">a</th>\n<th align=\"right\">b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td align=\"left\"><code>x|y</code></td>\n<td align=\"right\">2</td>\n</tr>\n</tbody>\n</table>\n"},
+		{"<details>\n<summary>More</summary>\n\nhidden\n</details>", "<details>\n<summary>More</summary>\n<p>hidden</p>\n</details>\n"},
+		{"[text][id] and [id]\n\n[id]: https://example.org \"T\"", "<p><a href=\"https://example.org\" title=\"T\" rel=\"nofollow noopener noreferrer\">text</a> and <a href=\"https://example.org\" title=\"T\" rel=\"nofollow noopener noreferrer\">id</a></p>\n"},
+		{"see https://example.org/a_(b)). <!-- hidden -->done", "<p>see <a href=\"https://example.org/a_(b)\" rel=\"nofollow noopener noreferrer\">https://example.org/a_(b)</a>). done</p>\n"},
+	} {
+		if got := markdown.HTML(tc.md, nil); got != tc.want {
+			t.Fatalf("%q:\ngot  %q\nwant %q", tc.md, got, tc.want)
+		}
+	}
+}
+
+func TestMarkdown_Sanitize(t *testing.T) {
+	for _, md := range []string{
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		`<a href="javascript:alert(1)">x</a>`,
+		"[x](javascript:alert(1))",
+		"[x](JaVaScRiPt:alert(1))",
+		"[x](&#106;avascript:alert(1))",
+		"[x](<javascript:alert(1)>)",
+		"<javascript:alert(1)>",
+		"![x](data:image/svg+xml;base64,PHN2Zz4=)",
+		"[x](https://example.org/\"onmouseover=\"alert(1))",
+		"<b>unclosed <i>tags",
+	} {
+		got := markdown.HTML(md, nil)
+		for _, bad := range []string{"<script", "<img src=x", "href=\"javascript", "href=\"data", "src=\"data", "\"onmouseover", "<a href=\"java"} {
+			if strings.Contains(strings.ToLower(got), strings.ToLower(bad)) {
+				t.Fatalf("%q rendered unsafe %q", md, got)
+			}
+		}
+		if strings.Count(got, "<b>") != strings.Count(got, "</b>") || strings.Count(got, "<i>") != strings.Count(got, "</i>") {
+			t.Fatalf("%q left tags open: %q", md, got)
+		}
+	}
+}
+
+func TestMarkdown_Links(t *testing.T) {
+	const md = "Thanks @alice for #12 (not user@example.org or @scope/pkg).\n\nSee [docs](../docs/guide.md#setup), [pr](/o/r/pull/3) and [anchor](#top)."
+
+	for _, tc := range []struct {
+		url  string
+		want []string
+	}{
+		{"https://github.com/o/r", []string{
+			`href="https://github.com/alice"`,
+			`href="https://github.com/o/r/issues/12"`,
+			`href="https://github.com/o/r/blob/v1.0.0/docs/guide.md#setup"`,
+			`href="https://github.com/o/r/pull/3"`,
+		}},
+		{"https://gitlab.com/group/repo", []string{
+			`href="https://gitlab.com/alice"`,
+			`href="https://gitlab.com/group/repo/-/issues/12"`,
+			`href="https://gitlab.com/group/repo/-/blob/v1.0.0/docs/guide.md#setup"`,
+		}},
+		{"https://bitbucket.org/ws/repo", []string{
+			`href="https://bitbucket.org/alice"`,
+			`href="https://bitbucket.org/ws/repo/issues/12"`,
+			`href="https://bitbucket.org/ws/repo/src/v1.0.0/docs/guide.md#setup"`,
+		}},
+		{"https://gitea.com/o/r", []string{
+			`href="https://gitea.com/alice"`,
+			`href="https://gitea.com/o/r/issues/12"`,
+			`href="https://gitea.com/o/r/src/v1.0.0/docs/guide.md#setup"`,
+		}},
+	} {
+		p, err := global.ParseOffline(tc.url)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.url, err)
+		}
+
+		got := markdown.HTML(md, markdown.NewLinker(p, "v1.0.0"))
+		for _, want := range append(tc.want, `href="#top"`, "user@example.org", "@scope/pkg") {
+			if !strings.Contains(got, want) {
+				t.Fatalf("%s: %q missing in\n%s", tc.url, want, got)
+			}
+		}
+		if strings.Count(got, "<a ") != 5 {
+			t.Fatalf("%s: expected 5 links in\n%s", tc.url, got)
+		}
+	}
+
+	if got := markdown.HTML("#12 @alice", nil); got != "<p>#12 @alice</p>\n" {
+		t.Fatalf("references linked without a linker: %q", got)
+	}
+}
+
+func TestMarkdown_Text(t *testing.T) {
+	p, _ := global.ParseOffline("https://github.com/o/r")
+	md := "# Release\n\n## What's Changed\n\n* **api:** new [endpoint](docs/api.md) by @alice in #4\n  - detail\n* fix <b>crash</b>\n\n```sh\ngo get x\n```\n\n> note\n\n| Name | Size |\n|------|-----:|\n| a | 10 |\n| long name | 2 |\n\n![logo](logo.png)\n"
+	want := "Release\n=======\n\nWhat's Changed\n--------------\n\n- api: new endpoint (https://github.com/o/r/blob/v2/docs/api.md) by @alice in #4\n  - detail\n- fix crash\n\n    go get x\n\n> note\n\nName      | Size\n----------+-----\na         |   10\nlong name |    2\n\nlogo\n"
+
+	if got := markdown.Text(md, markdown.NewLinker(p, "v2")); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,232 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/gitlab"
+	"github.com/voluminor/lightweigit-loader/gogsFamily"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+// recordServer answers every request via h and remembers the escaped paths.
+func recordServer(t *testing.T, h http.HandlerFunc) func() []string {
+	t.Helper()
+
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.EscapedPath())
+		mu.Unlock()
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	swapHTTPClient(t, srv)
+
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+// offlineAs parses raw with ParseOffline and asserts the provider
+// implements I, one of the optional capability interfaces.
+func offlineAs[I any](t *testing.T, raw string) I {
+	t.Helper()
+
+	p, err := global.ParseOffline(raw)
+	if err != nil {
+		t.Fatalf("%s: %v", raw, err)
+	}
+	i, ok := p.(I)
+	if !ok {
+		t.Fatalf("%T does not implement %v", p, reflect.TypeOf((*I)(nil)).Elem())
+	}
+	return i
+}
+
+// //
+
+func TestParseOffline_NoRequests(t *testing.T) {
+	paths := recordServer(t, http.NotFound)
+
+	for _, tc := range []struct {
+		raw, kind, name string
+	}{
+		{"https://github.com/owner/repo/commits/v0.1.0", "github", "owner/repo"},
+		{"git@bitbucket.org:ws/repo.git", "bitbucket", "ws/repo"},
+		{"https://gitlab.com/group/sub/repo/-/tags", "gitlab", "group/sub/repo"},
+		{"git@gitlab.example.org:group/repo.git", "gitlab", "group/repo"},
+		{"https://gitea.com/gitea/util/src/branch/main/file.go", "gitea", "gitea/util"},
+		{"https://codeberg.org/forgejo/forgejo", "forgejo", "forgejo/forgejo"},
+		{"https://git.example.org/owner/repo", "unknown", "owner/repo"},
+	} {
+		obj, err := global.ParseOffline(tc.raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.raw, err)
+		}
+		if obj.Type() != tc.kind || obj.String() != tc.name {
+			t.Fatalf("%s: got %s %s, want %s %s", tc.raw, obj.Type(), obj.String(), tc.kind, tc.name)
+		}
+	}
+
+	if got := paths(); len(got) != 0 {
+		t.Fatalf("offline parse sent requests: %v", got)
+	}
+}
+
+func TestParseOffline_GitLabResolvesOnFirstUse(t *testing.T) {
+	paths := recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Frepo":
+			w.Write([]byte(`{"id":7}`))
+		case "/api/v4/projects/7/repository/tags":
+			w.Write([]byte(`[{"name":"v2"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	obj, err := gitlab.ParseOffline("https://gitlab.com/group/repo")
+	if err != nil {
+		t.Fatalf("ParseOffline error: %v", err)
+	}
+	if len(paths()) != 0 {
+		t.Fatalf("ParseOffline sent requests: %v", paths())
+	}
+
+	tag, err := obj.TagLatest()
+	if err != nil {
+		t.Fatalf("TagLatest error: %v", err)
+	}
+	if tag.String() != "v2" {
+		t.Fatalf("unexpected tag: %s", tag)
+	}
+	if tag.ZIP().String() != "https://gitlab.com/api/v4/projects/7/repository/archive.zip?sha=v2" {
+		t.Fatalf("project ID not applied: %s", tag.ZIP())
+	}
+
+	if _, err := obj.TagLatest(); err != nil {
+		t.Fatalf("TagLatest error: %v", err)
+	}
+	want := []string{"/api/v4/projects/group%2Frepo", "/api/v4/projects/7/repository/tags", "/api/v4/projects/7/repository/tags"}
+	if got := paths(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("unexpected requests:\n got=%v\nwant=%v", got, want)
+	}
+}
+
+func TestParseOffline_GitLabRejectsUnnamedHost(t *testing.T) {
+	if _, err := gitlab.ParseOffline("https://git.example.org/group/repo"); err == nil {
+		t.Fatal("expected error for a host that does not name itself GitLab")
+	}
+}
+
+func TestParseOffline_GogsUnconfirmed(t *testing.T) {
+	recordServer(t, http.NotFound)
+
+	obj, err := gogsFamily.ParseOffline("https://git.example.org/owner/repo")
+	if err != nil {
+		t.Fatalf("ParseOffline error: %v", err)
+	}
+	if err := obj.Resolve(context.Background()); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestParseOffline_ResolveExplicit(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/version":
+			w.Write([]byte(`{"version":"1.22.0"}`))
+		case "/api/v1/repos/owner/repo":
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	obj, err := global.ParseOffline("https://git.example.org/sub/owner/repo")
+	if err != nil {
+		t.Fatalf("ParseOffline error: %v", err)
+	}
+	if obj.String() != "sub/owner" {
+		t.Fatalf("unexpected offline guess: %s", obj.String())
+	}
+
+	r, ok := obj.(lightweigit.ProviderResolverInterface)
+	if !ok {
+		t.Fatalf("%T does not implement ProviderResolverInterface", obj)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.Resolve(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+	if err := r.Resolve(context.Background()); err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if obj.Type() != "gitea" || obj.String() != "owner/repo" {
+		t.Fatalf("unexpected resolve result: %s %s", obj.Type(), obj.String())
+	}
+}
+
+func TestParseOffline_ConcurrentFirstUse(t *testing.T) {
+	paths := recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Frepo":
+			w.Write([]byte(`{"id":7}`))
+		case "/api/v1/version":
+			w.Write([]byte(`{"version":"1.22.0"}`))
+		case "/api/v1/repos/owner/repo":
+			w.Write([]byte(`{}`))
+		case "/api/v4/projects/7/repository/tags", "/api/v1/repos/owner/repo/tags":
+			w.Write([]byte(`[{"name":"v2"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	for _, raw := range []string{"https://gitlab.com/group/repo", "https://git.example.org/sub/owner/repo"} {
+		obj, err := global.ParseOffline(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", raw, err)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if tag, err := obj.TagLatest(); err != nil || tag.String() != "v2" {
+					t.Errorf("%s: TagLatest: %v, %v", raw, tag, err)
+				}
+				_ = obj.String() + obj.Type() + obj.URL().String()
+			}()
+		}
+		wg.Wait()
+	}
+
+	lookups := map[string]int{}
+	for _, p := range paths() {
+		lookups[p]++
+	}
+	for _, p := range []string{"/api/v4/projects/group%2Frepo", "/api/v1/repos/owner/repo"} {
+		if lookups[p] != 1 {
+			t.Fatalf("%s requested %d times, want one resolve per handle", p, lookups[p])
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func TestParseRef_Shapes(t *testing.T) {
+	recordServer(t, http.NotFound)
+
+	for _, tc := range []struct {
+		raw       string
+		name      string
+		kind      lightweigit.RefKindType
+		ref, path string
+	}{
+		{"https://github.com/o/r", "o/r", lightweigit.RefNone, "", ""},
+		{"git@github.com:o/r.git", "o/r", lightweigit.RefNone, "", ""},
+		{"https://github.com/o/r/releases/tag/v1.2.0", "o/r", lightweigit.RefRelease, "v1.2.0", ""},
+		{"https://github.com/o/r/releases/download/v1.2.0/app.zip", "o/r", lightweigit.RefRelease, "v1.2.0", ""},
+		{"https://github.com/o/r/commits/v0.1.0", "o/r", lightweigit.RefCommit, "v0.1.0", ""},
+		{"https://github.com/o/r/commit/0123abc", "o/r", lightweigit.RefCommit, "0123abc", ""},
+		{"https://github.com/o/r/blob/main/cmd/app/main.go", "o/r", lightweigit.RefFile, "main", "cmd/app/main.go"},
+		{"https://github.com/o/r/tree/v1/docs", "o/r", lightweigit.RefTree, "v1", "docs"},
+		{"https://github.com/o/r/archive/refs/tags/v1.0.0.tar.gz", "o/r", lightweigit.RefTag, "v1.0.0", ""},
+		{"https://github.com/o/r/archive/refs/heads/main.zip", "o/r", lightweigit.RefBranch, "main", ""},
+		{"https://gitlab.com/g/sub/r/-/tags/v3", "g/sub/r", lightweigit.RefTag, "v3", ""},
+		{"https://gitlab.com/g/r/-/releases/v3", "g/r", lightweigit.RefRelease, "v3", ""},
+		{"https://gitlab.com/g/r/-/blob/main/README.md", "g/r", lightweigit.RefFile, "main", "README.md"},
+		{"https://gitlab.com/g/r/-/tree/dev", "g/r", lightweigit.RefTree, "dev", ""},
+		{"https://gitlab.com/g/r/-/commit/0123abc", "g/r", lightweigit.RefCommit, "0123abc", ""},
+		{"https://gitea.com/o/r/src/branch/main/file.go", "o/r", lightweigit.RefFile, "main", "file.go"},
+		{"https://gitea.com/o/r/src/branch/main", "o/r", lightweigit.RefBranch, "main", ""},
+		{"https://gitea.com/o/r/src/tag/v2", "o/r", lightweigit.RefTag, "v2", ""},
+		{"https://codeberg.org/o/r/releases/tag/v4", "o/r", lightweigit.RefRelease, "v4", ""},
+		{"https://codeberg.org/o/r/commits/tag/v4", "o/r", lightweigit.RefTag, "v4", ""},
+		{"https://git.example.org/sub/o/r/src/commit/0123abc/a/b.txt", "sub/o", lightweigit.RefFile, "0123abc", "a/b.txt"},
+		{"https://bitbucket.org/ws/r/src/main/lib/", "ws/r", lightweigit.RefFile, "main", "lib"},
+		{"https://bitbucket.org/ws/r/branch/feature/x", "ws/r", lightweigit.RefBranch, "feature/x", ""},
+		{"https://bitbucket.org/ws/r/commits/0123abc", "ws/r", lightweigit.RefCommit, "0123abc", ""},
+	} {
+		res, err := global.ParseRefOffline(tc.raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.raw, err)
+		}
+		if res.Provider.String() != tc.name {
+			t.Fatalf("%s: unexpected repo %s", tc.raw, res.Provider.String())
+		}
+		if res.Ref.Kind != tc.kind || res.Ref.Name != tc.ref || res.Ref.Path != tc.path {
+			t.Fatalf("%s: got %s %q %q, want %s %q %q", tc.raw, res.Ref.Kind, res.Ref.Name, res.Ref.Path, tc.kind, tc.ref, tc.path)
+		}
+	}
+}
+
+func TestParseRef_ResolvesTag(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/releases/tags/v1.2.0":
+			w.Write([]byte(`{"tag_name":"v1.2.0","name":"one-two"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	res, err := global.ParseRef("https://github.com/o/r/releases/tag/v1.2.0")
+	if err != nil {
+		t.Fatalf("ParseRef error: %v", err)
+	}
+	rel, err := res.Release()
+	if err != nil {
+		t.Fatalf("Release error: %v", err)
+	}
+	if rel.Name() != "one-two" {
+		t.Fatalf("unexpected release: %s", rel.Name())
+	}
+
+	res, err = global.ParseRef("https://github.com/o/r/tree/main")
+	if err != nil {
+		t.Fatalf("ParseRef error: %v", err)
+	}
+	res.Ref.Kind = lightweigit.RefBranch
+	if _, err := res.Tag(); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a branch, got: %v", err)
+	}
+}
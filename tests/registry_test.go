@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+const forgeTagMod = target.ModCustomMin + 1
+
+// forgeObj stands in for an in-house forge; only the methods the tests
+// touch are implemented, the embedded nil interface covers the rest.
+type forgeObj struct {
+	lightweigit.ProviderInterface
+	name string
+}
+
+func (obj *forgeObj) Type() string   { return "forge" }
+func (obj *forgeObj) String() string { return obj.name }
+
+type forgeTagObj struct {
+	lightweigit.ProviderTagInterface
+	name string
+}
+
+func (tag *forgeTagObj) Mod() target.ModType { return forgeTagMod }
+func (tag *forgeTagObj) String() string      { return tag.name }
+func (tag *forgeTagObj) Marshal() []byte     { return lightweigit.Marshal(tag.Mod(), tag.name) }
+
+func forgeEntry() lightweigit.ProviderEntryObj {
+	return lightweigit.ProviderEntryObj{
+		Name:     "forge",
+		Priority: 200,
+		Match: func(host string) bool {
+			return host == "code.company.internal"
+		},
+		Parse: func(raw string) (lightweigit.ProviderInterface, error) {
+			i := strings.Index(raw, "code.company.internal/")
+			if i < 0 {
+				return nil, errors.New("not forge")
+			}
+			return &forgeObj{name: raw[i+len("code.company.internal/"):]}, nil
+		},
+		TagMod: forgeTagMod,
+		UnmarshalTag: func(data []byte) (lightweigit.ProviderTagInterface, error) {
+			var name string
+			if _, err := lightweigit.Unmarshal(data, &name); err != nil {
+				return nil, err
+			}
+			return &forgeTagObj{name: name}, nil
+		},
+	}
+}
+
+// //
+
+func TestRegistry_BuiltinsInOrder(t *testing.T) {
+	var names []string
+	for _, e := range lightweigit.Registry.Entries() {
+		names = append(names, e.Name)
+	}
+	if strings.Join(names, ",") != "local,bitbucket,github,gitlab,gogsFamily" {
+		t.Fatalf("unexpected built-in order: %v", names)
+	}
+}
+
+func TestRegistry_CustomProviderThroughGlobal(t *testing.T) {
+	if err := lightweigit.Register(forgeEntry()); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	t.Cleanup(func() { lightweigit.Registry.Unregister("forge") })
+
+	if err := lightweigit.Register(forgeEntry()); err == nil {
+		t.Fatal("expected error on duplicate registration")
+	}
+
+	obj, err := global.Parse("https://code.company.internal/team/tool")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if obj.Type() != "forge" || obj.String() != "team/tool" {
+		t.Fatalf("unexpected provider: %s %s", obj.Type(), obj.String())
+	}
+
+	obj, err = global.Parse("https://github.com/owner/repo")
+	if err != nil || obj.Type() != "github" {
+		t.Fatalf("built-in lost behind the custom provider: %v, %v", obj, err)
+	}
+
+	tag, err := global.UnmarshalTag((&forgeTagObj{name: "v1"}).Marshal())
+	if err != nil {
+		t.Fatalf("UnmarshalTag error: %v", err)
+	}
+	if tag.String() != "v1" {
+		t.Fatalf("unexpected tag: %s", tag)
+	}
+}
+
+func TestRegistry_RejectsModCollision(t *testing.T) {
+	r := new(lightweigit.RegistryObj)
+
+	entry := forgeEntry()
+	if err := r.Register(entry); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+
+	entry.Name = "other"
+	if err := r.Register(entry); err == nil {
+		t.Fatal("expected error for a taken mod byte")
+	}
+
+	entry.TagMod, entry.UnmarshalTag = 0, nil
+	if err := r.Register(entry); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	if _, err := r.Parse("https://example.org/a/b"); !errors.Is(err, lightweigit.ErrHostMismatch) {
+		t.Fatalf("expected ErrHostMismatch, got: %v", err)
+	}
+}
+
+func TestRegistry_RejectsReservedMods(t *testing.T) {
+	r := new(lightweigit.RegistryObj)
+
+	for _, mod := range []target.ModType{target.ModSnapshot, target.ModWatchState} {
+		entry := forgeEntry()
+		entry.TagMod = mod
+		if err := r.Register(entry); err == nil {
+			t.Fatalf("expected error for reserved mod %s", mod)
+		}
+	}
+}
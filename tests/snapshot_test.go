@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/snapshot"
+)
+
+// // // // // // // // // // // // // // // //
+
+func snapshotServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/tags"):
+			w.Write([]byte(`[{"name":"v1.1.0"},{"name":"v1.0.0"}]`))
+		case strings.HasSuffix(r.URL.Path, "/releases"):
+			w.Write([]byte(`[` +
+				`{"tag_name":"v1.1.0","name":"next","body":"rc","prerelease":true},` +
+				`{"tag_name":"v1.0.0","name":"first","body":"# Notes","assets":[` +
+				`{"browser_download_url":"https://github.com/owner/repo/releases/download/v1.0.0/app.zip","content_type":"application/zip","size":42}]}` +
+				`]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	swapHTTPClient(t, srv)
+	return srv
+}
+
+// //
+
+func TestSnapshot_RoundTripServesOffline(t *testing.T) {
+	snapshotServer(t)
+
+	obj := githubObj(t)
+	snap, err := snapshot.Take(context.Background(), obj)
+	if err != nil {
+		t.Fatalf("Take error: %v", err)
+	}
+
+	loaded, err := snapshot.Unmarshal(snap.Marshal())
+	if err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	origTag := githubObjTag(t)
+
+	// Any request from here on is a bug: the snapshot must answer offline.
+	lightweigit.HttpClient = &http.Client{Transport: rewriteTransportObj{host: "127.0.0.1:1"}}
+
+	var p lightweigit.ProviderInterface = loaded
+	if p.Type() != obj.Type() || p.String() != obj.String() || p.URL().String() != obj.URL().String() {
+		t.Fatalf("identity mismatch: %s %s %s", p.Type(), p.String(), p.URL())
+	}
+	if !loaded.Time().Equal(snap.Time()) {
+		t.Fatalf("time mismatch: %v != %v", loaded.Time(), snap.Time())
+	}
+
+	tag, err := p.TagLatest()
+	if err != nil || tag.String() != "v1.1.0" {
+		t.Fatalf("unexpected latest tag: %v, %v", tag, err)
+	}
+	if tag.ZIP().String() != origTag.ZIP().String() || tag.URL().String() != origTag.URL().String() {
+		t.Fatalf("url mismatch: %s != %s", tag.ZIP(), origTag.ZIP())
+	}
+	if _, err := p.TagFind("v1.0.0"); err != nil {
+		t.Fatalf("TagFind error: %v", err)
+	}
+	if _, err := p.TagFind("v9"); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+
+	rel, err := p.ReleaseLatest()
+	if err != nil {
+		t.Fatalf("ReleaseLatest error: %v", err)
+	}
+	if rel.Name() != "first" || rel.BodyMD() != "# Notes" || rel.Tag().String() != "v1.0.0" {
+		t.Fatalf("unexpected latest release: %s %q %s", rel.Name(), rel.BodyMD(), rel.Tag())
+	}
+	if len(rel.Assets()) != 1 || rel.Assets()[0].Name() != "app.zip" || rel.Assets()[0].Size() != 42 {
+		t.Fatalf("unexpected assets: %v", rel.Assets())
+	}
+	if rel, err = p.ReleaseFind("next"); err != nil || !rel.IsPrerelease() {
+		t.Fatalf("unexpected ReleaseFind result: %v, %v", rel, err)
+	}
+
+	out := make(chan lightweigit.ProviderTagInterface, 4)
+	if err := p.TagsStream(context.Background(), out, 1); err != nil {
+		t.Fatalf("TagsStream error: %v", err)
+	}
+	close(out)
+	if len(out) != 1 {
+		t.Fatalf("limit not respected: %d items", len(out))
+	}
+}
+
+func TestSnapshot_ItemMarshal(t *testing.T) {
+	snapshotServer(t)
+
+	snap, err := snapshot.Take(context.Background(), githubObj(t))
+	if err != nil {
+		t.Fatalf("Take error: %v", err)
+	}
+
+	tag, _ := snap.TagLatest()
+	bTag, err := snapshot.UnmarshalTag(tag.Marshal())
+	if err != nil {
+		t.Fatalf("UnmarshalTag error: %v", err)
+	}
+	if bTag.URL().String() != tag.URL().String() {
+		t.Fatal("tag does not match:", bTag.URL(), tag.URL())
+	}
+
+	rel, _ := snap.ReleaseLatest()
+	bRel, err := snapshot.UnmarshalRelease(rel.Marshal())
+	if err != nil {
+		t.Fatalf("UnmarshalRelease error: %v", err)
+	}
+	if bRel.URL().String() != rel.URL().String() || bRel.Tag().String() != rel.Tag().String() {
+		t.Fatal("release does not match:", bRel.URL(), rel.URL())
+	}
+
+	if _, err := snapshot.Unmarshal(nil); err == nil {
+		t.Fatal("expected error on empty data")
+	}
+	if _, err := snapshot.Unmarshal(githubObjTag(t).Marshal()); !errors.Is(err, lightweigit.ErrModTag) {
+		t.Fatalf("expected ErrModTag for a provider blob, got: %v", err)
+	}
+}
+
+func githubObjTag(t *testing.T) lightweigit.ProviderTagInterface {
+	t.Helper()
+
+	tag, err := githubObj(t).TagLatest()
+	if err != nil {
+		t.Fatalf("TagLatest error: %v", err)
+	}
+	return tag
+}
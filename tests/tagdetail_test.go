@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+const (
+	tagSig     = "-----BEGIN SSH SIGNATURE-----\nU1NIU0lH\n-----END SSH SIGNATURE-----\n"
+	tagSigJSON = `-----BEGIN SSH SIGNATURE-----\nU1NIU0lH\n-----END SSH SIGNATURE-----\n`
+)
+
+// //
+
+func TestTagDetail_Providers(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		// GitHub
+		case "/repos/o/r/git/ref/tags/v1.0.0":
+			w.Write([]byte(`{"ref":"refs/tags/v1.0.0","object":{"type":"tag","sha":"t1"}}`))
+		case "/repos/o/r/git/tags/t1":
+			w.Write([]byte(`{"sha":"t1","message":"Release 1.0\n` + tagSigJSON + `","tagger":{"name":"Ann","email":"ann@example.org","date":"2024-05-01T10:00:00Z"},
+				"object":{"type":"commit","sha":"c1"},"verification":{"verified":true,"reason":"valid","signature":null,"payload":"object c1\n"}}`))
+		case "/repos/o/r/git/ref/tags/v0.9.0":
+			w.Write([]byte(`{"ref":"refs/tags/v0.9.0","object":{"type":"commit","sha":"c0"}}`))
+
+		// GitLab
+		case "/api/v4/projects/group%2Frepo":
+			w.Write([]byte(`{"id":7}`))
+		case "/api/v4/projects/7/repository/tags/v1.0.0":
+			w.Write([]byte(`{"name":"v1.0.0","message":"Release 1.0","target":"t1","commit":{"id":"c1"},"created_at":"2024-05-01T10:00:00.000Z"}`))
+		case "/api/v4/projects/7/repository/tags/v1.0.0/signature":
+			w.Write([]byte(`{"signature_type":"SSH","verification_status":"unverified"}`))
+		case "/api/v4/projects/7/repository/tags/v0.9.0":
+			w.Write([]byte(`{"name":"v0.9.0","message":"","target":"c0","commit":{"id":"c0"}}`))
+
+		// Gitea
+		case "/api/v1/repos/o/r":
+			w.Write([]byte(`{"full_name":"o/r"}`))
+		case "/api/v1/repos/o/r/tags/v1.0.0":
+			w.Write([]byte(`{"name":"v1.0.0","id":"t1","commit":{"sha":"c1"}}`))
+		case "/api/v1/repos/o/r/git/tags/t1":
+			w.Write([]byte(`{"sha":"t1","message":"Release 1.0\n","tagger":{"name":"Ann","email":"ann@example.org","date":"2024-05-01T10:00:00Z"},
+				"object":{"type":"commit","sha":"c1"},"verification":{"verified":false,"reason":"gpg.error.no_gpg_keys_found","signature":"` + tagSigJSON + `","payload":"object c1\n"}}`))
+		case "/api/v1/repos/o/r/tags/v0.9.0":
+			w.Write([]byte(`{"name":"v0.9.0","id":"c0","commit":{"sha":"c0"}}`))
+
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	when := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		url      string
+		tagger   string
+		sig      string
+		payload  string
+		verified *lightweigit.VerificationObj
+	}{
+		{"https://github.com/o/r", "Ann", tagSig, "object c1\n", &lightweigit.VerificationObj{Verified: true, Reason: "valid"}},
+		{"https://gitlab.com/group/repo", "", "", "", &lightweigit.VerificationObj{Verified: false, Reason: "unverified"}},
+		{"https://gitea.com/o/r", "Ann", tagSig, "object c1\n", &lightweigit.VerificationObj{Verified: false, Reason: "gpg.error.no_gpg_keys_found"}},
+	} {
+		d, err := offlineAs[lightweigit.ProviderTagDetailInterface](t, tc.url).TagDetail("v1.0.0")
+		if err != nil {
+			t.Fatalf("%s: TagDetail error: %v", tc.url, err)
+		}
+		if d.Kind != lightweigit.TagAnnotated || d.SHA != "t1" || d.Commit != "c1" {
+			t.Fatalf("%s: unexpected object %+v", tc.url, *d)
+		}
+		if d.Message != "Release 1.0\n" && d.Message != "Release 1.0" {
+			t.Fatalf("%s: unexpected message %q", tc.url, d.Message)
+		}
+		if d.TaggerName != tc.tagger || !d.TaggerDate.Equal(when) {
+			t.Fatalf("%s: unexpected tagger %q %v", tc.url, d.TaggerName, d.TaggerDate)
+		}
+		if d.Signature != tc.sig || d.Payload != tc.payload {
+			t.Fatalf("%s: unexpected signature %q / payload %q", tc.url, d.Signature, d.Payload)
+		}
+		if d.Verification == nil || *d.Verification != *tc.verified || !d.IsSigned() {
+			t.Fatalf("%s: unexpected verification %+v", tc.url, d.Verification)
+		}
+
+		light, err := offlineAs[lightweigit.ProviderTagDetailInterface](t, tc.url).TagDetail("v0.9.0")
+		if err != nil {
+			t.Fatalf("%s: TagDetail error: %v", tc.url, err)
+		}
+		if light.Kind != lightweigit.TagLightweight || light.SHA != "c0" || light.Commit != "c0" || light.IsSigned() {
+			t.Fatalf("%s: unexpected lightweight tag %+v", tc.url, *light)
+		}
+	}
+}
+
+func TestTagDetail_Errors(t *testing.T) {
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	p, _ := global.ParseOffline("https://github.com/o/r")
+	if _, err := p.(lightweigit.ProviderTagDetailInterface).TagDetail("missing"); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := p.(lightweigit.ProviderTagDetailInterface).TagDetail(" "); err == nil {
+		t.Fatal("expected an error for an empty name")
+	}
+}
+
+func TestTagDetail_SplitSignature(t *testing.T) {
+	msg, sig := lightweigit.SplitTagSignature("Release\n\nNotes\n" + tagSig)
+	if msg != "Release\n\nNotes\n" || sig != tagSig {
+		t.Fatalf("got %q / %q", msg, sig)
+	}
+	if msg, sig := lightweigit.SplitTagSignature("mentions -----BEGIN PGP SIGNATURE----- inline"); sig != "" || msg == "" {
+		t.Fatalf("split a signature mark that does not start a line: %q / %q", msg, sig)
+	}
+}
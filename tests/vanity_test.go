@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/vanity"
+)
+
+// // // // // // // // // // // // // // // //
+
+func vanityServer(t *testing.T) func() []string {
+	t.Helper()
+
+	page := func(w http.ResponseWriter, code int, head string) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(code)
+		fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head>\n%s\n</head><body>nothing</body></html>", head)
+	}
+
+	return recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("go-get") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch r.Host + r.URL.Path {
+		case "go.uber.org/zap", "go.uber.org/zap/zapcore", "go.uber.org/zap/v2":
+			page(w, http.StatusOK, `<meta name="go-import" content="go.uber.org/zap git https://github.com/uber-go/zap">
+<meta name="go-source" content="go.uber.org/zap https://github.com/uber-go/zap https://github.com/uber-go/zap/tree/master{/dir} https://github.com/uber-go/zap/tree/master{/dir}/{file}#L{line}">`)
+		case "golang.org/x/net/http2":
+			// Subpackages of golang.org/x answer 404 with the right tags.
+			page(w, http.StatusNotFound, `<meta name="go-import" content="golang.org/x/net git https://go.googlesource.com/net">
+<meta name="go-import" content="golang.org/x/net mod https://proxy.golang.org">
+<meta name="go-source" content="golang.org/x/net https://github.com/golang/net/ https://github.com/golang/net/tree/master{/dir} https://github.com/golang/net/blob/master{/dir}/{file}#L{line}">`)
+		case "example.org/mono/tool":
+			page(w, http.StatusOK, `<meta name="go-import" content="example.org/mono/tool git https://gitea.com/org/mono tools/tool">`)
+		case "example.org/proxy-only":
+			page(w, http.StatusOK, `<meta name="go-import" content="example.org/proxy-only mod https://proxy.example.org">`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// //
+
+func TestVanity_Resolve(t *testing.T) {
+	vanityServer(t)
+
+	for _, tc := range []struct {
+		path, prefix, repo, subdir string
+		major                      int
+	}{
+		{"go.uber.org/zap", "go.uber.org/zap", "https://github.com/uber-go/zap", "", 0},
+		{"go.uber.org/zap/zapcore", "go.uber.org/zap", "https://github.com/uber-go/zap", "zapcore", 0},
+		{"go.uber.org/zap/v2", "go.uber.org/zap", "https://github.com/uber-go/zap", "", 2},
+		{"golang.org/x/net/http2", "golang.org/x/net", "https://go.googlesource.com/net", "http2", 0},
+		{"example.org/mono/tool", "example.org/mono/tool", "https://gitea.com/org/mono", "tools/tool", 0},
+		{"github.com/o/r/v3/pkg/x", "github.com/o/r", "https://github.com/o/r", "pkg/x", 3},
+		{"github.com/o/r/v3", "github.com/o/r", "https://github.com/o/r", "", 3},
+	} {
+		obj, err := vanity.Resolve(context.Background(), tc.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+		if obj.Prefix() != tc.prefix || obj.Repo().String() != tc.repo || obj.Subdir() != tc.subdir || obj.Major() != tc.major {
+			t.Fatalf("%s: got %s %s %q v%d", tc.path, obj.Prefix(), obj.Repo(), obj.Subdir(), obj.Major())
+		}
+	}
+}
+
+func TestVanity_Parse(t *testing.T) {
+	paths := vanityServer(t)
+
+	p, obj, err := vanity.Parse(context.Background(), "go.uber.org/zap/zapcore")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if p.Type() != "github" || p.String() != "uber-go/zap" || obj.Subdir() != "zapcore" {
+		t.Fatalf("unexpected result: %s %s %q", p.Type(), p.String(), obj.Subdir())
+	}
+
+	// go.googlesource.com is no provider: the go-source home page is used.
+	p, _, err = vanity.Parse(context.Background(), "golang.org/x/net/http2")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if p.Type() != "github" || p.String() != "golang/net" {
+		t.Fatalf("unexpected result: %s %s", p.Type(), p.String())
+	}
+
+	if got := paths(); len(got) != 2 {
+		t.Fatalf("unexpected requests: %v", got)
+	}
+}
+
+func TestVanity_Errors(t *testing.T) {
+	vanityServer(t)
+
+	for _, path := range []string{"example.org/missing", "example.org/proxy-only"} {
+		if _, err := vanity.Resolve(context.Background(), path); !errors.Is(err, lightweigit.ErrNotFound) {
+			t.Fatalf("%s: expected ErrNotFound, got: %v", path, err)
+		}
+	}
+	if _, err := vanity.Resolve(context.Background(), "fmt"); err == nil {
+		t.Fatal("expected error for a standard library path")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := vanity.Resolve(ctx, "go.uber.org/zap"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+}
@@ -0,0 +1,693 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target/global"
+	"github.com/voluminor/lightweigit-loader/verify"
+)
+
+// // // // // // // // // // // // // // // //
+
+// pgpKeyObj is a test OpenPGP key: the public key packet body and a way
+// to sign with it.
+type pgpKeyObj struct {
+	algo byte
+	body []byte
+	sign func(digest []byte) []byte
+}
+
+func pgpPacket(tag byte, body []byte) []byte {
+	out := []byte{0xc0 | tag, 0xff, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(out[2:], uint32(len(body)))
+	return append(out, body...)
+}
+
+func pgpMPI(b []byte) []byte {
+	b = bytes.TrimLeft(b, "\x00")
+	bits := len(b) * 8
+	if len(b) > 0 {
+		for m := byte(0x80); b[0]&m == 0; m >>= 1 {
+			bits--
+		}
+	}
+	return append([]byte{byte(bits >> 8), byte(bits)}, b...)
+}
+
+func pgpKeyBody(algo byte, material []byte) []byte {
+	return append([]byte{4, 0x65, 0, 0, 0, algo}, material...)
+}
+
+func newPGPEd25519(t *testing.T) *pgpKeyObj {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oid := []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0xda, 0x47, 0x0f, 0x01}
+	material := append(append([]byte{byte(len(oid))}, oid...), pgpMPI(append([]byte{0x40}, pub...))...)
+	return &pgpKeyObj{algo: 22, body: pgpKeyBody(22, material), sign: func(d []byte) []byte {
+		sig := ed25519.Sign(priv, d)
+		return append(pgpMPI(sig[:32]), pgpMPI(sig[32:])...)
+	}}
+}
+
+func newPGPRSA(t *testing.T) *pgpKeyObj {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	material := append(pgpMPI(priv.N.Bytes()), pgpMPI(big.NewInt(int64(priv.E)).Bytes())...)
+	return &pgpKeyObj{algo: 1, body: pgpKeyBody(1, material), sign: func(d []byte) []byte {
+		sig, err := rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pgpMPI(sig)
+	}}
+}
+
+func newPGPECDSA(t *testing.T) *pgpKeyObj {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oid := []byte{0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}
+	point := elliptic.Marshal(elliptic.P256(), priv.X, priv.Y)
+	material := append(append([]byte{byte(len(oid))}, oid...), pgpMPI(point)...)
+	return &pgpKeyObj{algo: 19, body: pgpKeyBody(19, material), sign: func(d []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, priv, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(pgpMPI(r.Bytes()), pgpMPI(s.Bytes())...)
+	}}
+}
+
+func (k *pgpKeyObj) fingerprint() []byte {
+	h := sha1.New()
+	h.Write([]byte{0x99, byte(len(k.body) >> 8), byte(len(k.body))})
+	h.Write(k.body)
+	return h.Sum(nil)
+}
+
+func (k *pgpKeyObj) hashPrefix() []byte {
+	return append([]byte{0x99, byte(len(k.body) >> 8), byte(len(k.body))}, k.body...)
+}
+
+// signature makes a version 4 SHA-256 signature packet of sigType over
+// data, issued by k.
+func (k *pgpKeyObj) signature(sigType byte, data []byte) []byte {
+	return k.signatureAt(sigType, data, 0x66000000)
+}
+
+// signatureAt is signature created at the Unix time created, with extra
+// hashed subpackets.
+func (k *pgpKeyObj) signatureAt(sigType byte, data []byte, created uint32, extra ...[]byte) []byte {
+	fp := k.fingerprint()
+	sub := append(pgpSub(2, binary.BigEndian.AppendUint32(nil, created)), append([]byte{22, 33, 4}, fp...)...)
+	for _, e := range extra {
+		sub = append(sub, e...)
+	}
+	hashed := append([]byte{4, sigType, k.algo, 8, byte(len(sub) >> 8), byte(len(sub))}, sub...)
+
+	h := sha256.New()
+	h.Write(data)
+	h.Write(hashed)
+	trailer := []byte{4, 0xff, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(trailer[2:], uint32(len(hashed)))
+	h.Write(trailer)
+	digest := h.Sum(nil)
+
+	unhashed := append([]byte{9, 16}, fp[12:]...)
+	body := append(hashed, 0, byte(len(unhashed)))
+	body = append(body, unhashed...)
+	body = append(body, digest[:2]...)
+	return pgpPacket(2, append(body, k.sign(digest)...))
+}
+
+// pgpSub is a signature subpacket; typ carries the critical bit.
+func pgpSub(typ byte, data []byte) []byte {
+	n := len(data) + 1
+	if n < 192 {
+		return append([]byte{byte(n), typ}, data...)
+	}
+	n -= 192
+	return append([]byte{byte(n>>8) + 192, byte(n), typ}, data...)
+}
+
+func pgpArmor(kind string, body []byte) []byte {
+	crc := uint32(0xb704ce)
+	for _, c := range body {
+		crc ^= uint32(c) << 16
+		for i := 0; i < 8; i++ {
+			if crc <<= 1; crc&0x1000000 != 0 {
+				crc ^= 0x1864cfb
+			}
+		}
+	}
+	sum := base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)})
+
+	var b strings.Builder
+	b.WriteString("-----BEGIN PGP " + kind + "-----\nComment: test\n\n")
+	enc := base64.StdEncoding.EncodeToString(body)
+	for len(enc) > 64 {
+		b.WriteString(enc[:64] + "\n")
+		enc = enc[64:]
+	}
+	b.WriteString(enc + "\n=" + sum + "\n-----END PGP " + kind + "-----\n")
+	return []byte(b.String())
+}
+
+// selfCert certifies uid on k with the certify and sign flags, or with
+// flags when given.
+func (k *pgpKeyObj) selfCert(uid string, flags ...byte) []byte {
+	if flags == nil {
+		flags = []byte{0x03}
+	}
+	data := append(k.hashPrefix(), 0xb4, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], uint32(len(uid)))
+	return k.signatureAt(0x13, append(data, uid...), 0x66000000, pgpSub(27, flags))
+}
+
+// binding binds sub to k as a signing subkey, with sub's back-signature
+// over k embedded, plus extra hashed subpackets.
+func (k *pgpKeyObj) binding(sub *pgpKeyObj, extra ...[]byte) []byte {
+	prefix := append(k.hashPrefix(), sub.hashPrefix()...)
+	back := sub.signatureAt(0x19, prefix, 0x66000000)
+	return k.signatureAt(0x18, prefix, 0x66000000, append([][]byte{pgpSub(27, []byte{0x02}), pgpSub(32, back[6:])}, extra...)...)
+}
+
+// publicKey exports k with a self-certified user ID, and sub bound to it
+// when given.
+func (k *pgpKeyObj) publicKey(uid string, sub *pgpKeyObj, bind bool) []byte {
+	out := append(pgpPacket(6, k.body), pgpPacket(13, []byte(uid))...)
+	out = append(out, k.selfCert(uid)...)
+	if sub != nil {
+		out = append(out, pgpPacket(14, sub.body)...)
+		if bind {
+			out = append(out, k.binding(sub)...)
+		}
+	}
+	return pgpArmor("PUBLIC KEY BLOCK", out)
+}
+
+// //
+
+func sshWire(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		n := make([]byte, 4)
+		binary.BigEndian.PutUint32(n, uint32(len(p)))
+		out = append(append(out, n...), p...)
+	}
+	return out
+}
+
+// sshSign makes an armored SSHSIG signature of data in namespace.
+func sshSign(t *testing.T, priv crypto.Signer, data []byte, namespace string) (blob []byte, armored []byte) {
+	t.Helper()
+
+	var format string
+	switch pub := priv.Public().(type) {
+	case ed25519.PublicKey:
+		format, blob = "ssh-ed25519", sshWire([]byte("ssh-ed25519"), pub)
+	case *ecdsa.PublicKey:
+		format, blob = "ecdsa-sha2-nistp256", sshWire([]byte("ecdsa-sha2-nistp256"), []byte("nistp256"), elliptic.Marshal(pub.Curve, pub.X, pub.Y))
+	}
+
+	sum := sha512.Sum512(data)
+	signed := append([]byte("SSHSIG"), sshWire([]byte(namespace), nil, []byte("sha512"), sum[:])...)
+
+	var sig []byte
+	switch k := priv.(type) {
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, signed)
+	case *ecdsa.PrivateKey:
+		h := sha256.Sum256(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, k, h[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = sshWire(append([]byte{0}, r.Bytes()...), append([]byte{0}, s.Bytes()...))
+	}
+
+	raw := append([]byte("SSHSIG\x00\x00\x00\x01"), sshWire(blob, []byte(namespace), nil, []byte("sha512"), sshWire([]byte(format), sig))...)
+	enc := base64.StdEncoding.EncodeToString(raw)
+	var b strings.Builder
+	b.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(enc) > 70 {
+		b.WriteString(enc[:70] + "\n")
+		enc = enc[70:]
+	}
+	b.WriteString(enc + "\n-----END SSH SIGNATURE-----\n")
+	return blob, []byte(b.String())
+}
+
+// //
+
+// The fixtures below were made with GnuPG 2.2 and OpenSSH over
+// fixtureData. gpgKey is an Ed25519 primary key with an RSA signing
+// subkey, both expiring two years after creation; fixtureSig was made by
+// the subkey. gpgKeyRevoked is the same key after revkey on the subkey:
+// gpg exports the revocation ahead of the binding.
+const (
+	fixtureData = "release artifact\n"
+
+	gpgKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatYXiBYJKwYBBAHaRw8BAQdA8r8QD/vnOV8aw0d0E2DZTeAcDXUpjS/Ixkgr
+DdSdI1e0HUFubiBFeGFtcGxlIDxhbm5AZXhhbXBsZS5vcmc+iJYEExYIAD4WIQTs
+FZ+nXXVT4PgxMIfTxdnHuvA22gUCatYXiAIbAwUJA8JnAAULCQgHAgYVCgkICwIE
+FgIDAQIeAQIXgAAKCRDTxdnHuvA22vpyAP9DXIsT16JMUA1xA51ZNE7m/i52IYcg
+5easaVgdegFidQEAsBMsZlNaBDcGBI9sbXDcs1639q2l31BN8285EVdAhAS5AQ0E
+atYXiAEIAOERgbJ0F3edt7HjoQVYFq0BiZhm6en+4QUQKDCZ7k4H3UCbip9bSDsJ
+gIG2IWqGCdZiX27yBYrX7d/eNVp8ArcHqO7VAzIvrxXzqv5ihwr/l1tMSUvH4TJe
+a4UKhwa7EgI92XeZooUJiEKZ4n2F37aVV/euhwCnyECNC/LX/KWQnNO84O2py8Lw
+sVQ7TUUGCV2Zot5bOMkNKa2UlnHKE8a+6PHZv9YsH8GvtMPXiKIZlDRPTbfx9j9T
+lhoZgRf537GnPAUJBFlM76lXHoDayqgkqNoLqiUq/g5Bc38X/SggVFyb1wuslJ9s
+igmfXd5on1h8eebO6RxABoIJ3lCZyq0AEQEAAYkBtAQYFggAJhYhBOwVn6dddVPg
++DEwh9PF2ce68DbaBQJq1heIAhsCBQkDwmcAAUAJENPF2ce68DbawHQgBBkBCgAd
+FiEE0knuk4dMCmjOKg7G/ocuV22wKEIFAmrWF4gACgkQ/ocuV22wKEIb7wf/YbUv
+IT7J0rljjGwgZ/QtNqC/o1rB+gOthdJVJ3dsZovZESX7/v6pEeLKx/vpnAn5Sdb9
+zqwzJzxvtfeopM3ti/U/pHdMi6yzCUuu1qhF2K0+fHfI63XLC0d4ayhPSlGazd/W
+ndKy4wQGVJsj5yVeGAyPF+DZQx+ubMcqlKNwjeO00fV/GjVXTyWwa2tjTxoaLov+
+yuISKtoDi21BO2B2b72a54qBcEyxiIFIsUMMjhXLrIKiR2ppcct41UbpbKGldzxY
+nbMiBrmBn1+i/8KqF41YfYYm4Gz9IM0MT1nk7FeR6COhZ9XafDc5DQ20geGIzGrR
+S6M/EOqi1uj1KYfE8ZQDAQCo/8IGlIZ+GwliWHCWo5k1Q3QOKArsOvQJ/Cc4ZxCm
+SAD9FYDyG0Il0gEIi+qlVpweIsdq74vYp4onHN3AVLffEA8=
+=crG4
+-----END PGP PUBLIC KEY BLOCK-----
+`
+	gpgSig = `-----BEGIN PGP SIGNATURE-----
+
+iQEzBAABCgAdFiEE0knuk4dMCmjOKg7G/ocuV22wKEIFAmrWF40ACgkQ/ocuV22w
+KEIYBgf/UjHJkoA1dbAnv7BSn52l8GlcHCrdesnWdhGnzVaMpkBWwFg6shUa95OU
+BGk0j7WYHiBGf+kaLf/eaZogTU6p6Cz8++kW137NynJLF+fm9QwC2lpEn+Xi/lts
+ead5gOAmg3yoa0NjeYzoHHDfe4p5WWFi/R3Q0KUbmzMc/k3RpzaEP5cTdiZoeiqC
+JQho4JBq16t5WzzEPsn1sjGBgLu2JVpOFS0ZronsvGYTPCjouvlUblljPVodEmaA
+fHn0jObHnZltG9+fyfyERSLkj2hdZn1rHWMYLOiFQpbt4DKslJeV+QJzKuuutUYz
+byDBoC+yAXlwVbz2nv81VIKgcg95KA==
+=kN3L
+-----END PGP SIGNATURE-----
+`
+
+	gpgKeyRevoked = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatYXiBYJKwYBBAHaRw8BAQdA8r8QD/vnOV8aw0d0E2DZTeAcDXUpjS/Ixkgr
+DdSdI1e0HUFubiBFeGFtcGxlIDxhbm5AZXhhbXBsZS5vcmc+iJYEExYIAD4WIQTs
+FZ+nXXVT4PgxMIfTxdnHuvA22gUCatYXiAIbAwUJA8JnAAULCQgHAgYVCgkICwIE
+FgIDAQIeAQIXgAAKCRDTxdnHuvA22vpyAP9DXIsT16JMUA1xA51ZNE7m/i52IYcg
+5easaVgdegFidQEAsBMsZlNaBDcGBI9sbXDcs1639q2l31BN8285EVdAhAS5AQ0E
+atYXiAEIAOERgbJ0F3edt7HjoQVYFq0BiZhm6en+4QUQKDCZ7k4H3UCbip9bSDsJ
+gIG2IWqGCdZiX27yBYrX7d/eNVp8ArcHqO7VAzIvrxXzqv5ihwr/l1tMSUvH4TJe
+a4UKhwa7EgI92XeZooUJiEKZ4n2F37aVV/euhwCnyECNC/LX/KWQnNO84O2py8Lw
+sVQ7TUUGCV2Zot5bOMkNKa2UlnHKE8a+6PHZv9YsH8GvtMPXiKIZlDRPTbfx9j9T
+lhoZgRf537GnPAUJBFlM76lXHoDayqgkqNoLqiUq/g5Bc38X/SggVFyb1wuslJ9s
+igmfXd5on1h8eebO6RxABoIJ3lCZyq0AEQEAAYh4BCgWCAAgFiEE7BWfp111U+D4
+MTCH08XZx7rwNtoFAmrWF5ACHQAACgkQ08XZx7rwNtr6tgEAlLkxXXcbOSIicT1c
+pt4N5VoXqYeYaNFux6cECSxqcKQA/jQmy5eYwc2BBxvolff8MH/yN2XyZR1yaolE
+8a2O3rEOiQG0BBgWCAAmFiEE7BWfp111U+D4MTCH08XZx7rwNtoFAmrWF4gCGwIF
+CQPCZwABQAkQ08XZx7rwNtrAdCAEGQEKAB0WIQTSSe6Th0wKaM4qDsb+hy5XbbAo
+QgUCatYXiAAKCRD+hy5XbbAoQhvvB/9htS8hPsnSuWOMbCBn9C02oL+jWsH6A62F
+0lUnd2xmi9kRJfv+/qkR4srH++mcCflJ1v3OrDMnPG+196ikze2L9T+kd0yLrLMJ
+S67WqEXYrT58d8jrdcsLR3hrKE9KUZrN39ad0rLjBAZUmyPnJV4YDI8X4NlDH65s
+xyqUo3CN47TR9X8aNVdPJbBra2NPGhoui/7K4hIq2gOLbUE7YHZvvZrnioFwTLGI
+gUixQwyOFcusgqJHamlxy3jVRulsoaV3PFidsyIGuYGfX6L/wqoXjVh9hibgbP0g
+zQxPWeTsV5HoI6Fn1dp8NzkNDbSB4YjMatFLoz8Q6qLW6PUph8TxlAMBAKj/wgaU
+hn4bCWJYcJajmTVDdA4oCuw69An8JzhnEKZIAP0VgPIbQiXSAQiL6qVWnB4ix2rv
+i9iniicc3cBUt98QDw==
+=YxXV
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+	sshEd25519Key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICnaBef01qrVLnsA6oQnWfiueNqvJj4VVS8JQIrWw6z0"
+	sshEd25519Sig = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAgKdoF5/TWqtUuewDqhCdZ+K542q
+8mPhVVLwlAitbDrPQAAAAEZmlsZQAAAAAAAAAGc2hhNTEyAAAAUwAAAAtzc2gtZWQyNTUx
+OQAAAEBpPxq2vzpFDUEC3KTSuG8LqK3qb5LkZAEudmaObTQQEReBggNS+FUnWkvbcnjngE
+KE8CC60TegBf/jfzljWAED
+-----END SSH SIGNATURE-----
+`
+
+	sshRSAKey = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCDomu6/KQF0NEZo7uRJc92Qg8tR5rx5VpEWC4l2/hXdN3xOLGHpPPIvnEtgfELCrNXQiDRrDOSYszbg1tXHAgK+ITdVvuCtOKtpOQg2gG2oK5l9VR9Rq7m5TOcWLwUzehTqbENPP3FfMJ80b07+Q2EVQ+hKEWmhrnDkiFRO1NmR0FwyCPb3cANljxoNxbSBipoevtM3+0u9W7C5fzke10qzsAre5OFSwG8+dNqyz/lglnM7AcDDhDIsHJcIy7rs0H5gLEZguXIi3/oeBkoB2OK5BbhUGsI7H+O2P63euYOFOE8/oqnjgcRwxJTX1uEeIQPiuOW15tXZf6L47NlzpND"
+	sshRSASig = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAARcAAAAHc3NoLXJzYQAAAAMBAAEAAAEBAIOia7r8pAXQ0Rmju5Elz3
+ZCDy1HmvHlWkRYLiXb+Fd03fE4sYek88i+cS2B8QsKs1dCINGsM5JizNuDW1ccCAr4hN1W
++4K04q2k5CDaAbagrmX1VH1GrublM5xYvBTN6FOpsQ08/cV8wnzRvTv5DYRVD6EoRaaGuc
+OSIVE7U2ZHQXDII9vdwA2WPGg3FtIGKmh6+0zf7S71bsLl/OR7XSrOwCt7k4VLAbz502rL
+P+WCWczsBwMOEMiwclwjLuuzQfmAsRmC5ciLf+h4GSgHY4rkFuFQawjsf47Y/rd65g4U4T
+z+iqeOBxHDElNfW4R4hA+K45bXm1dl/ovjs2XOk0MAAAAEZmlsZQAAAAAAAAAGc2hhNTEy
+AAABFAAAAAxyc2Etc2hhMi01MTIAAAEAcqMgeFWAHfXy25m2Ba0WgOJQ91PW4gZZ2nCGm9
+lXTSuYrorv05bdV67PIBed9xaBwyhUvyckgZixAcUYFmyICOHYiEX+3rqogXU4jVROefDP
+vSb75Nu9wzEz8Xdl2g1kZdftcNHC4eTkeNVMMVIkXPEazH+sy/SRBURPZS2+SGK0PkZ+9W
+Xp9Ypnq2eriv44+gJolUT8nsqIUkf/3bbg182sKve4/5D0UCZ2T3iL3/qHsb9hW/lGskUg
+JnkTxzHNWq7JjkIzmoyMposm6ivNSxvovseszjqSDtarl+hWX5gI7Sw02kolOOUgcsqN5v
+HT/c9EST272g0z4s3h09FG6w==
+-----END SSH SIGNATURE-----
+`
+)
+
+// //
+
+func TestVerify_OpenPGP(t *testing.T) {
+	data := []byte("release artifact\n")
+
+	for name, key := range map[string]*pgpKeyObj{
+		"ed25519": newPGPEd25519(t),
+		"rsa":     newPGPRSA(t),
+		"ecdsa":   newPGPECDSA(t),
+	} {
+		kr := verify.NewKeyring()
+		if err := kr.AddOpenPGP(key.publicKey("Ann <ann@example.org>", nil, false)); err != nil {
+			t.Fatalf("%s: AddOpenPGP: %v", name, err)
+		}
+
+		sig := key.signature(0x00, data)
+		for _, form := range [][]byte{sig, pgpArmor("SIGNATURE", sig)} {
+			res, err := kr.Verify(data, form, verify.NamespaceFile)
+			if err != nil {
+				t.Fatalf("%s: Verify: %v", name, err)
+			}
+			if res.Format != verify.FormatOpenPGP || res.Signer != "Ann <ann@example.org>" || len(res.Fingerprint) != 40 || res.Created.Unix() != 0x66000000 {
+				t.Fatalf("%s: unexpected result %+v", name, *res)
+			}
+		}
+
+		if _, err := kr.Verify([]byte("tampered\n"), sig, ""); !errors.Is(err, verify.ErrBadSignature) {
+			t.Fatalf("%s: expected ErrBadSignature, got %v", name, err)
+		}
+	}
+
+	other := verify.NewKeyring()
+	if err := other.AddOpenPGP(newPGPEd25519(t).publicKey("Bob", nil, false)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Verify(data, newPGPEd25519(t).signature(0x00, data), ""); !errors.Is(err, verify.ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestVerify_OpenPGPSubkey(t *testing.T) {
+	data := []byte("payload")
+	primary, sub := newPGPEd25519(t), newPGPEd25519(t)
+
+	kr := verify.NewKeyring()
+	if err := kr.AddOpenPGP(primary.publicKey("Ann", sub, true)); err != nil {
+		t.Fatal(err)
+	}
+	res, err := kr.Verify(data, sub.signature(0x00, data), "")
+	if err != nil {
+		t.Fatalf("Verify with bound subkey: %v", err)
+	}
+	if res.Signer != "Ann" || res.KeyID != strings.ToUpper(res.KeyID) || strings.HasSuffix(res.Fingerprint, res.KeyID) {
+		t.Fatalf("expected the primary fingerprint and the subkey ID, got %+v", *res)
+	}
+
+	unbound := verify.NewKeyring()
+	if err := unbound.AddOpenPGP(primary.publicKey("Ann", sub, false)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unbound.Verify(data, sub.signature(0x00, data), ""); !errors.Is(err, verify.ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey for an unbound subkey, got %v", err)
+	}
+}
+
+func TestVerify_OpenPGPFixture(t *testing.T) {
+	kr := verify.NewKeyring()
+	if err := kr.AddOpenPGP([]byte(gpgKey)); err != nil {
+		t.Fatalf("AddOpenPGP: %v", err)
+	}
+	res, err := kr.Verify([]byte(fixtureData), []byte(gpgSig), "")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if res.Signer != "Ann Example <ann@example.org>" || res.Fingerprint != "EC159FA75D7553E0F8313087D3C5D9C7BAF036DA" || res.KeyID != "FE872E576DB02842" {
+		t.Fatalf("unexpected result %+v", *res)
+	}
+
+	revoked := verify.NewKeyring()
+	if err := revoked.AddOpenPGP([]byte(gpgKeyRevoked)); err != nil {
+		t.Fatalf("AddOpenPGP: %v", err)
+	}
+	if _, err := revoked.Verify([]byte(fixtureData), []byte(gpgSig), ""); !errors.Is(err, verify.ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey for a revoked subkey, got %v", err)
+	}
+}
+
+func TestVerify_SSHFixture(t *testing.T) {
+	for _, tc := range []struct{ key, sig, fingerprint string }{
+		{sshEd25519Key, sshEd25519Sig, "SHA256:BF4gcp/EEFfbf7qFMmVxk6L4QkOxk8/JnWppQpzr4fg"},
+		{sshRSAKey, sshRSASig, ""},
+	} {
+		kr := verify.NewKeyring()
+		if err := kr.AddAllowedSigners([]byte("ann@example.org " + tc.key + "\n")); err != nil {
+			t.Fatalf("AddAllowedSigners: %v", err)
+		}
+		res, err := kr.Verify([]byte(fixtureData), []byte(tc.sig), verify.NamespaceFile)
+		if err != nil {
+			t.Fatalf("%s: Verify: %v", tc.key[:7], err)
+		}
+		if tc.fingerprint != "" && res.Fingerprint != tc.fingerprint {
+			t.Fatalf("fingerprint %s, ssh-keygen printed %s", res.Fingerprint, tc.fingerprint)
+		}
+		if _, err := kr.Verify([]byte("tampered\n"), []byte(tc.sig), verify.NamespaceFile); !errors.Is(err, verify.ErrBadSignature) {
+			t.Fatalf("%s: expected ErrBadSignature, got %v", tc.key[:7], err)
+		}
+	}
+}
+
+func TestVerify_OpenPGPValidity(t *testing.T) {
+	data := []byte("payload")
+	primary, sub := newPGPEd25519(t), newPGPEd25519(t)
+	life := func(seconds uint32) []byte {
+		return binary.BigEndian.AppendUint32(nil, seconds)
+	}
+	keyring := func(binding []byte) *verify.KeyringObj {
+		t.Helper()
+		kr := verify.NewKeyring()
+		block := append(pgpPacket(6, primary.body), pgpPacket(13, []byte("Ann"))...)
+		block = append(block, primary.selfCert("Ann")...)
+		block = append(append(block, pgpPacket(14, sub.body)...), binding...)
+		if err := kr.AddOpenPGP(pgpArmor("PUBLIC KEY BLOCK", block)); err != nil {
+			t.Fatal(err)
+		}
+		return kr
+	}
+	bindingPrefix := append(primary.hashPrefix(), sub.hashPrefix()...)
+
+	// Keys are created at 0x65000000, signatures at 0x66000000.
+	expiring := keyring(primary.binding(sub, pgpSub(9, life(0x00800000))))
+	if _, err := expiring.Verify(data, sub.signature(0x00, data), ""); !errors.Is(err, verify.ErrExpired) {
+		t.Fatalf("expected ErrExpired for a subkey expired at signing, got %v", err)
+	}
+	if _, err := expiring.Verify(data, sub.signatureAt(0x00, data, 0x65700000), ""); err != nil {
+		t.Fatalf("a signature made before the key expired must verify: %v", err)
+	}
+
+	kr := keyring(primary.binding(sub))
+	if _, err := kr.Verify(data, sub.signatureAt(0x00, data, 0x66000000, pgpSub(3, life(1))), ""); !errors.Is(err, verify.ErrExpired) {
+		t.Fatalf("expected ErrExpired for an expired signature, got %v", err)
+	}
+	if _, err := kr.Verify(data, sub.signatureAt(0x00, data, 0x64000000), ""); !errors.Is(err, verify.ErrBadSignature) {
+		t.Fatalf("expected ErrBadSignature for a signature older than the key, got %v", err)
+	}
+	if _, err := kr.Verify(data, sub.signatureAt(0x00, data, 0x66000000, pgpSub(0x80|100, nil)), ""); !errors.Is(err, lightweigit.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported for an unknown critical subpacket, got %v", err)
+	}
+	if _, err := kr.Verify(data, sub.signatureAt(0x00, data, 0x66000000, pgpSub(0x80|2, life(0x66000000))), ""); err != nil {
+		t.Fatalf("a critical subpacket that is understood must verify: %v", err)
+	}
+
+	future := uint32(time.Now().Add(time.Hour).Unix())
+	if _, err := kr.Verify(data, sub.signatureAt(0x00, data, future), ""); !errors.Is(err, verify.ErrBadSignature) {
+		t.Fatalf("expected ErrBadSignature for a signature dated in the future, got %v", err)
+	}
+
+	critical := keyring(primary.binding(sub, pgpSub(0x80|100, nil)))
+	if _, err := critical.Verify(data, sub.signature(0x00, data), ""); !errors.Is(err, verify.ErrUnknownKey) {
+		t.Fatalf("a binding with an unknown critical subpacket must not bind, got %v", err)
+	}
+
+	revocation := primary.signature(0x28, bindingPrefix)
+	for name, order := range map[string][]byte{
+		"revocation last":  append(primary.binding(sub), revocation...),
+		"revocation first": append(append([]byte(nil), revocation...), primary.binding(sub)...),
+	} {
+		if _, err := keyring(order).Verify(data, sub.signature(0x00, data), ""); !errors.Is(err, verify.ErrUnknownKey) {
+			t.Fatalf("%s: expected ErrUnknownKey for a revoked subkey, got %v", name, err)
+		}
+	}
+}
+
+func TestVerify_OpenPGPSigningKeys(t *testing.T) {
+	data := []byte("payload")
+	primary, sub := newPGPEd25519(t), newPGPEd25519(t)
+	prefix := append(primary.hashPrefix(), sub.hashPrefix()...)
+	keyring := func(block []byte) *verify.KeyringObj {
+		t.Helper()
+		kr := verify.NewKeyring()
+		if err := kr.AddOpenPGP(pgpArmor("PUBLIC KEY BLOCK", block)); err != nil {
+			t.Fatal(err)
+		}
+		return kr
+	}
+	withSub := func(binding []byte) []byte {
+		block := append(pgpPacket(6, primary.body), pgpPacket(13, []byte("Ann"))...)
+		block = append(block, primary.selfCert("Ann")...)
+		return append(append(block, pgpPacket(14, sub.body)...), binding...)
+	}
+
+	// A binding alone does not make a signing subkey: the subkey must sign
+	// back over this very primary key.
+	noBack := primary.signatureAt(0x18, prefix, 0x66000000, pgpSub(27, []byte{0x02}))
+	if _, err := keyring(withSub(noBack)).Verify(data, sub.signature(0x00, data), ""); !errors.Is(err, verify.ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey without a back-signature, got %v", err)
+	}
+
+	// Someone else's subkey, with the back-signature it made for its own
+	// primary key, cannot be claimed.
+	owner := newPGPEd25519(t)
+	stolen := sub.signatureAt(0x19, append(owner.hashPrefix(), sub.hashPrefix()...), 0x66000000)
+	claim := primary.signatureAt(0x18, prefix, 0x66000000, pgpSub(27, []byte{0x02}), pgpSub(32, stolen[6:]))
+	if _, err := keyring(withSub(claim)).Verify(data, sub.signature(0x00, data), ""); !errors.Is(err, verify.ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey for a claimed subkey, got %v", err)
+	}
+
+	certifyOnly := append(pgpPacket(6, primary.body), pgpPacket(13, []byte("Ann"))...)
+	certifyOnly = append(certifyOnly, primary.selfCert("Ann", 0x01)...)
+	if _, err := keyring(certifyOnly).Verify(data, primary.signature(0x00, data), ""); !errors.Is(err, verify.ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey for a primary key without the sign flag, got %v", err)
+	}
+}
+
+func TestVerify_SSH(t *testing.T) {
+	data := []byte("release artifact\n")
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	for _, priv := range []crypto.Signer{edKey, ecKey} {
+		blob, sig := sshSign(t, priv, data, verify.NamespaceFile)
+		typ := strings.SplitN(string(blob[4:]), "\x00", 2)[0]
+		allowed := "# release keys\nann@example.org,ci@example.org namespaces=\"file,git\" " + typ + " " + base64.StdEncoding.EncodeToString(blob) + " ann\n"
+
+		kr := verify.NewKeyring()
+		if err := kr.AddAllowedSigners([]byte(allowed)); err != nil {
+			t.Fatalf("%s: AddAllowedSigners: %v", typ, err)
+		}
+		res, err := kr.Verify(data, sig, verify.NamespaceFile)
+		if err != nil {
+			t.Fatalf("%s: Verify: %v", typ, err)
+		}
+		if res.Format != verify.FormatSSH || res.Signer != "ann@example.org,ci@example.org" || !strings.HasPrefix(res.Fingerprint, "SHA256:") {
+			t.Fatalf("%s: unexpected result %+v", typ, *res)
+		}
+
+		if _, err := kr.Verify([]byte("tampered"), sig, verify.NamespaceFile); !errors.Is(err, verify.ErrBadSignature) {
+			t.Fatalf("%s: expected ErrBadSignature, got %v", typ, err)
+		}
+		if _, err := kr.Verify(data, sig, verify.NamespaceGit); !errors.Is(err, verify.ErrBadSignature) {
+			t.Fatalf("%s: expected ErrBadSignature for another namespace, got %v", typ, err)
+		}
+
+		gitOnly := verify.NewKeyring()
+		gitOnly.AddAllowedSigners([]byte("ann@example.org namespaces=\"git\" " + typ + " " + base64.StdEncoding.EncodeToString(blob) + "\n"))
+		if _, err := gitOnly.Verify(data, sig, verify.NamespaceFile); !errors.Is(err, verify.ErrUnknownKey) {
+			t.Fatalf("%s: expected ErrUnknownKey outside the allowed namespaces, got %v", typ, err)
+		}
+
+		expired := verify.NewKeyring()
+		expired.AddAllowedSigners([]byte("ann@example.org valid-before=20000101Z " + typ + " " + base64.StdEncoding.EncodeToString(blob) + "\n"))
+		if _, err := expired.Verify(data, sig, verify.NamespaceFile); !errors.Is(err, verify.ErrUnknownKey) {
+			t.Fatalf("%s: expected ErrUnknownKey for an expired line, got %v", typ, err)
+		}
+	}
+}
+
+func TestVerify_Tag(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	payload := "object c1\ntype commit\ntag v1.0.0\ntagger Ann <ann@example.org> 1714557600 +0000\n\nRelease 1.0\n"
+	blob, sig := sshSign(t, priv, []byte(payload), verify.NamespaceGit)
+
+	kr := verify.NewKeyring()
+	kr.AddAllowedSigners([]byte("ann@example.org ssh-ed25519 " + base64.StdEncoding.EncodeToString(blob)))
+
+	d := &lightweigit.TagDetailObj{Name: "v1.0.0", Kind: lightweigit.TagAnnotated, Signature: string(sig), Payload: payload}
+	if _, err := kr.Tag(d); err != nil {
+		t.Fatalf("Tag: %v", err)
+	}
+
+	d.Payload = ""
+	if _, err := kr.Tag(d); !errors.Is(err, lightweigit.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported without a payload, got %v", err)
+	}
+	if _, err := kr.Tag(&lightweigit.TagDetailObj{Name: "v0.9.0"}); !errors.Is(err, verify.ErrNoSignature) {
+		t.Fatalf("expected ErrNoSignature, got %v", err)
+	}
+}
+
+func TestVerify_Asset(t *testing.T) {
+	data := []byte("binary contents")
+	key := newPGPEd25519(t)
+	sig := pgpArmor("SIGNATURE", key.signature(0x00, data))
+
+	recordServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/releases/tags/v1.0.0":
+			w.Write([]byte(`{"tag_name":"v1.0.0","name":"v1.0.0","assets":[
+				{"browser_download_url":"https://github.com/o/r/releases/download/v1.0.0/tool.tar.gz","size":15},
+				{"browser_download_url":"https://github.com/o/r/releases/download/v1.0.0/tool.tar.gz.asc","size":300},
+				{"browser_download_url":"https://github.com/o/r/releases/download/v1.0.0/other.zip","size":3}]}`))
+		case "/o/r/releases/download/v1.0.0/tool.tar.gz":
+			w.Write(data)
+		case "/o/r/releases/download/v1.0.0/tool.tar.gz.asc":
+			w.Write(sig)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	p, _ := global.ParseOffline("https://github.com/o/r")
+	rel, err := p.ReleaseFind("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kr := verify.NewKeyring()
+	if err := kr.AddOpenPGP(key.publicKey("Ann", nil, false)); err != nil {
+		t.Fatal(err)
+	}
+	if s := verify.Sidecar(rel, "tool.tar.gz"); s == nil || s.Name() != "tool.tar.gz.asc" {
+		t.Fatalf("unexpected sidecar %v", s)
+	}
+	if _, err := kr.Asset(context.Background(), rel, "tool.tar.gz"); err != nil {
+		t.Fatalf("Asset: %v", err)
+	}
+	if _, err := kr.Asset(context.Background(), rel, "other.zip"); !errors.Is(err, verify.ErrNoSignature) {
+		t.Fatalf("expected ErrNoSignature, got %v", err)
+	}
+	if _, err := kr.Asset(context.Background(), rel, "missing"); !errors.Is(err, lightweigit.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+func TestVersion_Order(t *testing.T) {
+	// SemVer 2.0 section 11 precedence example, then the tolerated forms.
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "v1.0.0", "1.1", "v2",
+	}
+	for i := 1; i < len(ordered); i++ {
+		if c := lightweigit.CompareVersions(ordered[i-1], ordered[i]); c != -1 {
+			t.Fatalf("%s vs %s: %d", ordered[i-1], ordered[i], c)
+		}
+	}
+
+	if lightweigit.CompareVersions("v1.0.0+build.5", "1.0.0") != 0 {
+		t.Fatal("build metadata must not order")
+	}
+	if lightweigit.CompareVersions("nightly", "v0.0.1") != -1 {
+		t.Fatal("non-versions must sort below versions")
+	}
+}
+
+func TestVersion_Parse(t *testing.T) {
+	v, ok := lightweigit.ParseVersion("v1.2.3-rc.1+meta")
+	if !ok || v.Major != 1 || v.Minor != 2 || v.Patch != 3 || !v.Prerelease() || v.Build != "meta" {
+		t.Fatalf("unexpected %+v %v", v, ok)
+	}
+	if v.String() != "1.2.3-rc.1+meta" {
+		t.Fatalf("String = %s", v)
+	}
+	for _, s := range []string{"", "v", "1.2.3.4", "01.2.3", "1.2.3-", "1.2.3-a..b", "release-1"} {
+		if _, ok := lightweigit.ParseVersion(s); ok {
+			t.Fatalf("%q parsed", s)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+	"github.com/voluminor/lightweigit-loader/target/global"
+	"github.com/voluminor/lightweigit-loader/watch"
+)
+
+// // // // // // // // // // // // // // // //
+
+type fakeReleaseObj struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// fakeForgeObj serves the GitHub release and tag endpoints of o/r from
+// lists the test edits between polls, newest first.
+type fakeForgeObj struct {
+	mu       sync.Mutex
+	releases []fakeReleaseObj
+	tags     []string
+	limited  bool
+	calls    int
+}
+
+func (f *fakeForgeObj) set(fn func(f *fakeForgeObj)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn(f)
+}
+
+func (f *fakeForgeObj) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+
+	if f.limited {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/repos/o/private/") {
+		http.Error(w, `{"message":"Repository access blocked"}`, http.StatusForbidden)
+		return
+	}
+	page := r.URL.Query().Get("page")
+	p := strings.TrimPrefix(r.URL.Path, "/repos/o/r/")
+	switch {
+	case p == "releases/latest":
+		for _, rel := range f.releases {
+			if !rel.Prerelease {
+				json.NewEncoder(w).Encode(rel)
+				return
+			}
+		}
+	case p == "releases":
+		list := []fakeReleaseObj{}
+		if page == "1" {
+			list = append(list, f.releases...)
+		}
+		json.NewEncoder(w).Encode(list)
+		return
+	case strings.HasPrefix(p, "releases/tags/"):
+		for _, rel := range f.releases {
+			if rel.TagName == strings.TrimPrefix(p, "releases/tags/") {
+				json.NewEncoder(w).Encode(rel)
+				return
+			}
+		}
+	case p == "tags":
+		list := []map[string]string{}
+		for i, name := range f.tags {
+			if page == "1" && (r.URL.Query().Get("per_page") != "1" || i == 0) {
+				list = append(list, map[string]string{"name": name})
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+		return
+	case strings.HasPrefix(p, "git/ref/tags/"):
+		for _, name := range f.tags {
+			if name == strings.TrimPrefix(p, "git/ref/tags/") {
+				w.Write([]byte(`{"object":{"sha":"c1","type":"commit"}}`))
+				return
+			}
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func eventList(t *testing.T, w *watch.WatcherObj) []string {
+	t.Helper()
+	var out []string
+	err := w.Poll(context.Background(), func(e watch.EventObj) {
+		kind := "tag"
+		if e.IsRelease() {
+			kind = "release"
+		}
+		out = append(out, e.Type.String()+" "+kind+" "+e.Tag.String())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func expectEvents(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Fatalf("events:\n got  %q\n want %q", got, want)
+	}
+}
+
+// //
+
+func TestWatch_Events(t *testing.T) {
+	f := &fakeForgeObj{
+		releases: []fakeReleaseObj{{TagName: "v1.0.0", Name: "1.0"}},
+		tags:     []string{"v1.0.0"},
+	}
+	recordServer(t, f.serve)
+
+	p, _ := global.ParseOffline("https://github.com/o/r")
+	var errs []error
+	w, err := watch.New(watch.OptionsObj{OnError: func(_ lightweigit.ProviderInterface, err error) {
+		errs = append(errs, err)
+	}}, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectEvents(t, eventList(t, w))
+	expectEvents(t, eventList(t, w))
+
+	f.set(func(f *fakeForgeObj) {
+		f.releases = append([]fakeReleaseObj{
+			{TagName: "v1.2.0", Name: "1.2"},
+			{TagName: "v1.2.0-rc1", Name: "1.2 rc", Prerelease: true},
+			{TagName: "v1.1.0", Name: "1.1"},
+		}, f.releases...)
+		f.tags = append([]string{"v1.2.0", "v1.2.0-rc1", "v1.1.0"}, f.tags...)
+	})
+	expectEvents(t, eventList(t, w),
+		"created release v1.1.0", "created release v1.2.0",
+		"created tag v1.1.0", "created tag v1.2.0-rc1", "created tag v1.2.0")
+
+	f.set(func(f *fakeForgeObj) { f.releases[0].Body = "Fixed notes" })
+	expectEvents(t, eventList(t, w), "updated release v1.2.0")
+
+	f.set(func(f *fakeForgeObj) {
+		f.releases = f.releases[1:]
+		f.tags = f.tags[1:]
+	})
+	expectEvents(t, eventList(t, w), "deleted release v1.2.0", "deleted tag v1.2.0")
+	expectEvents(t, eventList(t, w))
+
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func TestWatch_InitialAndStore(t *testing.T) {
+	f := &fakeForgeObj{
+		releases: []fakeReleaseObj{{TagName: "v2.0.0", Name: "2.0"}},
+		tags:     []string{"v2.0.0"},
+	}
+	recordServer(t, f.serve)
+	p, _ := global.ParseOffline("https://github.com/o/r")
+
+	store := watch.NewDirStore(t.TempDir())
+	w, _ := watch.New(watch.OptionsObj{Store: store, Initial: true}, p)
+	expectEvents(t, eventList(t, w), "created release v2.0.0", "created tag v2.0.0")
+
+	// A new watcher over the same store picks up where the last one left.
+	f.set(func(f *fakeForgeObj) {
+		f.releases = append([]fakeReleaseObj{{TagName: "v2.1.0", Name: "2.1"}}, f.releases...)
+	})
+	w2, _ := watch.New(watch.OptionsObj{Store: store, Initial: true}, p)
+	expectEvents(t, eventList(t, w2), "created release v2.1.0")
+
+	data, err := store.Load(lightweigit.Identity(p).Key())
+	if err != nil || len(data) == 0 || data[0] != byte(target.ModWatchState) {
+		t.Fatalf("expected a watch state blob, got %d bytes, %v", len(data), err)
+	}
+}
+
+func TestWatch_BadState(t *testing.T) {
+	f := &fakeForgeObj{releases: []fakeReleaseObj{{TagName: "v1.0.0"}}, tags: []string{"v1.0.0"}}
+	recordServer(t, f.serve)
+	p, _ := global.ParseOffline("https://github.com/o/r")
+
+	store := watch.NewMemoryStore()
+	key := lightweigit.Identity(p).Key()
+	if err := store.Save(key, []byte("not a state")); err != nil {
+		t.Fatal(err)
+	}
+	var errs []error
+	w, _ := watch.New(watch.OptionsObj{Store: store, OnError: func(_ lightweigit.ProviderInterface, err error) {
+		errs = append(errs, err)
+	}}, p)
+
+	expectEvents(t, eventList(t, w))
+	f.set(func(f *fakeForgeObj) {
+		f.releases = append([]fakeReleaseObj{{TagName: "v1.1.0"}}, f.releases...)
+	})
+	expectEvents(t, eventList(t, w), "created release v1.1.0")
+
+	if len(errs) != 1 {
+		t.Fatalf("an unreadable state must be reported once, got %v", errs)
+	}
+	if data, _ := store.Load(key); len(data) == 0 || data[0] != byte(target.ModWatchState) {
+		t.Fatal("the unreadable state must be replaced")
+	}
+}
+
+func TestWatch_RateLimit(t *testing.T) {
+	f := &fakeForgeObj{limited: true}
+	recordServer(t, f.serve)
+
+	a, _ := global.ParseOffline("https://github.com/o/r")
+	b, _ := global.ParseOffline("https://github.com/o/other")
+	var errs []error
+	w, _ := watch.New(watch.OptionsObj{OnError: func(_ lightweigit.ProviderInterface, err error) {
+		errs = append(errs, err)
+	}}, a, b)
+
+	expectEvents(t, eventList(t, w))
+	if len(errs) != 1 || !errors.Is(errs[0], lightweigit.ErrTooManyRequests) {
+		t.Fatalf("expected one rate-limit error for the host, got %v", errs)
+	}
+
+	calls := f.calls
+	expectEvents(t, eventList(t, w))
+	if f.calls != calls || len(errs) != 1 {
+		t.Fatalf("a rate-limited host must not be polled again before its backoff ends")
+	}
+}
+
+func TestWatch_ForbiddenRepo(t *testing.T) {
+	f := &fakeForgeObj{releases: []fakeReleaseObj{{TagName: "v1.0.0"}}, tags: []string{"v1.0.0"}}
+	recordServer(t, f.serve)
+
+	blocked, _ := global.ParseOffline("https://github.com/o/private")
+	p, _ := global.ParseOffline("https://github.com/o/r")
+	var errs []error
+	w, _ := watch.New(watch.OptionsObj{OnError: func(_ lightweigit.ProviderInterface, err error) {
+		errs = append(errs, err)
+	}}, blocked, p)
+
+	expectEvents(t, eventList(t, w))
+	f.set(func(f *fakeForgeObj) {
+		f.releases = append([]fakeReleaseObj{{TagName: "v1.1.0"}}, f.releases...)
+	})
+	expectEvents(t, eventList(t, w), "created release v1.1.0")
+
+	if len(errs) != 2 || !errors.Is(errs[1], lightweigit.ErrForbidden) {
+		t.Fatalf("the blocked repository must fail on each poll without pausing the host, got %v", errs)
+	}
+}
+
+func TestWatch_Stream(t *testing.T) {
+	f := &fakeForgeObj{releases: []fakeReleaseObj{{TagName: "v1.0.0"}}}
+	recordServer(t, f.serve)
+	p, _ := global.ParseOffline("https://github.com/o/r")
+
+	w, _ := watch.New(watch.OptionsObj{Initial: true}, p)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := make(chan watch.EventObj)
+	errc := make(chan error, 1)
+	go func() { errc <- w.Stream(ctx, out) }()
+
+	e := <-out
+	if e.Type != watch.EventCreated || e.Release == nil || e.Tag.String() != "v1.0.0" {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
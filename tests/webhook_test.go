@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/voluminor/lightweigit-loader/watch"
+	"github.com/voluminor/lightweigit-loader/webhook"
+)
+
+// // // // // // // // // // // // // // // //
+
+const hookSecret = "s3cret"
+
+func hookSign(body string) string {
+	m := hmac.New(sha256.New, []byte(hookSecret))
+	m.Write([]byte(body))
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// deliver posts body with headers to a handler and returns the status and
+// the events it reported.
+func deliver(t *testing.T, opts webhook.OptionsObj, body string, headers map[string]string) (int, []watch.EventObj, error) {
+	t.Helper()
+	var (
+		got     []watch.EventObj
+		hookErr error
+	)
+	opts.OnEvent = func(e watch.EventObj) { got = append(got, e) }
+	opts.OnError = func(_ *http.Request, err error) { hookErr = err }
+
+	req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	webhook.NewHandler(opts).ServeHTTP(rec, req)
+	return rec.Code, got, hookErr
+}
+
+func describe(events []watch.EventObj) string {
+	var parts []string
+	for _, e := range events {
+		kind := "tag"
+		if e.IsRelease() {
+			kind = "release"
+		}
+		parts = append(parts, e.Type.String()+" "+kind+" "+e.Tag.String()+" "+e.Provider.Type()+":"+e.Provider.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// //
+
+func TestWebhook_GitHub(t *testing.T) {
+	opts := webhook.OptionsObj{Secret: hookSecret}
+	release := `{"action":"published","repository":{"html_url":"https://github.com/o/r"},
+		"release":{"tag_name":"v1.0.0","name":"One","body":"notes","assets":[
+			{"browser_download_url":"https://github.com/o/r/releases/download/v1.0.0/tool.zip","size":3}]}}`
+
+	code, events, err := deliver(t, opts, release, map[string]string{
+		"X-GitHub-Event":      "release",
+		"X-Hub-Signature-256": "sha256=" + hookSign(release),
+	})
+	if code != http.StatusNoContent || err != nil {
+		t.Fatalf("status %d, error %v", code, err)
+	}
+	if got := describe(events); got != "created release v1.0.0 github:o/r" {
+		t.Fatalf("unexpected events %q", got)
+	}
+	rel := events[0].Release
+	if rel.Name() != "One" || rel.BodyMD() != "notes" || len(rel.Assets()) != 1 || rel.Assets()[0].Name() != "tool.zip" {
+		t.Fatalf("release not read from the payload")
+	}
+
+	push := `{"ref":"refs/tags/v0.9.0","deleted":true,"repository":{"html_url":"https://github.com/o/r"}}`
+	_, events, _ = deliver(t, opts, push, map[string]string{
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": "sha256=" + hookSign(push),
+	})
+	if got := describe(events); got != "deleted tag v0.9.0 github:o/r" {
+		t.Fatalf("unexpected events %q", got)
+	}
+
+	branch := `{"ref":"refs/heads/main","repository":{"html_url":"https://github.com/o/r"}}`
+	code, events, _ = deliver(t, opts, branch, map[string]string{
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": "sha256=" + hookSign(branch),
+	})
+	if code != http.StatusNoContent || len(events) != 0 {
+		t.Fatalf("a branch push must be accepted without events, got %d %q", code, describe(events))
+	}
+}
+
+func TestWebhook_Signatures(t *testing.T) {
+	body := `{"action":"published","repository":{"html_url":"https://github.com/o/r"},"release":{"tag_name":"v1"}}`
+	headers := map[string]string{"X-GitHub-Event": "release", "X-Hub-Signature-256": "sha256=" + hookSign(body+" ")}
+
+	code, events, err := deliver(t, webhook.OptionsObj{Secret: hookSecret}, body, headers)
+	if code != http.StatusUnauthorized || len(events) != 0 || !errors.Is(err, webhook.ErrSignature) {
+		t.Fatalf("a bad signature must be refused, got %d, %v", code, err)
+	}
+
+	code, _, err = deliver(t, webhook.OptionsObj{}, body, headers)
+	if code != http.StatusUnauthorized || !errors.Is(err, webhook.ErrSignature) {
+		t.Fatalf("a repository without a secret must be refused, got %d, %v", code, err)
+	}
+
+	code, events, _ = deliver(t, webhook.OptionsObj{AllowUnsigned: true}, body, headers)
+	if code != http.StatusNoContent || len(events) != 1 {
+		t.Fatalf("AllowUnsigned must accept the delivery, got %d", code)
+	}
+
+	perRepo := webhook.OptionsObj{Secret: "other", Secrets: map[string]string{"github:github.com/o/r": hookSecret}}
+	headers["X-Hub-Signature-256"] = "sha256=" + hookSign(body)
+	if code, _, err := deliver(t, perRepo, body, headers); code != http.StatusNoContent {
+		t.Fatalf("the per-repository secret must win, got %d, %v", code, err)
+	}
+
+	if code, _, err := deliver(t, webhook.OptionsObj{Secret: hookSecret}, body, nil); code != http.StatusBadRequest || !errors.Is(err, webhook.ErrUnknownForge) {
+		t.Fatalf("a request without forge headers must be refused, got %d, %v", code, err)
+	}
+	if code, _, err := deliver(t, webhook.OptionsObj{Secret: hookSecret, MaxBody: 10}, body, headers); code != http.StatusRequestEntityTooLarge || !errors.Is(err, webhook.ErrBodyTooLarge) {
+		t.Fatalf("an oversized body must be refused, got %d, %v", code, err)
+	}
+
+	rec := httptest.NewRecorder()
+	webhook.NewHandler(webhook.OptionsObj{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hook", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET must be refused, got %d", rec.Code)
+	}
+}
+
+func TestWebhook_GitLab(t *testing.T) {
+	opts := webhook.OptionsObj{Secret: hookSecret}
+	headers := map[string]string{"X-Gitlab-Event": "Release Hook", "X-Gitlab-Token": hookSecret}
+
+	release := `{"object_kind":"release","action":"create","tag":"v2.0.0","name":"Two","description":"notes",
+		"released_at":"2020-11-02 12:55:12 UTC","project":{"id":7,"web_url":"https://gitlab.com/group/repo"},
+		"assets":{"links":[{"id":1,"name":"bin","url":"https://gitlab.com/group/repo/-/releases/v2.0.0/downloads/bin","link_type":"package"}]}}`
+	_, events, err := deliver(t, opts, release, headers)
+	if got := describe(events); got != "created release v2.0.0 gitlab:group/repo" {
+		t.Fatalf("unexpected events %q, %v", got, err)
+	}
+	if rel := events[0].Release; rel.BodyMD() != "notes" || rel.IsPrerelease() || len(rel.Assets()) != 1 {
+		t.Fatalf("release not read from the payload")
+	}
+
+	headers["X-Gitlab-Event"] = "Tag Push Hook"
+	push := `{"object_kind":"tag_push","ref":"refs/tags/v2.0.0","before":"0000000000000000000000000000000000000000",
+		"after":"82b3d5ae55f7080f1e6022629cdb57bfae7cccc7","project":{"web_url":"https://gitlab.com/group/repo"}}`
+	_, events, _ = deliver(t, opts, push, headers)
+	if got := describe(events); got != "created tag v2.0.0 gitlab:group/repo" {
+		t.Fatalf("unexpected events %q", got)
+	}
+
+	headers["X-Gitlab-Token"] = "wrong"
+	if code, _, _ := deliver(t, opts, push, headers); code != http.StatusUnauthorized {
+		t.Fatalf("a wrong token must be refused, got %d", code)
+	}
+}
+
+func TestWebhook_Gitea(t *testing.T) {
+	opts := webhook.OptionsObj{Secret: hookSecret}
+
+	release := `{"action":"published","repository":{"html_url":"https://git.example.org/o/r"},
+		"release":{"tag_name":"v3.0.0","name":"Three","prerelease":true}}`
+	_, events, err := deliver(t, opts, release, map[string]string{
+		"X-GitHub-Event":    "release",
+		"X-Gitea-Event":     "release",
+		"X-Gitea-Signature": hookSign(release),
+	})
+	if got := describe(events); got != "created release v3.0.0 gitea:o/r" {
+		t.Fatalf("unexpected events %q, %v", got, err)
+	}
+	if !events[0].Release.IsPrerelease() {
+		t.Fatalf("pre-release flag lost")
+	}
+
+	draft := `{"action":"published","repository":{"html_url":"https://git.example.org/o/r"},"release":{"tag_name":"v4","draft":true}}`
+	if _, events, _ := deliver(t, opts, draft, map[string]string{"X-Gitea-Event": "release", "X-Gitea-Signature": hookSign(draft)}); len(events) != 0 {
+		t.Fatalf("drafts must not be reported")
+	}
+
+	create := `{"ref":"v3.0.1","ref_type":"tag","repository":{"html_url":"https://git.example.org/o/r"}}`
+	_, events, err = deliver(t, opts, create, map[string]string{
+		"X-Forgejo-Event":     "create",
+		"X-Forgejo-Signature": hookSign(create),
+	})
+	if got := describe(events); got != "created tag v3.0.1 forgejo:o/r" {
+		t.Fatalf("unexpected events %q, %v", got, err)
+	}
+}
+
+func TestWebhook_Bitbucket(t *testing.T) {
+	push := `{"repository":{"links":{"html":{"href":"https://bitbucket.org/ws/repo"}}},"push":{"changes":[
+		{"new":{"type":"tag","name":"v5.0.0"},"old":null},
+		{"new":{"type":"branch","name":"main"},"old":{"type":"branch","name":"main"}},
+		{"new":null,"old":{"type":"tag","name":"v4.9.0"}}]}}`
+
+	_, events, err := deliver(t, webhook.OptionsObj{Secret: hookSecret}, push, map[string]string{
+		"X-Event-Key":     "repo:push",
+		"X-Hub-Signature": "sha256=" + hookSign(push),
+	})
+	want := "created release v5.0.0 bitbucket:ws/repo; created tag v5.0.0 bitbucket:ws/repo; " +
+		"deleted release v4.9.0 bitbucket:ws/repo; deleted tag v4.9.0 bitbucket:ws/repo"
+	if got := describe(events); got != want {
+		t.Fatalf("events:\n got  %q\n want %q (%v)", got, want, err)
+	}
+}
@@ -12,6 +12,9 @@ import (
 // as ErrResponseTooLarge instead of being truncated and mis-decoded.
 const maxJSONBody = 8 << 20
 
+// MaxFileSize caps a file read with GetRaw or from a local repository.
+const MaxFileSize = 8 << 20
+
 var (
 	HttpClient = &http.Client{Timeout: 4 * time.Second}
 
@@ -20,4 +23,6 @@ var (
 	ErrTooManyRequests  = errors.New("too many requests")
 	ErrModTag           = errors.New("invalid tag")
 	ErrResponseTooLarge = errors.New("response too large")
+	ErrHostMismatch     = errors.New("host not handled by provider")
+	ErrUnsupported      = errors.New("not supported by provider")
 )
@@ -0,0 +1,91 @@
+package vanity
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+// ImportPath is the path Resolve was called with, normalised.
+func (obj *ImportObj) ImportPath() string {
+	return obj.path
+}
+
+// Prefix is the repository root import path from the go-import tag.
+func (obj *ImportObj) Prefix() string {
+	return obj.prefix
+}
+
+func (obj *ImportObj) VCS() string {
+	return obj.vcs
+}
+
+// Repo is the repository URL from the go-import tag.
+func (obj *ImportObj) Repo() *url.URL {
+	u := obj.repo
+	return &u
+}
+
+// Home is the web page from the go-source tag; "" when there is none.
+func (obj *ImportObj) Home() string {
+	return obj.home
+}
+
+// Subdir is the directory of the package inside the repository: the
+// go-import subdirectory joined with the rest of the import path after
+// Prefix, without the /vN major version element.
+func (obj *ImportObj) Subdir() string {
+	d := path.Join(obj.root, obj.subdir)
+	if d == "." {
+		return ""
+	}
+	return d
+}
+
+// Major is the major version from a /vN element right after Prefix (or at
+// the end of Prefix), N >= 2; 0 otherwise. Whether vN is a directory or a
+// branch convention in the repository is not known from the path alone.
+func (obj *ImportObj) Major() int {
+	return obj.major
+}
+
+func (obj *ImportObj) String() string {
+	return obj.path
+}
+
+// //
+
+// majorOf returns N for a "vN" path element (N >= 2), 0 otherwise.
+func majorOf(elem string) int {
+	if len(elem) < 2 || elem[0] != 'v' || elem[1] == '0' {
+		return 0
+	}
+	n, err := strconv.Atoi(elem[1:])
+	if err != nil || n < 2 {
+		return 0
+	}
+	return n
+}
+
+// splitSubdir turns the import path after the repository prefix into the
+// package directory, taking a leading /vN off as the major version. A
+// prefix that itself ends in /vN gives the major too.
+func splitSubdir(prefix, rest string) (string, int) {
+	major := majorOf(path.Base(prefix))
+	first, tail := rest, ""
+	if i := strings.Index(rest, "/"); i >= 0 {
+		first, tail = rest[:i], rest[i+1:]
+	}
+	if n := majorOf(first); n != 0 {
+		return tail, n
+	}
+	return rest, major
+}
+
+// hasPathPrefix reports whether prefix is p or a parent of p.
+func hasPathPrefix(p, prefix string) bool {
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
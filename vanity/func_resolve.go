@@ -0,0 +1,241 @@
+package vanity
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+	"github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+// knownHosts serve their repositories at /owner/repo and need no lookup,
+// exactly as in the go command.
+var knownHosts = map[string]bool{
+	"github.com":    true,
+	"bitbucket.org": true,
+}
+
+func fetchMeta(ctx context.Context, importPath string) ([]metaImportObj, []metaSourceObj, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+importPath+"?go-get=1", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("%s %s; go-get", target.Name, target.Version))
+
+	resp, err := lightweigit.HttpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	// Like the go command, read the meta tags of error pages too: vanity
+	// servers commonly answer subpackages with 404 and the right tags.
+	imports, sources, err := parseMeta(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %s: %w", importPath, resp.Status, err)
+	}
+	if len(imports) == 0 {
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return nil, nil, fmt.Errorf("%s: no go-import meta tag: %w", importPath, lightweigit.ErrNotFound)
+		case http.StatusForbidden:
+			return nil, nil, fmt.Errorf("%s: %s: %w", importPath, resp.Status, lightweigit.ErrForbidden)
+		case http.StatusTooManyRequests:
+			return nil, nil, fmt.Errorf("%s: %s: %w", importPath, resp.Status, lightweigit.ErrTooManyRequests)
+		}
+		return nil, nil, fmt.Errorf("%s: %s: no go-import meta tag: %w", importPath, resp.Status, lightweigit.ErrNotFound)
+	}
+	return imports, sources, nil
+}
+
+// parseMeta reads go-import and go-source tags from the head of an HTML
+// page, the way the go command does: a lenient XML tokenizer, stopping at
+// </head> or <body>.
+func parseMeta(r io.Reader) ([]metaImportObj, []metaSourceObj, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "utf-8", "ascii", "us-ascii":
+			return input, nil
+		}
+		return nil, fmt.Errorf("can't decode XML document using charset %q", charset)
+	}
+
+	var (
+		imports []metaImportObj
+		sources []metaSourceObj
+	)
+	for {
+		t, err := d.RawToken()
+		if err != nil {
+			if err == io.EOF || len(imports) > 0 {
+				return imports, sources, nil
+			}
+			return nil, nil, err
+		}
+		if e, ok := t.(xml.StartElement); ok && strings.EqualFold(e.Name.Local, "body") {
+			return imports, sources, nil
+		}
+		if e, ok := t.(xml.EndElement); ok && strings.EqualFold(e.Name.Local, "head") {
+			return imports, sources, nil
+		}
+
+		e, ok := t.(xml.StartElement)
+		if !ok || !strings.EqualFold(e.Name.Local, "meta") {
+			continue
+		}
+
+		f := strings.Fields(attrValue(e.Attr, "content"))
+		switch attrValue(e.Attr, "name") {
+		case "go-import":
+			if len(f) == 3 || len(f) == 4 {
+				m := metaImportObj{Prefix: f[0], VCS: f[1], Repo: f[2]}
+				if len(f) == 4 {
+					m.Subdir = f[3]
+				}
+				imports = append(imports, m)
+			}
+		case "go-source":
+			if len(f) == 4 {
+				sources = append(sources, metaSourceObj{Prefix: f[0], Home: f[1]})
+			}
+		}
+	}
+}
+
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// matchImport picks the git go-import tag covering p. "mod" tags only
+// name a module proxy and are skipped; two different git tags for the same
+// path are an error, as in the go command.
+func matchImport(p string, imports []metaImportObj) (metaImportObj, error) {
+	var (
+		found metaImportObj
+		ok    bool
+		vcs   []string
+	)
+	for _, m := range imports {
+		if !hasPathPrefix(p, m.Prefix) {
+			continue
+		}
+		vcs = append(vcs, m.VCS)
+		if m.VCS != "git" {
+			continue
+		}
+		if ok && (found.Prefix != m.Prefix || found.Repo != m.Repo) {
+			return metaImportObj{}, fmt.Errorf("%s: multiple go-import git tags: %s and %s", p, found.Repo, m.Repo)
+		}
+		found, ok = m, true
+	}
+
+	if !ok {
+		if len(vcs) > 0 {
+			return metaImportObj{}, fmt.Errorf("%s: no git repository in go-import (found %s): %w", p, strings.Join(vcs, ", "), lightweigit.ErrNotFound)
+		}
+		return metaImportObj{}, fmt.Errorf("%s: no go-import meta tag for this path: %w", p, lightweigit.ErrNotFound)
+	}
+	return found, nil
+}
+
+// // // //
+
+// Resolve maps a Go import path to its repository. Paths on github.com and
+// bitbucket.org are mapped directly; everything else is looked up through
+// the go-import meta tag served at https://<path>?go-get=1.
+func Resolve(ctx context.Context, importPath string) (*ImportObj, error) {
+	p := strings.TrimSpace(importPath)
+	if i := strings.Index(p, "://"); i >= 0 {
+		p = p[i+3:]
+	}
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	p = strings.Trim(p, "/")
+	if p == "" || !strings.Contains(p, ".") {
+		return nil, fmt.Errorf("not a remote import path: %q", importPath)
+	}
+
+	obj := &ImportObj{path: p}
+
+	segs := strings.Split(p, "/")
+	if knownHosts[strings.ToLower(segs[0])] {
+		if len(segs) < 3 {
+			return nil, fmt.Errorf("expected host/owner/repo, received: %q", importPath)
+		}
+		obj.prefix = strings.Join(segs[:3], "/")
+		obj.vcs = "git"
+		obj.repo = url.URL{Scheme: "https", Host: segs[0], Path: "/" + segs[1] + "/" + segs[2]}
+		obj.subdir, obj.major = splitSubdir(obj.prefix, strings.Join(segs[3:], "/"))
+		return obj, nil
+	}
+
+	imports, sources, err := fetchMeta(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	m, err := matchImport(p, imports)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := url.Parse(m.Repo)
+	if err != nil {
+		return nil, fmt.Errorf("%s: go-import repository %q: %w", p, m.Repo, err)
+	}
+
+	obj.prefix = m.Prefix
+	obj.vcs = m.VCS
+	obj.repo = *repo
+	obj.root = m.Subdir
+	obj.subdir, obj.major = splitSubdir(m.Prefix, strings.TrimPrefix(strings.TrimPrefix(p, m.Prefix), "/"))
+	for _, s := range sources {
+		if s.Prefix == m.Prefix {
+			obj.home = s.Home
+			break
+		}
+	}
+	return obj, nil
+}
+
+// Parse resolves importPath and hands the repository to global.Parse. When
+// the go-import URL is not understood by any provider, the go-source home
+// page is tried.
+func Parse(ctx context.Context, importPath string) (lightweigit.ProviderInterface, *ImportObj, error) {
+	obj, err := Resolve(ctx, importPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := global.Parse(obj.repo.String())
+	if err == nil {
+		return p, obj, nil
+	}
+	if obj.home != "" && obj.home != obj.repo.String() {
+		if p, herr := global.Parse(obj.home); herr == nil {
+			return p, obj, nil
+		}
+	}
+	return nil, obj, fmt.Errorf("%s: %w", obj.path, err)
+}
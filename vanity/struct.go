@@ -0,0 +1,29 @@
+package vanity
+
+import (
+	"net/url"
+)
+
+// // // // // // // // // // // // // // // //
+
+// ImportObj is a Go import path mapped to the repository that holds it.
+type ImportObj struct {
+	path   string
+	prefix string
+	vcs    string
+	repo   url.URL
+	root   string
+	subdir string
+	major  int
+	home   string
+}
+
+// metaImportObj is one go-import meta tag: "prefix vcs repo [subdir]".
+type metaImportObj struct {
+	Prefix, VCS, Repo, Subdir string
+}
+
+// metaSourceObj is one go-source meta tag: "prefix home directory file".
+type metaSourceObj struct {
+	Prefix, Home string
+}
@@ -0,0 +1,153 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// NamespaceGit and NamespaceFile are the SSH signature namespaces git uses
+// for commits and tags and ssh-keygen -Y sign uses by convention for files.
+const (
+	NamespaceGit  = "git"
+	NamespaceFile = "file"
+)
+
+// sidecarExts are the signature files looked for next to an asset.
+var sidecarExts = []string{".sig", ".asc"}
+
+// //
+
+func NewKeyring() *KeyringObj {
+	return &KeyringObj{}
+}
+
+// AddOpenPGP adds the public keys in data, armored or binary, as gpg
+// --export writes them. Only version 4 keys are read; subkeys need a
+// binding signature from their primary key.
+func (k *KeyringObj) AddOpenPGP(data []byte) error {
+	_, err := k.addOpenPGP(data)
+	return err
+}
+
+// AddAllowedSigners adds the keys of an allowed_signers file, the
+// format git's gpg.ssh.allowedSignersFile and ssh-keygen -Y verify read.
+func (k *KeyringObj) AddAllowedSigners(data []byte) error {
+	_, err := k.addAllowedSigners(data)
+	return err
+}
+
+// //
+
+// Verify checks sig, a detached OpenPGP signature (armored or binary) or
+// an SSH signature, over data. namespace only applies to SSH signatures.
+func (k *KeyringObj) Verify(data, sig []byte, namespace string) (*ResultObj, error) {
+	return k.VerifyReader(bytes.NewReader(data), sig, namespace)
+}
+
+// VerifyReader is Verify over data read from r, which is hashed as it
+// streams rather than held in memory.
+func (k *KeyringObj) VerifyReader(r io.Reader, sig []byte, namespace string) (*ResultObj, error) {
+	if len(bytes.TrimSpace(sig)) == 0 {
+		return nil, ErrNoSignature
+	}
+	if bytes.Contains(sig, []byte(sshArmorBegin)) {
+		return k.verifySSH(r, sig, namespace)
+	}
+	return k.verifyPGP(r, sig)
+}
+
+// Tag checks the signature of an annotated tag against the signed tag
+// object. Providers that do not serve the object (Payload), such as
+// GitLab, cannot be checked here; d.Verification may still hold the
+// forge's own verdict.
+func (k *KeyringObj) Tag(d *lightweigit.TagDetailObj) (*ResultObj, error) {
+	if d.Signature == "" {
+		return nil, fmt.Errorf("tag %s: %w", d.Name, ErrNoSignature)
+	}
+	if d.Payload == "" {
+		return nil, fmt.Errorf("tag %s: signed object not served: %w", d.Name, lightweigit.ErrUnsupported)
+	}
+	return k.Verify([]byte(d.Payload), []byte(d.Signature), NamespaceGit)
+}
+
+// //
+
+// Sidecar returns the signature asset published next to the asset named
+// name, name.sig or name.asc, or nil.
+func Sidecar(rel lightweigit.ProviderReleaseInterface, name string) lightweigit.ProviderReleaseAssetInterface {
+	assets := rel.Assets()
+	for _, ext := range sidecarExts {
+		for _, a := range assets {
+			if a.Name() == name+ext {
+				return a
+			}
+		}
+	}
+	return nil
+}
+
+// Asset downloads the asset named name of rel and its sidecar signature
+// and checks them.
+func (k *KeyringObj) Asset(ctx context.Context, rel lightweigit.ProviderReleaseInterface, name string) (*ResultObj, error) {
+	var asset lightweigit.ProviderReleaseAssetInterface
+	for _, a := range rel.Assets() {
+		if a.Name() == name {
+			asset = a
+			break
+		}
+	}
+	if asset == nil {
+		return nil, fmt.Errorf("asset %s: %w", name, lightweigit.ErrNotFound)
+	}
+
+	sig := Sidecar(rel, name)
+	if sig == nil {
+		return nil, fmt.Errorf("asset %s: %w", name, ErrNoSignature)
+	}
+	return k.VerifyAsset(ctx, asset, sig)
+}
+
+// VerifyAsset checks asset against the signature in the asset sig. The
+// signature is read whole (up to 1 MiB); the asset is streamed through the
+// hash. SSH signatures must be in the "file" namespace.
+func (k *KeyringObj) VerifyAsset(ctx context.Context, asset, sig lightweigit.ProviderReleaseAssetInterface) (*ResultObj, error) {
+	sigBody, err := open(ctx, sig)
+	if err != nil {
+		return nil, err
+	}
+	sigData, err := io.ReadAll(io.LimitReader(sigBody, maxSidecar+1))
+	sigBody.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(sigData) > maxSidecar {
+		return nil, fmt.Errorf("signature %s: over %d bytes: %w", sig.Name(), maxSidecar, lightweigit.ErrResponseTooLarge)
+	}
+
+	body, err := open(ctx, asset)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	res, err := k.VerifyReader(body, sigData, NamespaceFile)
+	if err != nil {
+		return nil, fmt.Errorf("asset %s: %w", asset.Name(), err)
+	}
+	return res, nil
+}
+
+// open starts the download of an asset.
+func open(ctx context.Context, a lightweigit.ProviderReleaseAssetInterface) (io.ReadCloser, error) {
+	body, err := lightweigit.Download(ctx, a.URL())
+	if err != nil {
+		return nil, fmt.Errorf("asset %s: %w", a.Name(), err)
+	}
+	return body, nil
+}
@@ -0,0 +1,873 @@
+package verify
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+const (
+	pgpTagSignature = 2
+	pgpTagPublicKey = 6
+	pgpTagUserID    = 13
+	pgpTagSubkey    = 14
+	pgpTagUserAttr  = 17
+
+	pgpSigBinary      = 0x00
+	pgpSigText        = 0x01
+	pgpSigCertFirst   = 0x10
+	pgpSigCertLast    = 0x13
+	pgpSigSubkey      = 0x18
+	pgpSigPrimaryBind = 0x19
+	pgpSigDirect      = 0x1f
+	pgpSigKeyRevoke   = 0x20
+	pgpSigSubkeyRevok = 0x28
+
+	pgpAlgoRSA        = 1
+	pgpAlgoRSASign    = 3
+	pgpAlgoECDSA      = 19
+	pgpAlgoEdDSA      = 22
+	pgpAlgoEd25519    = 27
+	pgpSubCreated     = 2
+	pgpSubSigExpires  = 3
+	pgpSubKeyExpires  = 9
+	pgpSubIssuer      = 16
+	pgpSubKeyFlags    = 27
+	pgpSubEmbedded    = 32
+	pgpSubIssuerFP    = 33
+	pgpFlagSign       = 0x02
+	pgpArmorSignature = "SIGNATURE"
+	pgpArmorPublicKey = "PUBLIC KEY BLOCK"
+)
+
+// pgpHashes are the digests accepted in signatures; SHA-1 and MD5 are not.
+var pgpHashes = map[byte]crypto.Hash{
+	8:  crypto.SHA256,
+	9:  crypto.SHA384,
+	10: crypto.SHA512,
+	11: crypto.SHA224,
+}
+
+// pgpCurves maps the OIDs of ECDSA keys to their curves.
+var pgpCurves = map[string]elliptic.Curve{
+	"2a8648ce3d030107": elliptic.P256(),
+	"2b81040022":       elliptic.P384(),
+	"2b81040023":       elliptic.P521(),
+}
+
+const oidEd25519 = "2b06010401da470f01"
+
+// pgpSubKnown are the hashed subpackets that may be marked critical: the
+// ones read here and preferences that do not bear on validity. A critical
+// subpacket of any other type voids the signature.
+var pgpSubKnown = map[byte]bool{
+	pgpSubCreated:    true,
+	pgpSubSigExpires: true,
+	pgpSubKeyExpires: true,
+	pgpSubIssuer:     true,
+	pgpSubKeyFlags:   true,
+	pgpSubEmbedded:   true,
+	pgpSubIssuerFP:   true,
+	11:               true, // preferred symmetric algorithms
+	21:               true, // preferred hashes
+	22:               true, // preferred compression
+	23:               true, // key server preferences
+	25:               true, // primary user ID
+	30:               true, // features
+}
+
+// //
+
+// dearmor returns the bodies of the "-----BEGIN PGP <kind>-----" blocks in
+// data, checking the CRC-24 where one is given.
+func dearmor(data []byte, kind string) ([][]byte, error) {
+	begin, end := "-----BEGIN PGP "+kind+"-----", "-----END PGP "+kind+"-----"
+
+	var out [][]byte
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64<<10), len(data)+1)
+	for sc.Scan() {
+		if strings.TrimSpace(sc.Text()) != begin {
+			continue
+		}
+
+		var b64, crc strings.Builder
+		headers, closed := true, false
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == end {
+				closed = true
+				break
+			}
+			if headers {
+				if line == "" || strings.Contains(line, ": ") {
+					headers = line != ""
+					continue
+				}
+				headers = false
+			}
+			if strings.HasPrefix(line, "=") && len(line) == 5 {
+				crc.WriteString(line[1:])
+				continue
+			}
+			b64.WriteString(line)
+		}
+		if !closed {
+			return nil, errors.New("openpgp armor: missing end line")
+		}
+
+		body, err := base64.StdEncoding.DecodeString(b64.String())
+		if err != nil {
+			return nil, fmt.Errorf("openpgp armor: %w", err)
+		}
+		if crc.Len() > 0 {
+			sum, err := base64.StdEncoding.DecodeString(crc.String())
+			if err != nil || len(sum) != 3 || uint32(sum[0])<<16|uint32(sum[1])<<8|uint32(sum[2]) != crc24(body) {
+				return nil, errors.New("openpgp armor: checksum mismatch")
+			}
+		}
+		out = append(out, body)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func crc24(b []byte) uint32 {
+	crc := uint32(0xb704ce)
+	for _, c := range b {
+		crc ^= uint32(c) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864cfb
+			}
+		}
+	}
+	return crc & 0xffffff
+}
+
+// //
+
+type pgpPacketObj struct {
+	tag  byte
+	body []byte
+}
+
+// readPackets splits b into packets. Partial body lengths, used only for
+// streamed data packets, are not supported.
+func readPackets(b []byte) ([]pgpPacketObj, error) {
+	var out []pgpPacketObj
+	for len(b) > 0 {
+		h := b[0]
+		if h&0x80 == 0 {
+			return nil, errors.New("openpgp: invalid packet header")
+		}
+
+		var tag byte
+		var n, off int
+		if h&0x40 != 0 {
+			tag = h & 0x3f
+			if len(b) < 2 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			switch l := b[1]; {
+			case l < 192:
+				n, off = int(l), 2
+			case l < 224:
+				if len(b) < 3 {
+					return nil, io.ErrUnexpectedEOF
+				}
+				n, off = (int(l)-192)<<8+int(b[2])+192, 3
+			case l == 255:
+				if len(b) < 6 {
+					return nil, io.ErrUnexpectedEOF
+				}
+				n, off = int(binary.BigEndian.Uint32(b[2:6])), 6
+			default:
+				return nil, fmt.Errorf("openpgp: partial body length: %w", lightweigit.ErrUnsupported)
+			}
+		} else {
+			tag = (h >> 2) & 0x0f
+			switch h & 3 {
+			case 0:
+				if len(b) < 2 {
+					return nil, io.ErrUnexpectedEOF
+				}
+				n, off = int(b[1]), 2
+			case 1:
+				if len(b) < 3 {
+					return nil, io.ErrUnexpectedEOF
+				}
+				n, off = int(binary.BigEndian.Uint16(b[1:3])), 3
+			case 2:
+				if len(b) < 5 {
+					return nil, io.ErrUnexpectedEOF
+				}
+				n, off = int(binary.BigEndian.Uint32(b[1:5])), 5
+			default:
+				n, off = len(b)-1, 1
+			}
+		}
+		if n < 0 || off+n > len(b) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		out = append(out, pgpPacketObj{tag: tag, body: b[off : off+n]})
+		b = b[off+n:]
+	}
+	return out, nil
+}
+
+// readMPI reads a multiprecision integer: a bit count and the bytes.
+func readMPI(b []byte) ([]byte, []byte, error) {
+	if len(b) < 2 {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	n := (int(binary.BigEndian.Uint16(b)) + 7) / 8
+	if len(b) < 2+n {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	return b[2 : 2+n], b[2+n:], nil
+}
+
+// //
+
+// parsePublicKey reads a version 4 key packet. Keys of algorithms that
+// cannot be used here keep a nil pub but still have a fingerprint.
+func parsePublicKey(body []byte) (*pgpPubObj, error) {
+	if len(body) < 6 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if body[0] != 4 {
+		return nil, fmt.Errorf("openpgp: key version %d: %w", body[0], lightweigit.ErrUnsupported)
+	}
+
+	h := sha1.New()
+	h.Write([]byte{0x99, byte(len(body) >> 8), byte(len(body))})
+	h.Write(body)
+
+	k := &pgpPubObj{
+		algo:        body[5],
+		created:     time.Unix(int64(binary.BigEndian.Uint32(body[1:5])), 0).UTC(),
+		body:        body,
+		fingerprint: h.Sum(nil),
+	}
+	k.keyID = binary.BigEndian.Uint64(k.fingerprint[12:])
+
+	rest := body[6:]
+	switch k.algo {
+	case pgpAlgoRSA, pgpAlgoRSASign:
+		n, rest, err := readMPI(rest)
+		if err != nil {
+			return nil, err
+		}
+		e, _, err := readMPI(rest)
+		if err != nil {
+			return nil, err
+		}
+		if len(e) > 4 {
+			return nil, errors.New("openpgp: rsa exponent too large")
+		}
+		k.pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	case pgpAlgoECDSA, pgpAlgoEdDSA:
+		if len(rest) < 1 || len(rest) < 1+int(rest[0]) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		oid := hex.EncodeToString(rest[1 : 1+rest[0]])
+		point, _, err := readMPI(rest[1+rest[0]:])
+		if err != nil {
+			return nil, err
+		}
+
+		if k.algo == pgpAlgoEdDSA {
+			if oid != oidEd25519 || len(point) != 33 || point[0] != 0x40 {
+				return k, nil
+			}
+			k.pub = ed25519.PublicKey(point[1:])
+			break
+		}
+		curve, ok := pgpCurves[oid]
+		if !ok {
+			return k, nil
+		}
+		x, y := elliptic.Unmarshal(curve, point)
+		if x == nil {
+			return nil, errors.New("openpgp: invalid ecdsa point")
+		}
+		k.pub = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+
+	case pgpAlgoEd25519:
+		if len(rest) < ed25519.PublicKeySize {
+			return nil, io.ErrUnexpectedEOF
+		}
+		k.pub = ed25519.PublicKey(rest[:ed25519.PublicKeySize])
+	}
+	return k, nil
+}
+
+// parseSignature reads a version 4 signature packet.
+func parseSignature(body []byte) (*pgpSigObj, error) {
+	if len(body) < 6 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if body[0] != 4 {
+		return nil, fmt.Errorf("openpgp: signature version %d: %w", body[0], lightweigit.ErrUnsupported)
+	}
+
+	s := &pgpSigObj{sigType: body[1], algo: body[2], hash: body[3]}
+	hashedLen := int(binary.BigEndian.Uint16(body[4:6]))
+	if len(body) < 6+hashedLen+2 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	s.hashed = body[:6+hashedLen]
+	if err := s.subpackets(body[6:6+hashedLen], true); err != nil {
+		return nil, err
+	}
+
+	rest := body[6+hashedLen:]
+	unhashedLen := int(binary.BigEndian.Uint16(rest))
+	if len(rest) < 2+unhashedLen+2 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err := s.subpackets(rest[2:2+unhashedLen], false); err != nil {
+		return nil, err
+	}
+	if s.created.IsZero() {
+		return nil, errors.New("openpgp: signature without creation time")
+	}
+	rest = rest[2+unhashedLen:]
+	copy(s.left[:], rest)
+	rest = rest[2:]
+
+	switch s.algo {
+	case pgpAlgoEd25519:
+		if len(rest) < ed25519.SignatureSize {
+			return nil, io.ErrUnexpectedEOF
+		}
+		s.values = [][]byte{rest[:ed25519.SignatureSize]}
+	default:
+		for len(rest) > 0 {
+			v, r, err := readMPI(rest)
+			if err != nil {
+				return nil, err
+			}
+			s.values = append(s.values, v)
+			rest = r
+		}
+	}
+	return s, nil
+}
+
+// subpackets reads the issuer and, from the hashed area only, the
+// creation time, the expirations and the key flags. An unknown critical
+// subpacket in the hashed area is an error; the unhashed area is not
+// signed, so its critical bits are not trusted either way.
+func (s *pgpSigObj) subpackets(b []byte, hashed bool) error {
+	for len(b) > 0 {
+		n, off := int(b[0]), 1
+		switch {
+		case b[0] >= 192 && b[0] < 255:
+			if len(b) < 2 {
+				return io.ErrUnexpectedEOF
+			}
+			n, off = (int(b[0])-192)<<8+int(b[1])+192, 2
+		case b[0] == 255:
+			if len(b) < 5 {
+				return io.ErrUnexpectedEOF
+			}
+			n, off = int(binary.BigEndian.Uint32(b[1:5])), 5
+		}
+		if n < 1 || off+n > len(b) {
+			return io.ErrUnexpectedEOF
+		}
+
+		data := b[off+1 : off+n]
+		typ := b[off] & 0x7f
+		if hashed && b[off]&0x80 != 0 && !pgpSubKnown[typ] {
+			return fmt.Errorf("openpgp: critical subpacket %d: %w", typ, lightweigit.ErrUnsupported)
+		}
+		switch typ {
+		case pgpSubCreated:
+			if hashed && len(data) == 4 {
+				s.created = time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC()
+			}
+		case pgpSubSigExpires:
+			if hashed && len(data) == 4 {
+				s.sigLife = binary.BigEndian.Uint32(data)
+			}
+		case pgpSubKeyExpires:
+			if hashed && len(data) == 4 {
+				s.keyLife = binary.BigEndian.Uint32(data)
+			}
+		case pgpSubKeyFlags:
+			if hashed && len(data) > 0 {
+				s.flags = data[0]
+			}
+		case pgpSubEmbedded:
+			// The embedded back-signature is verified on its own, so it is
+			// taken from either area.
+			s.embedded = data
+		case pgpSubIssuer:
+			if len(data) == 8 {
+				s.issuer = binary.BigEndian.Uint64(data)
+			}
+		case pgpSubIssuerFP:
+			if len(data) == 21 && data[0] == 4 {
+				s.issuerFP = data[1:]
+				s.issuer = binary.BigEndian.Uint64(data[13:])
+			}
+		}
+		b = b[off+n:]
+	}
+	return nil
+}
+
+// expired reports whether the signature's own lifetime ended before now.
+func (s *pgpSigObj) expired(now time.Time) bool {
+	return s.sigLife != 0 && !now.Before(s.created.Add(time.Duration(s.sigLife)*time.Second))
+}
+
+// selfSigned takes the key expiration and flags of s, a valid
+// self-signature over k, unless a newer one already set them. A
+// self-signature without an expiration clears it.
+func (k *pgpPubObj) selfSigned(s *pgpSigObj, flags byte) {
+	if s.created.Before(k.expirySet) {
+		return
+	}
+	k.expirySet = s.created
+	k.flags = flags
+	k.expires = time.Time{}
+	if s.keyLife != 0 {
+		k.expires = k.created.Add(time.Duration(s.keyLife) * time.Second)
+	}
+}
+
+// valid checks what a good signature by c still needs: it is not dated
+// in the future nor older than the key, the key and its primary had not expired when it was made,
+// and it has not expired itself.
+func (c pgpCandidateObj) valid(s *pgpSigObj, now time.Time) error {
+	if s.created.After(now) {
+		return fmt.Errorf("openpgp signature dated %s, in the future: %w", s.created.Format(time.RFC3339), ErrBadSignature)
+	}
+	if s.created.Before(c.pub.created) {
+		return fmt.Errorf("openpgp key %016X: signature made before the key: %w", c.pub.keyID, ErrBadSignature)
+	}
+	for _, pub := range []*pgpPubObj{c.key.primary, c.pub} {
+		if !pub.expires.IsZero() && !s.created.Before(pub.expires) {
+			return fmt.Errorf("openpgp key %016X expired %s: %w", pub.keyID, pub.expires.Format(time.RFC3339), ErrExpired)
+		}
+	}
+	if s.expired(now) {
+		return fmt.Errorf("openpgp signature of %s expired: %w", s.created.Format(time.RFC3339), ErrExpired)
+	}
+	return nil
+}
+
+// //
+
+// newHash returns the hash the signature uses.
+func (s *pgpSigObj) newHash() (hash.Hash, crypto.Hash, error) {
+	h, ok := pgpHashes[s.hash]
+	if !ok {
+		return nil, 0, fmt.Errorf("openpgp: hash algorithm %d: %w", s.hash, lightweigit.ErrUnsupported)
+	}
+	switch h {
+	case crypto.SHA224:
+		return sha256.New224(), h, nil
+	case crypto.SHA256:
+		return sha256.New(), h, nil
+	case crypto.SHA384:
+		return sha512.New384(), h, nil
+	}
+	return sha512.New(), h, nil
+}
+
+// finish appends the signature's own hashed part and trailer to h and
+// checks the result against k.
+func (s *pgpSigObj) finish(h hash.Hash, id crypto.Hash, k *pgpPubObj) error {
+	h.Write(s.hashed)
+	var trailer [6]byte
+	trailer[0], trailer[1] = 4, 0xff
+	binary.BigEndian.PutUint32(trailer[2:], uint32(len(s.hashed)))
+	h.Write(trailer[:])
+	digest := h.Sum(nil)
+
+	if digest[0] != s.left[0] || digest[1] != s.left[1] {
+		return ErrBadSignature
+	}
+	if k.pub == nil || k.algo != s.algo && !(isRSA(k.algo) && isRSA(s.algo)) {
+		return fmt.Errorf("openpgp: key algorithm %d: %w", k.algo, lightweigit.ErrUnsupported)
+	}
+
+	ok := false
+	switch pub := k.pub.(type) {
+	case *rsa.PublicKey:
+		if len(s.values) == 1 {
+			ok = rsa.VerifyPKCS1v15(pub, id, digest, leftPad(s.values[0], pub.Size())) == nil
+		}
+	case *ecdsa.PublicKey:
+		if len(s.values) == 2 {
+			ok = ecdsa.Verify(pub, digest, new(big.Int).SetBytes(s.values[0]), new(big.Int).SetBytes(s.values[1]))
+		}
+	case ed25519.PublicKey:
+		switch {
+		case len(s.values) == 1:
+			ok = ed25519.Verify(pub, digest, s.values[0])
+		case len(s.values) == 2 && len(s.values[0]) <= 32 && len(s.values[1]) <= 32:
+			sig := make([]byte, 0, ed25519.SignatureSize)
+			sig = append(append(sig, leftPad(s.values[0], 32)...), leftPad(s.values[1], 32)...)
+			ok = ed25519.Verify(pub, digest, sig)
+		}
+	}
+	if !ok {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// writeKey hashes a key the way certification signatures cover it.
+func writeKey(h hash.Hash, k *pgpPubObj) {
+	h.Write([]byte{0x99, byte(len(k.body) >> 8), byte(len(k.body))})
+	h.Write(k.body)
+}
+
+// writeUID hashes a user ID the way certification signatures cover it.
+func writeUID(h hash.Hash, uid []byte) {
+	var head [5]byte
+	head[0] = 0xb4
+	binary.BigEndian.PutUint32(head[1:], uint32(len(uid)))
+	h.Write(head[:])
+	h.Write(uid)
+}
+
+// //
+
+// addOpenPGP reads the transferable public keys in data. Subkeys are only
+// kept with a binding signature from their primary key; keys and subkeys
+// revoked by it are marked, whatever the order of the signatures.
+func (k *KeyringObj) addOpenPGP(data []byte) (int, error) {
+	bodies := [][]byte{data}
+	if bytes.Contains(data, []byte("-----BEGIN PGP ")) {
+		var err error
+		if bodies, err = dearmor(data, pgpArmorPublicKey); err != nil {
+			return 0, err
+		}
+	}
+
+	added := 0
+	for _, body := range bodies {
+		packets, err := readPackets(body)
+		if err != nil {
+			return added, err
+		}
+
+		var cur *pgpKeyObj
+		var sub *pgpPubObj
+		var uid []byte
+		for _, p := range packets {
+			switch p.tag {
+			case pgpTagPublicKey:
+				pk, err := parsePublicKey(p.body)
+				if err != nil {
+					return added, err
+				}
+				cur, sub, uid = &pgpKeyObj{primary: pk}, nil, nil
+				k.pgp = append(k.pgp, cur)
+				added++
+
+			case pgpTagUserID:
+				if cur != nil && cur.uid == "" {
+					cur.uid = string(p.body)
+				}
+				sub, uid = nil, p.body
+
+			case pgpTagUserAttr:
+				sub, uid = nil, nil
+
+			case pgpTagSubkey:
+				if cur == nil {
+					continue
+				}
+				if sub, err = parsePublicKey(p.body); err != nil {
+					return added, err
+				}
+				uid = nil
+
+			case pgpTagSignature:
+				if cur == nil {
+					continue
+				}
+				s, err := parseSignature(p.body)
+				if err != nil {
+					continue
+				}
+				cur.certify(s, sub, uid, time.Now())
+			}
+		}
+	}
+	if added == 0 {
+		return 0, errors.New("openpgp: no public key found")
+	}
+	return added, nil
+}
+
+// certify applies a signature that follows a key, user ID or subkey
+// packet: self-signatures that set the key's expiration and flags, subkey
+// bindings and revocations, and key revocations. Signatures older than
+// the keys they cover, dated after now or expired at now are ignored.
+func (key *pgpKeyObj) certify(s *pgpSigObj, sub *pgpPubObj, uid []byte, now time.Time) {
+	if s.created.Before(key.primary.created) || sub != nil && s.created.Before(sub.created) || s.created.After(now) || s.expired(now) {
+		return
+	}
+	h, id, err := s.newHash()
+	if err != nil {
+		return
+	}
+
+	switch {
+	case s.sigType == pgpSigKeyRevoke && sub == nil && uid == nil:
+		writeKey(h, key.primary)
+		if s.finish(h, id, key.primary) == nil {
+			key.revoked = true
+		}
+
+	case s.sigType == pgpSigDirect && sub == nil && uid == nil:
+		writeKey(h, key.primary)
+		if s.finish(h, id, key.primary) == nil {
+			key.primary.selfSigned(s, s.flags)
+		}
+
+	case s.sigType >= pgpSigCertFirst && s.sigType <= pgpSigCertLast && uid != nil:
+		writeKey(h, key.primary)
+		writeUID(h, uid)
+		if s.finish(h, id, key.primary) == nil {
+			key.primary.selfSigned(s, s.flags)
+		}
+
+	case s.sigType == pgpSigSubkey && sub != nil:
+		writeKey(h, key.primary)
+		writeKey(h, sub)
+		if s.finish(h, id, key.primary) != nil {
+			return
+		}
+		// A subkey signs only with a back-signature of its own over this
+		// primary key, or any certificate could claim another's subkey.
+		flags := s.flags
+		if flags&pgpFlagSign != 0 && !key.backSigned(s, sub, now) {
+			flags &^= pgpFlagSign
+		}
+		sub.selfSigned(s, flags)
+		for _, have := range key.subkeys {
+			if have == sub {
+				return
+			}
+		}
+		key.subkeys = append(key.subkeys, sub)
+
+	case s.sigType == pgpSigSubkeyRevok && sub != nil:
+		writeKey(h, key.primary)
+		writeKey(h, sub)
+		if s.finish(h, id, key.primary) == nil {
+			sub.revoked = true
+		}
+	}
+}
+
+// backSigned reports whether the binding s of sub embeds a valid primary
+// key binding signature, made by sub over the primary key and sub.
+func (key *pgpKeyObj) backSigned(s *pgpSigObj, sub *pgpPubObj, now time.Time) bool {
+	if s.embedded == nil {
+		return false
+	}
+	back, err := parseSignature(s.embedded)
+	if err != nil || back.sigType != pgpSigPrimaryBind {
+		return false
+	}
+	if back.created.Before(sub.created) || back.created.After(now) || back.expired(now) {
+		return false
+	}
+	h, id, err := back.newHash()
+	if err != nil {
+		return false
+	}
+	writeKey(h, key.primary)
+	writeKey(h, sub)
+	return back.finish(h, id, sub) == nil
+}
+
+// pgpCandidates lists the keys that may have made s: those with the sign
+// flag that match its issuer, or every such key when it names none.
+func (k *KeyringObj) pgpCandidates(s *pgpSigObj) []pgpCandidateObj {
+	var out []pgpCandidateObj
+	for _, key := range k.pgp {
+		if key.revoked {
+			continue
+		}
+		for _, pub := range append([]*pgpPubObj{key.primary}, key.subkeys...) {
+			switch {
+			case pub.revoked, pub.flags&pgpFlagSign == 0:
+			case s.issuerFP != nil && !bytes.Equal(s.issuerFP, pub.fingerprint):
+			case s.issuerFP == nil && s.issuer != 0 && s.issuer != pub.keyID:
+			default:
+				out = append(out, pgpCandidateObj{key: key, pub: pub})
+			}
+		}
+	}
+	return out
+}
+
+// verifyPGP checks a detached binary or text signature over r.
+func (k *KeyringObj) verifyPGP(r io.Reader, sig []byte) (*ResultObj, error) {
+	if bytes.Contains(sig, []byte("-----BEGIN PGP ")) {
+		bodies, err := dearmor(sig, pgpArmorSignature)
+		if err != nil {
+			return nil, err
+		}
+		if len(bodies) == 0 {
+			return nil, ErrNoSignature
+		}
+		sig = bodies[0]
+	}
+
+	packets, err := readPackets(sig)
+	if err != nil {
+		return nil, err
+	}
+	var s *pgpSigObj
+	for _, p := range packets {
+		if p.tag == pgpTagSignature {
+			if s, err = parseSignature(p.body); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if s == nil {
+		return nil, ErrNoSignature
+	}
+	if s.sigType != pgpSigBinary && s.sigType != pgpSigText {
+		return nil, fmt.Errorf("openpgp: signature type 0x%02x: %w", s.sigType, lightweigit.ErrUnsupported)
+	}
+
+	cands := k.pgpCandidates(s)
+	if len(cands) == 0 {
+		return nil, fmt.Errorf("openpgp key %016X: %w", s.issuer, ErrUnknownKey)
+	}
+
+	h, id, err := s.newHash()
+	if err != nil {
+		return nil, err
+	}
+	var w io.Writer = h
+	if s.sigType == pgpSigText {
+		w = &crlfWriterObj{w: h}
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return nil, err
+	}
+	state, err := marshalHash(h)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ErrBadSignature
+	now := time.Now()
+	for _, c := range cands {
+		h, _, _ := s.newHash()
+		if err := unmarshalHash(h, state); err != nil {
+			return nil, err
+		}
+		if err = s.finish(h, id, c.pub); err == nil {
+			err = c.valid(s, now)
+		}
+		if err == nil {
+			return &ResultObj{
+				Format:      FormatOpenPGP,
+				Fingerprint: strings.ToUpper(hex.EncodeToString(c.key.primary.fingerprint)),
+				KeyID:       fmt.Sprintf("%016X", c.pub.keyID),
+				Signer:      c.key.uid,
+				Created:     s.created,
+			}, nil
+		}
+	}
+	return nil, err
+}
+
+// //
+
+// crlfWriterObj writes line ends as CRLF, as text signatures are made.
+type crlfWriterObj struct {
+	w    io.Writer
+	last byte
+}
+
+func (c *crlfWriterObj) Write(p []byte) (int, error) {
+	var buf bytes.Buffer
+	for _, b := range p {
+		if b == '\n' && c.last != '\r' {
+			buf.WriteByte('\r')
+		}
+		buf.WriteByte(b)
+		c.last = b
+	}
+	if _, err := c.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// marshalHash and unmarshalHash save and restore the state of a hash
+// over the data, so that each candidate key reads the data only once.
+func marshalHash(h hash.Hash) ([]byte, error) {
+	m, ok := h.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, errors.New("hash state cannot be saved")
+	}
+	return m.MarshalBinary()
+}
+
+func unmarshalHash(h hash.Hash, state []byte) error {
+	u, ok := h.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return errors.New("hash state cannot be restored")
+	}
+	return u.UnmarshalBinary(state)
+}
+
+func isRSA(algo byte) bool {
+	return algo == pgpAlgoRSA || algo == pgpAlgoRSASign
+}
+
+// leftPad pads b with zeros to n bytes; MPIs drop leading zeros.
+func leftPad(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b
+	}
+	out := make([]byte, n)
+	copy(out[n-len(b):], b)
+	return out
+}
@@ -0,0 +1,409 @@
+package verify
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+const (
+	sshArmorBegin = "-----BEGIN SSH SIGNATURE-----"
+	sshArmorEnd   = "-----END SSH SIGNATURE-----"
+	sshMagic      = "SSHSIG"
+)
+
+// sshCurves maps ECDSA key types to their curve and hash.
+var sshCurves = map[string]struct {
+	curve elliptic.Curve
+	hash  crypto.Hash
+}{
+	"ecdsa-sha2-nistp256": {elliptic.P256(), crypto.SHA256},
+	"ecdsa-sha2-nistp384": {elliptic.P384(), crypto.SHA384},
+	"ecdsa-sha2-nistp521": {elliptic.P521(), crypto.SHA512},
+}
+
+// //
+
+// wireObj reads the SSH wire encoding; the first error sticks.
+type wireObj struct {
+	b   []byte
+	err error
+}
+
+func (w *wireObj) bytes() []byte {
+	if w.err != nil {
+		return nil
+	}
+	if len(w.b) < 4 {
+		w.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	n := binary.BigEndian.Uint32(w.b)
+	if uint64(len(w.b)-4) < uint64(n) {
+		w.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	out := w.b[4 : 4+n]
+	w.b = w.b[4+n:]
+	return out
+}
+
+func (w *wireObj) string() string {
+	return string(w.bytes())
+}
+
+func (w *wireObj) uint32() uint32 {
+	if w.err != nil {
+		return 0
+	}
+	if len(w.b) < 4 {
+		w.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	n := binary.BigEndian.Uint32(w.b)
+	w.b = w.b[4:]
+	return n
+}
+
+func wireString(b []byte) []byte {
+	out := make([]byte, 4, 4+len(b))
+	binary.BigEndian.PutUint32(out, uint32(len(b)))
+	return append(out, b...)
+}
+
+// //
+
+// parseSSHKey reads a public key blob: ssh-ed25519, ssh-rsa or
+// ecdsa-sha2-nistp*. Security key and certificate types are not supported.
+func parseSSHKey(blob []byte) (crypto.PublicKey, error) {
+	w := &wireObj{b: blob}
+	typ := w.string()
+
+	var pub crypto.PublicKey
+	switch typ {
+	case "ssh-ed25519":
+		k := w.bytes()
+		if w.err == nil && len(k) != ed25519.PublicKeySize {
+			return nil, errors.New("ssh: invalid ed25519 key")
+		}
+		pub = ed25519.PublicKey(k)
+
+	case "ssh-rsa":
+		e, n := w.bytes(), w.bytes()
+		if w.err == nil && len(bytes.TrimLeft(e, "\x00")) > 4 {
+			return nil, errors.New("ssh: rsa exponent too large")
+		}
+		pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	default:
+		c, ok := sshCurves[typ]
+		if !ok {
+			return nil, fmt.Errorf("ssh key type %q: %w", typ, lightweigit.ErrUnsupported)
+		}
+		w.string()
+		x, y := elliptic.Unmarshal(c.curve, w.bytes())
+		if w.err == nil && x == nil {
+			return nil, errors.New("ssh: invalid ecdsa point")
+		}
+		pub = &ecdsa.PublicKey{Curve: c.curve, X: x, Y: y}
+	}
+	if w.err != nil {
+		return nil, fmt.Errorf("ssh key: %w", w.err)
+	}
+	return pub, nil
+}
+
+// sshFingerprint is the key fingerprint as ssh-keygen -l prints it.
+func sshFingerprint(blob []byte) string {
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// parseSSHSig reads an armored SSHSIG signature, as ssh-keygen -Y sign and
+// git with gpg.format=ssh write them.
+func parseSSHSig(data []byte) (*sshSigObj, error) {
+	s := string(data)
+	begin, end := strings.Index(s, sshArmorBegin), strings.Index(s, sshArmorEnd)
+	if begin < 0 || end < begin {
+		return nil, ErrNoSignature
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s[begin+len(sshArmorBegin):end]), ""))
+	if err != nil {
+		return nil, fmt.Errorf("ssh signature: %w", err)
+	}
+	if !bytes.HasPrefix(raw, []byte(sshMagic)) {
+		return nil, errors.New("ssh signature: bad magic")
+	}
+
+	w := &wireObj{b: raw[len(sshMagic):]}
+	if v := w.uint32(); w.err == nil && v != 1 {
+		return nil, fmt.Errorf("ssh signature version %d: %w", v, lightweigit.ErrUnsupported)
+	}
+	sig := &sshSigObj{
+		blob:      w.bytes(),
+		namespace: w.string(),
+		reserved:  w.string(),
+		hashAlg:   w.string(),
+	}
+	inner := &wireObj{b: w.bytes()}
+	sig.format = inner.string()
+	sig.sig = inner.bytes()
+	if w.err != nil || inner.err != nil {
+		return nil, errors.New("ssh signature: truncated")
+	}
+
+	if sig.pub, err = parseSSHKey(sig.blob); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// signedData is what the key signs: the magic, namespace, reserved field,
+// hash name and the hash of the message.
+func (s *sshSigObj) signedData(r io.Reader) ([]byte, error) {
+	var h hash.Hash
+	switch s.hashAlg {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, fmt.Errorf("ssh hash %q: %w", s.hashAlg, lightweigit.ErrUnsupported)
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString(sshMagic)
+	b.Write(wireString([]byte(s.namespace)))
+	b.Write(wireString([]byte(s.reserved)))
+	b.Write(wireString([]byte(s.hashAlg)))
+	b.Write(wireString(h.Sum(nil)))
+	return b.Bytes(), nil
+}
+
+// check verifies the signature over signed with the key in the blob.
+func (s *sshSigObj) check(signed []byte) error {
+	ok := false
+	switch pub := s.pub.(type) {
+	case ed25519.PublicKey:
+		ok = s.format == "ssh-ed25519" && ed25519.Verify(pub, signed, s.sig)
+
+	case *rsa.PublicKey:
+		switch s.format {
+		case "rsa-sha2-256":
+			sum := sha256.Sum256(signed)
+			ok = rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], s.sig) == nil
+		case "rsa-sha2-512":
+			sum := sha512.Sum512(signed)
+			ok = rsa.VerifyPKCS1v15(pub, crypto.SHA512, sum[:], s.sig) == nil
+		default:
+			return fmt.Errorf("ssh signature format %q: %w", s.format, lightweigit.ErrUnsupported)
+		}
+
+	case *ecdsa.PublicKey:
+		c, known := sshCurves[s.format]
+		if !known || c.curve != pub.Curve {
+			return fmt.Errorf("ssh signature format %q: %w", s.format, lightweigit.ErrUnsupported)
+		}
+		h := c.hash.New()
+		h.Write(signed)
+		w := &wireObj{b: s.sig}
+		r, sv := w.bytes(), w.bytes()
+		ok = w.err == nil && ecdsa.Verify(pub, h.Sum(nil), new(big.Int).SetBytes(r), new(big.Int).SetBytes(sv))
+	}
+	if !ok {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// verifySSH checks an SSH signature over r. The signature must be made in
+// namespace ("git" for tags, "file" for files) by a key an allowed_signers
+// line trusts for that namespace at the current time.
+func (k *KeyringObj) verifySSH(r io.Reader, sig []byte, namespace string) (*ResultObj, error) {
+	s, err := parseSSHSig(sig)
+	if err != nil {
+		return nil, err
+	}
+	if s.namespace != namespace {
+		return nil, fmt.Errorf("ssh signature namespace %q, want %q: %w", s.namespace, namespace, ErrBadSignature)
+	}
+
+	now := time.Now()
+	var signer *signerObj
+	for _, li := range k.signers {
+		if bytes.Equal(li.blob, s.blob) && li.allows(namespace, now) {
+			signer = li
+			break
+		}
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("ssh key %s: %w", sshFingerprint(s.blob), ErrUnknownKey)
+	}
+
+	signed, err := s.signedData(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(signed); err != nil {
+		return nil, err
+	}
+	return &ResultObj{
+		Format:      FormatSSH,
+		Fingerprint: sshFingerprint(s.blob),
+		Signer:      signer.principals,
+	}, nil
+}
+
+// //
+
+// allows reports whether the line trusts its key for namespace at t.
+func (li *signerObj) allows(namespace string, t time.Time) bool {
+	if !li.validAfter.IsZero() && t.Before(li.validAfter) {
+		return false
+	}
+	if !li.validBefore.IsZero() && !t.Before(li.validBefore) {
+		return false
+	}
+	if li.namespaces == nil {
+		return true
+	}
+	for _, pattern := range li.namespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addAllowedSigners reads an allowed_signers file (see ssh-keygen(1)):
+// "principals [options] keytype base64-key [comment]". Lines with
+// cert-authority, or with key types that cannot be verified here, are
+// skipped.
+func (k *KeyringObj) addAllowedSigners(data []byte) (int, error) {
+	added := 0
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := splitQuoted(line, ' ')
+		if len(fields) < 3 {
+			return added, fmt.Errorf("allowed_signers line %d: too few fields", n+1)
+		}
+		li := &signerObj{principals: strings.Trim(fields[0], `"`)}
+
+		rest := fields[1:]
+		if !isSSHKeyType(rest[0]) {
+			skip, err := li.options(rest[0])
+			if err != nil {
+				return added, fmt.Errorf("allowed_signers line %d: %w", n+1, err)
+			}
+			if skip {
+				continue
+			}
+			rest = rest[1:]
+		}
+		if len(rest) < 2 {
+			return added, fmt.Errorf("allowed_signers line %d: missing key", n+1)
+		}
+
+		blob, err := base64.StdEncoding.DecodeString(rest[1])
+		if err != nil {
+			return added, fmt.Errorf("allowed_signers line %d: %w", n+1, err)
+		}
+		if li.pub, err = parseSSHKey(blob); err != nil {
+			if errors.Is(err, lightweigit.ErrUnsupported) {
+				continue
+			}
+			return added, fmt.Errorf("allowed_signers line %d: %w", n+1, err)
+		}
+		li.blob = blob
+		k.signers = append(k.signers, li)
+		added++
+	}
+	return added, nil
+}
+
+// options applies the option list of a line. skip is set for
+// cert-authority lines.
+func (li *signerObj) options(s string) (skip bool, err error) {
+	for _, opt := range splitQuoted(s, ',') {
+		name, value, _ := strings.Cut(opt, "=")
+		value = strings.Trim(value, `"`)
+		switch strings.ToLower(name) {
+		case "cert-authority":
+			return true, nil
+		case "namespaces":
+			li.namespaces = strings.Split(value, ",")
+		case "valid-after":
+			if li.validAfter, err = parseSSHTime(value); err != nil {
+				return false, err
+			}
+		case "valid-before":
+			if li.validBefore, err = parseSSHTime(value); err != nil {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// parseSSHTime reads YYYYMMDD[HHMM[SS]][Z] times; without Z they are local.
+func parseSSHTime(s string) (time.Time, error) {
+	loc := time.Local
+	if strings.HasSuffix(s, "Z") {
+		s, loc = s[:len(s)-1], time.UTC
+	}
+	for _, layout := range []string{"20060102150405", "200601021504", "20060102"} {
+		if len(s) == len(layout) {
+			return time.ParseInLocation(layout, s, loc)
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+func isSSHKeyType(s string) bool {
+	return strings.HasPrefix(s, "ssh-") || strings.HasPrefix(s, "ecdsa-") || strings.HasPrefix(s, "sk-")
+}
+
+// splitQuoted splits s on sep outside double quotes; runs of spaces count
+// as one separator.
+func splitQuoted(s string, sep byte) []string {
+	var out []string
+	start, quoted := 0, false
+	for i := 0; i <= len(s); i++ {
+		switch {
+		case i < len(s) && s[i] == '"':
+			quoted = !quoted
+		case i == len(s) || s[i] == sep && !quoted:
+			if f := s[start:i]; f != "" || sep != ' ' {
+				out = append(out, f)
+			}
+			start = i + 1
+		}
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package verify
+
+import (
+	"crypto"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+// FormatType is the kind of signature that was verified.
+type FormatType byte
+
+const (
+	FormatOpenPGP FormatType = iota
+	FormatSSH
+)
+
+func (f FormatType) String() string {
+	switch f {
+	case FormatOpenPGP:
+		return "openpgp"
+	case FormatSSH:
+		return "ssh"
+	}
+	return "unknown"
+}
+
+// ResultObj describes a good signature and the trusted key that made it.
+type ResultObj struct {
+	Format FormatType
+
+	// Fingerprint is the key's fingerprint: 40 hex digits for OpenPGP,
+	// "SHA256:..." as ssh-keygen prints it for SSH. KeyID is the OpenPGP
+	// key ID, the low 16 hex digits of the fingerprint of the (sub)key.
+	Fingerprint string
+	KeyID       string
+
+	// Signer is the first user ID of the OpenPGP key, or the principals
+	// of the allowed_signers line.
+	Signer string
+
+	// Created is the OpenPGP signature's creation time; SSH signatures
+	// carry none.
+	Created time.Time
+}
+
+// KeyringObj holds the trusted keys: OpenPGP public keys and SSH
+// allowed_signers entries. Build it with NewKeyring and the Add methods
+// before verifying; it is not safe to add keys concurrently with use.
+type KeyringObj struct {
+	pgp     []*pgpKeyObj
+	signers []*signerObj
+}
+
+// //
+
+// pgpPubObj is a version 4 public key or subkey packet. pub is nil for
+// algorithms that cannot sign or are not supported. expires is zero for a
+// key that does not expire; expirySet is the creation time of the
+// self-signature it and flags were taken from. A subkey keeps the sign
+// flag only with a valid back-signature.
+type pgpPubObj struct {
+	algo        byte
+	created     time.Time
+	body        []byte
+	fingerprint []byte
+	keyID       uint64
+	pub         crypto.PublicKey
+
+	flags     byte
+	expires   time.Time
+	expirySet time.Time
+	revoked   bool
+}
+
+// pgpKeyObj is a transferable public key: the primary key, its first user
+// ID and the subkeys bound to it by a valid binding signature. A revoked
+// subkey stays in subkeys with its revoked flag set.
+type pgpKeyObj struct {
+	primary *pgpPubObj
+	uid     string
+	subkeys []*pgpPubObj
+	revoked bool
+}
+
+// pgpCandidateObj is a key or subkey that may have made a signature,
+// with the key it belongs to.
+type pgpCandidateObj struct {
+	key *pgpKeyObj
+	pub *pgpPubObj
+}
+
+// pgpSigObj is a version 4 signature packet. hashed is the part the
+// signature covers after the data: version through hashed subpackets.
+// sigLife and keyLife are the signature and key expiration subpackets,
+// seconds after the creation of the signature and of the key; 0 is never.
+type pgpSigObj struct {
+	sigType  byte
+	algo     byte
+	hash     byte
+	hashed   []byte
+	left     [2]byte
+	issuer   uint64
+	issuerFP []byte
+	created  time.Time
+	sigLife  uint32
+	keyLife  uint32
+	flags    byte
+	embedded []byte
+	values   [][]byte
+}
+
+// signerObj is one allowed_signers line.
+type signerObj struct {
+	principals  string
+	namespaces  []string
+	validAfter  time.Time
+	validBefore time.Time
+	blob        []byte
+	pub         crypto.PublicKey
+}
+
+// sshSigObj is a parsed SSHSIG blob.
+type sshSigObj struct {
+	blob      []byte
+	pub       crypto.PublicKey
+	namespace string
+	reserved  string
+	hashAlg   string
+	format    string
+	sig       []byte
+}
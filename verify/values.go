@@ -0,0 +1,17 @@
+package verify
+
+import (
+	"errors"
+)
+
+// // // // // // // // // // // // // // // //
+
+// maxSidecar caps a downloaded signature file.
+const maxSidecar = 1 << 20
+
+var (
+	ErrNoSignature  = errors.New("no signature")
+	ErrUnknownKey   = errors.New("signed by no trusted key")
+	ErrBadSignature = errors.New("bad signature")
+	ErrExpired      = errors.New("signature or key expired")
+)
@@ -0,0 +1,146 @@
+package lightweigit
+
+import (
+	"strconv"
+	"strings"
+)
+
+// // // // // // // // // // // // // // // //
+
+// VersionObj is a semantic version read from a tag name.
+type VersionObj struct {
+	Major, Minor, Patch int
+
+	// Pre holds the dot-separated pre-release identifiers, Build the build
+	// metadata; Build takes no part in ordering.
+	Pre   []string
+	Build string
+}
+
+// ParseVersion reads a tag name as a semantic version. A leading "v" and
+// a missing minor or patch ("v2", "1.4") are tolerated, as tags often
+// have them; anything else that is not SemVer 2.0 gives false.
+func ParseVersion(s string) (VersionObj, bool) {
+	var v VersionObj
+
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	s, v.Build, _ = strings.Cut(s, "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+	if hasPre {
+		if pre == "" {
+			return v, false
+		}
+		v.Pre = strings.Split(pre, ".")
+		for _, id := range v.Pre {
+			if id == "" {
+				return v, false
+			}
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, ok := versionNumber(p)
+		if !ok {
+			return v, false
+		}
+		*nums[i] = n
+	}
+	return v, true
+}
+
+func versionNumber(s string) (int, bool) {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// Prerelease reports whether v carries pre-release identifiers.
+func (v VersionObj) Prerelease() bool {
+	return len(v.Pre) > 0
+}
+
+func (v VersionObj) String() string {
+	s := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	if len(v.Pre) > 0 {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare orders versions by SemVer 2.0 precedence: -1, 0 or +1.
+func (v VersionObj) Compare(o VersionObj) int {
+	for _, d := range [][2]int{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		if d[0] != d[1] {
+			return cmpInt(d[0], d[1])
+		}
+	}
+
+	// A release ranks above its pre-releases.
+	switch {
+	case len(v.Pre) == 0 && len(o.Pre) == 0:
+		return 0
+	case len(v.Pre) == 0:
+		return 1
+	case len(o.Pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Pre) && i < len(o.Pre); i++ {
+		a, b := v.Pre[i], o.Pre[i]
+		an, aNum := versionNumber(a)
+		bn, bNum := versionNumber(b)
+		switch {
+		case aNum && bNum:
+			if an != bn {
+				return cmpInt(an, bn)
+			}
+		case aNum:
+			return -1
+		case bNum:
+			return 1
+		case a != b:
+			return strings.Compare(a, b)
+		}
+	}
+	return cmpInt(len(v.Pre), len(o.Pre))
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// CompareVersions compares two tag names as versions. Names that do not
+// parse sort below those that do and among themselves by name.
+func CompareVersions(a, b string) int {
+	va, okA := ParseVersion(a)
+	vb, okB := ParseVersion(b)
+	switch {
+	case okA && okB:
+		return va.Compare(vb)
+	case okA:
+		return 1
+	case okB:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
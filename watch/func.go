@@ -0,0 +1,344 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// New returns a watcher over repos. Zero fields of opts take their
+// defaults.
+func New(opts OptionsObj, repos ...lightweigit.ProviderInterface) (*WatcherObj, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInterval
+	}
+	if opts.Jitter < 0 {
+		opts.Jitter = 0
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore()
+	}
+	if opts.Backlog <= 0 {
+		opts.Backlog = defaultBacklog
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+
+	w := &WatcherObj{opts: opts, backoff: make(map[string]*backoffObj)}
+	for _, p := range repos {
+		if err := w.Add(p); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Add puts p under watch from the next poll on. A repository already
+// watched is not added twice.
+func (w *WatcherObj) Add(p lightweigit.ProviderInterface) error {
+	if p == nil {
+		return ErrNilProvider
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, r := range w.repos {
+		if lightweigit.SameRepo(r, p) {
+			return nil
+		}
+	}
+	w.repos = append(w.repos, p)
+	return nil
+}
+
+// Repos lists the watched repositories.
+func (w *WatcherObj) Repos() []lightweigit.ProviderInterface {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]lightweigit.ProviderInterface(nil), w.repos...)
+}
+
+// // // //
+
+// Run polls every repository right away and then once per interval until
+// ctx is done, which is the error it returns. Events go to fn, which runs
+// on the polling goroutine.
+func (w *WatcherObj) Run(ctx context.Context, fn func(EventObj)) error {
+	return w.run(ctx, func(e EventObj) error {
+		fn(e)
+		return nil
+	})
+}
+
+// Stream is Run delivering the events into out. A consumer that stops
+// reading holds the watcher until ctx is done.
+func (w *WatcherObj) Stream(ctx context.Context, out chan<- EventObj) error {
+	return w.run(ctx, func(e EventObj) error {
+		return lightweigit.Send(ctx, out, e)
+	})
+}
+
+// Poll checks every repository once. Errors of single repositories go to
+// OnError; the returned error is ctx's.
+func (w *WatcherObj) Poll(ctx context.Context, fn func(EventObj)) error {
+	return w.poll(ctx, func(e EventObj) error {
+		fn(e)
+		return nil
+	})
+}
+
+func (w *WatcherObj) run(ctx context.Context, emit func(EventObj) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		if err := w.poll(ctx, emit); err != nil {
+			return err
+		}
+
+		t := time.NewTimer(w.wait())
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// wait is the interval plus a random share of the jitter.
+func (w *WatcherObj) wait() time.Duration {
+	d := w.opts.Interval
+	if w.opts.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(w.opts.Jitter) + 1))
+	}
+	return d
+}
+
+func (w *WatcherObj) poll(ctx context.Context, emit func(EventObj) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for _, p := range w.Repos() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		host := lightweigit.Identity(p).Host
+		if w.limited(host) {
+			continue
+		}
+
+		events, err := w.check(ctx, p)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			w.hit(host, err)
+			if w.opts.OnError != nil {
+				w.opts.OnError(p, err)
+			}
+			continue
+		}
+		w.clear(host)
+
+		for _, e := range events {
+			if err := emit(e); err != nil {
+				return err
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// // // //
+
+// limited reports whether host is still backing off.
+func (w *WatcherObj) limited(host string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := w.backoff[host]
+	return b != nil && time.Now().Before(b.until)
+}
+
+// hit starts or doubles the backoff of host after a rate-limit error,
+// from one interval up to MaxBackoff. The other repositories of the host
+// wait as well: the limit is per account, not per repository.
+func (w *WatcherObj) hit(host string, err error) {
+	if !lightweigit.IsRateLimit(err) {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := w.backoff[host]
+	if b == nil {
+		b = &backoffObj{delay: w.opts.Interval}
+		w.backoff[host] = b
+	} else if b.delay *= 2; b.delay > w.opts.MaxBackoff {
+		b.delay = w.opts.MaxBackoff
+	}
+	b.until = time.Now().Add(b.delay)
+}
+
+func (w *WatcherObj) clear(host string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.backoff, host)
+}
+
+// // // //
+
+// check polls one repository and saves its new state. Events are only
+// returned once the state is saved, so a failing store repeats the poll
+// rather than losing or doubling events.
+func (w *WatcherObj) check(ctx context.Context, p lightweigit.ProviderInterface) ([]EventObj, error) {
+	key := lightweigit.Identity(p).Key()
+	data, err := w.opts.Store.Load(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: load state: %w", key, err)
+	}
+	st, err := unmarshalState(data)
+	if err != nil {
+		// A state that cannot be read would fail every poll. Report it
+		// once and start over; the save below replaces it.
+		if w.opts.OnError != nil {
+			w.opts.OnError(p, fmt.Errorf("%s: %w", key, err))
+		}
+		st = new(stateObj)
+	}
+	silent := st.Checked == 0 && !w.opts.Initial
+
+	var events []EventObj
+	emit := func(e EventObj) {
+		if !silent {
+			events = append(events, e)
+		}
+	}
+
+	next := &stateObj{Checked: time.Now().Unix()}
+	if next.Release, err = track(ctx, w.releases(p), st.Release, w.opts.Backlog, emit); err != nil {
+		return nil, fmt.Errorf("%s: releases: %w", key, err)
+	}
+	if next.Tag, err = track(ctx, w.tags(p), st.Tag, w.opts.Backlog, emit); err != nil {
+		return nil, fmt.Errorf("%s: tags: %w", key, err)
+	}
+
+	if err := w.opts.Store.Save(key, next.marshal()); err != nil {
+		return nil, fmt.Errorf("%s: save state: %w", key, err)
+	}
+	return events, nil
+}
+
+// track compares the latest item with the one in prev and reports what
+// changed. When the latest item is a new one, the items listed before
+// the previous one are reported too, oldest first; when the previous one
+// is gone, only those that sort above it as versions, so that falling back
+// to an older release is not taken for a new one.
+func track[T any](ctx context.Context, t trackObj[T], prev []byte, backlog int, emit func(EventObj)) ([]byte, error) {
+	cur, err := t.latest()
+	have := err == nil
+	if err != nil && !errors.Is(err, lightweigit.ErrNotFound) {
+		return prev, err
+	}
+
+	var old T
+	known := false
+	if len(prev) > 0 {
+		// A blob no registered provider reads any more starts over.
+		if old, err = t.unmarshal(prev); err == nil {
+			known = true
+		}
+	}
+
+	switch {
+	case !known:
+		if have {
+			emit(t.event(EventCreated, cur, old))
+		}
+
+	case have && t.name(cur) == t.name(old):
+		if t.changed(old, cur) {
+			emit(t.event(EventUpdated, cur, old))
+		}
+
+	default:
+		oldName := t.name(old)
+		newer, found, err := since(ctx, t, oldName, backlog)
+		if err != nil {
+			return prev, err
+		}
+		gone := false
+		if !found {
+			if _, err := t.find(oldName); errors.Is(err, lightweigit.ErrNotFound) {
+				gone = true
+			} else if err != nil {
+				return prev, err
+			}
+		}
+
+		if gone {
+			emit(t.event(EventDeleted, old, old))
+		}
+		for i := len(newer) - 1; i >= 0; i-- {
+			if gone && lightweigit.CompareVersions(t.name(newer[i]), oldName) <= 0 {
+				continue
+			}
+			emit(t.event(EventCreated, newer[i], old))
+		}
+	}
+
+	if !have {
+		return nil, nil
+	}
+	return t.marshal(cur), nil
+}
+
+// since lists the items the provider lists before the one named stop, at
+// most limit of them, newest first. found reports that stop was reached;
+// it is false as well when the limit cut the listing short.
+func since[T any](ctx context.Context, t trackObj[T], stop string, limit int) ([]T, bool, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := make(chan T)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- t.stream(streamCtx, out, 0)
+		close(out)
+	}()
+
+	var list []T
+	found, stopped := false, false
+	for it := range out {
+		if stopped {
+			continue
+		}
+		switch {
+		case t.name(it) == stop:
+			found = true
+		case t.skip != nil && t.skip(it):
+			continue
+		default:
+			list = append(list, it)
+			if len(list) < limit {
+				continue
+			}
+		}
+		stopped = true
+		cancel()
+	}
+
+	if err := <-errc; err != nil && !(stopped && errors.Is(err, context.Canceled)) {
+		return nil, false, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	return list, found, nil
+}
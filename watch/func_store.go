@@ -0,0 +1,95 @@
+package watch
+
+import (
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+func NewMemoryStore() *MemoryStoreObj {
+	return &MemoryStoreObj{data: make(map[string][]byte)}
+}
+
+func (s *MemoryStoreObj) Load(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key], nil
+}
+
+func (s *MemoryStoreObj) Save(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// //
+
+// NewDirStore keeps the state in dir, which is created on the first Save.
+func NewDirStore(dir string) *DirStoreObj {
+	return &DirStoreObj{dir: dir}
+}
+
+// path escapes the key, which holds ':' and '/', into one file name.
+func (s *DirStoreObj) path(key string) string {
+	return filepath.Join(s.dir, url.QueryEscape(key)+".state")
+}
+
+func (s *DirStoreObj) Load(key string) ([]byte, error) {
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// Save writes through a temporary file and a rename, so a crash never
+// leaves a torn state behind.
+func (s *DirStoreObj) Save(key string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, ".state-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// // // //
+
+func (st *stateObj) marshal() []byte {
+	return lightweigit.Marshal(target.ModWatchState, st)
+}
+
+func unmarshalState(data []byte) (*stateObj, error) {
+	st := new(stateObj)
+	if len(data) == 0 {
+		return st, nil
+	}
+	mod, err := lightweigit.Unmarshal(data, st)
+	if err != nil {
+		return nil, err
+	}
+	if mod != target.ModWatchState {
+		return nil, ErrBadState
+	}
+	return st, nil
+}
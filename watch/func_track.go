@@ -0,0 +1,105 @@
+package watch
+
+import (
+	"context"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/snapshot"
+	"github.com/voluminor/lightweigit-loader/target"
+)
+
+// // // // // // // // // // // // // // // //
+
+// unmarshalRelease reads a release blob of any registered provider or of
+// a snapshot, which stays outside the registry.
+func unmarshalRelease(data []byte) (lightweigit.ProviderReleaseInterface, error) {
+	if len(data) > 0 && target.ModType(data[0]) == target.ModSnapshot {
+		return snapshot.UnmarshalRelease(data)
+	}
+	return lightweigit.Registry.UnmarshalRelease(data)
+}
+
+func unmarshalTag(data []byte) (lightweigit.ProviderTagInterface, error) {
+	if len(data) > 0 && target.ModType(data[0]) == target.ModSnapshot {
+		return snapshot.UnmarshalTag(data)
+	}
+	return lightweigit.Registry.UnmarshalTag(data)
+}
+
+// releaseChanged compares what a release announcement shows: the title,
+// the notes, the pre-release flag and the assets.
+func releaseChanged(old, cur lightweigit.ProviderReleaseInterface) bool {
+	if old.Name() != cur.Name() || old.BodyMD() != cur.BodyMD() || old.IsPrerelease() != cur.IsPrerelease() {
+		return true
+	}
+	a, b := old.Assets(), cur.Assets()
+	if len(a) != len(b) {
+		return true
+	}
+	for i := range a {
+		if a[i].Name() != b[i].Name() || a[i].Size() != b[i].Size() {
+			return true
+		}
+	}
+	return false
+}
+
+func releaseName(r lightweigit.ProviderReleaseInterface) string {
+	if t := r.Tag(); t != nil && t.String() != "" {
+		return t.String()
+	}
+	return r.Name()
+}
+
+// //
+
+func (w *WatcherObj) releases(p lightweigit.ProviderInterface) trackObj[lightweigit.ProviderReleaseInterface] {
+	return trackObj[lightweigit.ProviderReleaseInterface]{
+		latest: p.ReleaseLatest,
+		find:   p.ReleaseFind,
+		stream: func(ctx context.Context, out chan lightweigit.ProviderReleaseInterface, limit int) error {
+			return p.ReleasesStream(ctx, out, limit)
+		},
+		name: releaseName,
+		skip: func(r lightweigit.ProviderReleaseInterface) bool {
+			return r.IsPrerelease()
+		},
+		marshal: func(r lightweigit.ProviderReleaseInterface) []byte {
+			return r.Marshal()
+		},
+		unmarshal: unmarshalRelease,
+		changed:   releaseChanged,
+		event: func(typ EventType, item, previous lightweigit.ProviderReleaseInterface) EventObj {
+			e := EventObj{Type: typ, Provider: p, Release: item, Tag: item.Tag()}
+			if typ == EventUpdated {
+				e.Previous = previous
+			}
+			return e
+		},
+	}
+}
+
+// tags never report EventUpdated: a tag handle carries nothing but its
+// name, so a moved tag looks the same.
+func (w *WatcherObj) tags(p lightweigit.ProviderInterface) trackObj[lightweigit.ProviderTagInterface] {
+	return trackObj[lightweigit.ProviderTagInterface]{
+		latest: p.TagLatest,
+		find:   p.TagFind,
+		stream: func(ctx context.Context, out chan lightweigit.ProviderTagInterface, limit int) error {
+			return p.TagsStream(ctx, out, limit)
+		},
+		name: func(t lightweigit.ProviderTagInterface) string {
+			return t.String()
+		},
+		marshal: func(t lightweigit.ProviderTagInterface) []byte {
+			return t.Marshal()
+		},
+		unmarshal: unmarshalTag,
+		changed: func(old, cur lightweigit.ProviderTagInterface) bool {
+			return false
+		},
+		event: func(typ EventType, item, previous lightweigit.ProviderTagInterface) EventObj {
+			return EventObj{Type: typ, Provider: p, Tag: item}
+		},
+	}
+}
@@ -0,0 +1,144 @@
+package watch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/voluminor/lightweigit-loader"
+)
+
+// // // // // // // // // // // // // // // //
+
+// EventType is what happened to a release or tag between two polls.
+type EventType byte
+
+const (
+	EventCreated EventType = iota
+	EventUpdated
+	EventDeleted
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventCreated:
+		return "created"
+	case EventUpdated:
+		return "updated"
+	case EventDeleted:
+		return "deleted"
+	}
+	return "unknown"
+}
+
+// EventObj is one change seen on a repository. Release events carry the
+// release and its tag; tag events only the tag. Deleted events carry the
+// item as it was last seen, read back from the state store.
+type EventObj struct {
+	Type     EventType
+	Provider lightweigit.ProviderInterface
+	Release  lightweigit.ProviderReleaseInterface
+	Tag      lightweigit.ProviderTagInterface
+
+	// Previous is the release as it was before an EventUpdated.
+	Previous lightweigit.ProviderReleaseInterface
+}
+
+// IsRelease reports whether the event is about a release rather than a
+// bare tag.
+func (e EventObj) IsRelease() bool {
+	return e.Release != nil
+}
+
+// StoreInterface keeps the state of each repository between polls, keyed
+// by lightweigit.IdentityObj.Key. The state is an opaque blob written by
+// lightweigit.Marshal; Load returns nil and no error for an unknown key.
+type StoreInterface interface {
+	Load(key string) ([]byte, error)
+	Save(key string, data []byte) error
+}
+
+// OptionsObj tunes a watcher. The zero value polls every 15 minutes
+// without jitter and keeps its state in memory.
+type OptionsObj struct {
+	// Interval is the time between two polls; Jitter adds a random delay
+	// of up to its value to each wait, so that several bots started
+	// together do not poll in step.
+	Interval time.Duration
+	Jitter   time.Duration
+
+	// Store keeps the last seen release and tag of every repository.
+	Store StoreInterface
+
+	// Initial reports the releases and tags found on the first poll of a
+	// repository as created. By default the first poll only records them.
+	Initial bool
+
+	// Backlog caps how many releases or tags that appeared between two
+	// polls are reported, oldest first. The default is 20.
+	Backlog int
+
+	// MaxBackoff caps the wait after a host answered with a rate-limit
+	// error. The default is an hour.
+	MaxBackoff time.Duration
+
+	// OnError receives the error of a repository whose poll failed. Its
+	// state is left as it was, so nothing is lost; the next poll retries.
+	// A stored state that cannot be read is reported here as well, and
+	// then replaced by a fresh baseline.
+	OnError func(lightweigit.ProviderInterface, error)
+}
+
+// WatcherObj polls a set of repositories for new, changed and deleted
+// releases and tags. Repositories can be added while it runs.
+type WatcherObj struct {
+	opts OptionsObj
+
+	mu      sync.Mutex
+	repos   []lightweigit.ProviderInterface
+	backoff map[string]*backoffObj
+}
+
+// backoffObj is the rate-limit state of one host.
+type backoffObj struct {
+	until time.Time
+	delay time.Duration
+}
+
+// stateObj is what a store keeps for one repository: the lightweigit
+// Marshal blobs of the last seen latest release and tag.
+type stateObj struct {
+	Checked int64
+	Release []byte
+	Tag     []byte
+}
+
+// trackObj adapts releases or tags to the common polling logic.
+type trackObj[T any] struct {
+	latest func() (T, error)
+	find   func(string) (T, error)
+	stream func(context.Context, chan T, int) error
+	name   func(T) string
+
+	// skip leaves items out of the backlog: pre-releases, which the
+	// latest release never is.
+	skip      func(T) bool
+	marshal   func(T) []byte
+	unmarshal func([]byte) (T, error)
+	changed   func(old, cur T) bool
+	event     func(typ EventType, item, previous T) EventObj
+}
+
+// // // //
+
+// MemoryStoreObj is a StoreInterface kept in memory, lost on exit.
+type MemoryStoreObj struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// DirStoreObj is a StoreInterface keeping one file per repository in a
+// directory.
+type DirStoreObj struct {
+	dir string
+}
@@ -0,0 +1,25 @@
+package watch
+
+import (
+	"errors"
+	"time"
+)
+
+// // // // // // // // // // // // // // // //
+
+const (
+	// defaultInterval is the time between two polls of every repository.
+	defaultInterval = 15 * time.Minute
+
+	// defaultMaxBackoff caps how long a rate-limited host is left alone.
+	defaultMaxBackoff = time.Hour
+
+	// defaultBacklog is how many releases or tags that appeared between
+	// two polls are reported at most.
+	defaultBacklog = 20
+)
+
+var (
+	ErrNilProvider = errors.New("nil provider")
+	ErrBadState    = errors.New("invalid watch state")
+)
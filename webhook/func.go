@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/watch"
+
+	// The built-in providers register themselves from their init functions.
+	_ "github.com/voluminor/lightweigit-loader/target/global"
+)
+
+// // // // // // // // // // // // // // // //
+
+func NewHandler(opts OptionsObj) *HandlerObj {
+	if opts.MaxBody <= 0 {
+		opts.MaxBody = defaultMaxBody
+	}
+	return &HandlerObj{opts: opts}
+}
+
+// ServeHTTP answers 204 to an accepted delivery, also one without release
+// or tag events such as a ping, after OnEvent has seen its events.
+// Refused deliveries get 400, 401 or 413 and go to OnError.
+func (h *HandlerObj) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	events, err := h.Parse(r)
+	if err != nil {
+		if h.opts.OnError != nil {
+			h.opts.OnError(r, err)
+		}
+		code := http.StatusBadRequest
+		switch {
+		case errors.Is(err, ErrSignature):
+			code = http.StatusUnauthorized
+		case errors.Is(err, ErrBodyTooLarge):
+			code = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	if h.opts.OnEvent != nil {
+		for _, e := range events {
+			h.opts.OnEvent(e)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Parse reads and checks one delivery and returns its release and tag
+// events, for callers that route deliveries themselves.
+func (h *HandlerObj) Parse(r *http.Request) ([]watch.EventObj, error) {
+	limit := h.opts.MaxBody
+	if limit <= 0 {
+		limit = defaultMaxBody
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("over %d bytes: %w", limit, ErrBodyTooLarge)
+	}
+
+	for _, f := range forges {
+		event, kind := f.event(r.Header)
+		if event == "" {
+			continue
+		}
+
+		d, err := f.parse(event, body)
+		if err != nil {
+			return nil, err
+		}
+		if d.repo == "" {
+			return nil, fmt.Errorf("%s %s: no repository URL: %w", f.family, event, ErrBadPayload)
+		}
+		p, err := provider(f.family, kind, d.repo)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", f.family, event, err)
+		}
+
+		secret, ok := h.opts.Secrets[lightweigit.Identity(p).Key()]
+		if !ok {
+			secret = h.opts.Secret
+		}
+		switch {
+		case secret != "":
+			if !f.verify(r.Header, body, secret) {
+				return nil, fmt.Errorf("%s %s for %s: %w", f.family, event, p, ErrSignature)
+			}
+		case !h.opts.AllowUnsigned:
+			return nil, fmt.Errorf("%s %s for %s: no secret: %w", f.family, event, p, ErrSignature)
+		}
+
+		return events(p, d)
+	}
+	return nil, ErrUnknownForge
+}
+
+// // // //
+
+// provider builds the handle of the repository at raw. The family is
+// known from the headers, so a self-hosted forge is taken as pinned to it
+// rather than probed; a pin in lightweigit.HostMap still gives its API base.
+func provider(family, kind, raw string) (webhookProviderInterface, error) {
+	e, ok := lightweigit.Registry.Lookup(family)
+	if !ok {
+		return nil, fmt.Errorf("provider %q is not registered", family)
+	}
+
+	var (
+		obj lightweigit.ProviderInterface
+		err error
+	)
+	switch {
+	case e.ParsePinned != nil:
+		pin := lightweigit.HostPinObj{Host: lightweigit.HostOf(raw), Provider: family, Kind: kind}
+		if hosts := lightweigit.Registry.Hosts; hosts != nil {
+			if p, ok := hosts.Get(pin.Host); ok && p.Provider == family {
+				pin = p
+			}
+		}
+		obj, err = e.ParsePinned(raw, pin)
+	case e.ParseOffline != nil:
+		obj, err = e.ParseOffline(raw)
+	default:
+		return nil, fmt.Errorf("provider %q cannot parse offline", family)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: repository %q: %v", ErrBadPayload, raw, err)
+	}
+
+	wp, ok := obj.(webhookProviderInterface)
+	if !ok {
+		return nil, fmt.Errorf("%s webhooks: %w", family, lightweigit.ErrUnsupported)
+	}
+	return wp, nil
+}
+
+// events turns the changes of a delivery into events on p.
+func events(p webhookProviderInterface, d *deliveryObj) ([]watch.EventObj, error) {
+	out := make([]watch.EventObj, 0, len(d.changes))
+	for _, c := range d.changes {
+		e := watch.EventObj{Type: c.typ, Provider: p}
+		if c.release != nil {
+			rel, err := p.WebhookRelease(c.release)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrBadPayload, err)
+			}
+			e.Release, e.Tag = rel, rel.Tag()
+		} else {
+			e.Tag = p.WebhookTag(c.tag)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
@@ -0,0 +1,325 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/voluminor/lightweigit-loader/watch"
+)
+
+// // // // // // // // // // // // // // // //
+
+// forges are tried in order. The Gitea family comes before GitHub: Gitea
+// sends X-GitHub-Event too, for compatibility.
+var forges = []forgeObj{
+	{family: "gogsFamily", event: giteaEvent, parse: parseGitea, verify: verifyGitea},
+	{family: "github", event: headerEvent("X-GitHub-Event"), parse: parseGitHub, verify: verifyGitHub},
+	{family: "gitlab", event: headerEvent("X-Gitlab-Event"), parse: parseGitLab, verify: verifyGitLab},
+	{family: "bitbucket", event: headerEvent("X-Event-Key"), parse: parseBitbucket, verify: verifyBitbucket},
+}
+
+func headerEvent(name string) func(http.Header) (string, string) {
+	return func(h http.Header) (string, string) {
+		return h.Get(name), ""
+	}
+}
+
+// checkHMAC compares sig, the hex HMAC-SHA256 of body under secret, in
+// constant time.
+func checkHMAC(sig string, body []byte, secret string) bool {
+	got, err := hex.DecodeString(strings.TrimSpace(sig))
+	if err != nil {
+		return false
+	}
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write(body)
+	return hmac.Equal(got, m.Sum(nil))
+}
+
+func decode(body []byte, v any) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadPayload, err)
+	}
+	return nil
+}
+
+// isNull reports an absent or null JSON value.
+func isNull(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
+
+// isDraft reports a draft release, which polling never sees either.
+func isDraft(raw json.RawMessage) bool {
+	var r struct {
+		Draft bool `json:"draft"`
+	}
+	return json.Unmarshal(raw, &r) == nil && r.Draft
+}
+
+// isZeroSHA reports the all-zero object ID git pushes use for a ref that
+// did not exist before or no longer does.
+func isZeroSHA(sha string) bool {
+	return sha != "" && strings.Trim(sha, "0") == ""
+}
+
+// // // //
+
+type githubHookObj struct {
+	Action     string          `json:"action"`
+	Ref        string          `json:"ref"`
+	Created    bool            `json:"created"`
+	Deleted    bool            `json:"deleted"`
+	Release    json.RawMessage `json:"release"`
+	Repository struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"repository"`
+}
+
+// githubActions maps release actions to events. "created", "released" and
+// "prereleased" accompany "published" and are left out.
+var githubActions = map[string]watch.EventType{
+	"published":   watch.EventCreated,
+	"edited":      watch.EventUpdated,
+	"deleted":     watch.EventDeleted,
+	"unpublished": watch.EventDeleted,
+}
+
+// parseGitHub reads release and push deliveries. Tags come from push
+// only: the create and delete events repeat it and are not sent for more
+// than three tags at once.
+func parseGitHub(event string, body []byte) (*deliveryObj, error) {
+	var p githubHookObj
+	if err := decode(body, &p); err != nil {
+		return nil, err
+	}
+	d := &deliveryObj{repo: p.Repository.HTMLURL}
+
+	switch event {
+	case "release":
+		typ, ok := githubActions[p.Action]
+		if ok && !isNull(p.Release) && !isDraft(p.Release) {
+			d.changes = append(d.changes, changeObj{typ: typ, release: p.Release})
+		}
+	case "push":
+		name := strings.TrimPrefix(p.Ref, "refs/tags/")
+		if name == p.Ref {
+			break
+		}
+		typ := watch.EventUpdated
+		switch {
+		case p.Created:
+			typ = watch.EventCreated
+		case p.Deleted:
+			typ = watch.EventDeleted
+		}
+		d.changes = append(d.changes, changeObj{typ: typ, tag: name})
+	}
+	return d, nil
+}
+
+func verifyGitHub(h http.Header, body []byte, secret string) bool {
+	sig := h.Get("X-Hub-Signature-256")
+	return strings.HasPrefix(sig, "sha256=") && checkHMAC(sig[len("sha256="):], body, secret)
+}
+
+// // // //
+
+// giteaHeaders are the event and signature headers of each flavour.
+var giteaHeaders = []struct{ kind, event, signature string }{
+	{"forgejo", "X-Forgejo-Event", "X-Forgejo-Signature"},
+	{"gitea", "X-Gitea-Event", "X-Gitea-Signature"},
+	{"gogs", "X-Gogs-Event", "X-Gogs-Signature"},
+}
+
+func giteaEvent(h http.Header) (string, string) {
+	for _, g := range giteaHeaders {
+		if e := h.Get(g.event); e != "" {
+			return e, g.kind
+		}
+	}
+	return "", ""
+}
+
+type giteaHookObj struct {
+	Action     string          `json:"action"`
+	Ref        string          `json:"ref"`
+	RefType    string          `json:"ref_type"`
+	Release    json.RawMessage `json:"release"`
+	Repository struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"repository"`
+}
+
+var giteaActions = map[string]watch.EventType{
+	"published": watch.EventCreated,
+	"updated":   watch.EventUpdated,
+	"deleted":   watch.EventDeleted,
+}
+
+// parseGitea reads release, create and delete deliveries. A tag push also
+// arrives as push, which carries no sign of the tag being new.
+func parseGitea(event string, body []byte) (*deliveryObj, error) {
+	var p giteaHookObj
+	if err := decode(body, &p); err != nil {
+		return nil, err
+	}
+	d := &deliveryObj{repo: p.Repository.HTMLURL}
+
+	switch event {
+	case "release":
+		typ, ok := giteaActions[p.Action]
+		if ok && !isNull(p.Release) && !isDraft(p.Release) {
+			d.changes = append(d.changes, changeObj{typ: typ, release: p.Release})
+		}
+	case "create", "delete":
+		if p.RefType != "tag" || p.Ref == "" {
+			break
+		}
+		typ := watch.EventCreated
+		if event == "delete" {
+			typ = watch.EventDeleted
+		}
+		d.changes = append(d.changes, changeObj{typ: typ, tag: strings.TrimPrefix(p.Ref, "refs/tags/")})
+	}
+	return d, nil
+}
+
+func verifyGitea(h http.Header, body []byte, secret string) bool {
+	for _, g := range giteaHeaders {
+		if sig := h.Get(g.signature); sig != "" {
+			return checkHMAC(sig, body, secret)
+		}
+	}
+	return false
+}
+
+// // // //
+
+type gitlabHookObj struct {
+	ObjectKind string `json:"object_kind"`
+	Action     string `json:"action"`
+	Ref        string `json:"ref"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	Project    struct {
+		WebURL string `json:"web_url"`
+	} `json:"project"`
+}
+
+var gitlabActions = map[string]watch.EventType{
+	"create": watch.EventCreated,
+	"update": watch.EventUpdated,
+	"delete": watch.EventDeleted,
+}
+
+// parseGitLab reads Release Hook and Tag Push Hook deliveries. The whole
+// Release Hook payload is the release.
+func parseGitLab(event string, body []byte) (*deliveryObj, error) {
+	var p gitlabHookObj
+	if err := decode(body, &p); err != nil {
+		return nil, err
+	}
+	d := &deliveryObj{repo: p.Project.WebURL}
+
+	switch p.ObjectKind {
+	case "release":
+		if typ, ok := gitlabActions[p.Action]; ok {
+			d.changes = append(d.changes, changeObj{typ: typ, release: body})
+		}
+	case "tag_push":
+		name := strings.TrimPrefix(p.Ref, "refs/tags/")
+		if name == p.Ref {
+			break
+		}
+		typ := watch.EventUpdated
+		switch {
+		case isZeroSHA(p.Before):
+			typ = watch.EventCreated
+		case isZeroSHA(p.After):
+			typ = watch.EventDeleted
+		}
+		d.changes = append(d.changes, changeObj{typ: typ, tag: name})
+	}
+	return d, nil
+}
+
+// verifyGitLab compares the shared token GitLab sends as is.
+func verifyGitLab(h http.Header, body []byte, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(h.Get("X-Gitlab-Token")), []byte(secret)) == 1
+}
+
+// // // //
+
+type bitbucketRefObj struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type bitbucketHookObj struct {
+	Repository struct {
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	} `json:"repository"`
+	Push struct {
+		Changes []struct {
+			New json.RawMessage `json:"new"`
+			Old json.RawMessage `json:"old"`
+		} `json:"changes"`
+	} `json:"push"`
+}
+
+// bitbucketTag is the name of a tag ref of a push change, or "".
+func bitbucketTag(raw json.RawMessage) string {
+	var ref bitbucketRefObj
+	if isNull(raw) || json.Unmarshal(raw, &ref) != nil || ref.Type != "tag" {
+		return ""
+	}
+	return ref.Name
+}
+
+// parseBitbucket reads repo:push deliveries. Every tag is a release on
+// Bitbucket, so a new or deleted tag is reported as both, as polling does;
+// a moved tag only as a tag.
+func parseBitbucket(event string, body []byte) (*deliveryObj, error) {
+	var p bitbucketHookObj
+	if err := decode(body, &p); err != nil {
+		return nil, err
+	}
+	d := &deliveryObj{repo: p.Repository.Links.HTML.Href}
+	if event != "repo:push" {
+		return d, nil
+	}
+
+	for _, c := range p.Push.Changes {
+		added, removed := bitbucketTag(c.New), bitbucketTag(c.Old)
+		switch {
+		case added != "" && removed != "":
+			d.changes = append(d.changes, changeObj{typ: watch.EventUpdated, tag: added})
+		case added != "":
+			d.changes = append(d.changes,
+				changeObj{typ: watch.EventCreated, release: c.New},
+				changeObj{typ: watch.EventCreated, tag: added})
+		case removed != "":
+			d.changes = append(d.changes,
+				changeObj{typ: watch.EventDeleted, release: c.Old},
+				changeObj{typ: watch.EventDeleted, tag: removed})
+		}
+	}
+	return d, nil
+}
+
+func verifyBitbucket(h http.Header, body []byte, secret string) bool {
+	sig := h.Get("X-Hub-Signature")
+	return strings.HasPrefix(sig, "sha256=") && checkHMAC(sig[len("sha256="):], body, secret)
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/voluminor/lightweigit-loader"
+	"github.com/voluminor/lightweigit-loader/watch"
+)
+
+// // // // // // // // // // // // // // // //
+
+// OptionsObj configures a HandlerObj.
+type OptionsObj struct {
+	// Secret is the webhook secret set on the forge. Secrets overrides it
+	// per repository, keyed by lightweigit.IdentityObj.Key.
+	Secret  string
+	Secrets map[string]string
+
+	// AllowUnsigned accepts deliveries for repositories without a secret.
+	// Otherwise they are refused, as a forged delivery would be.
+	AllowUnsigned bool
+
+	// MaxBody caps the request body; the default is 25 MiB.
+	MaxBody int64
+
+	// OnEvent receives each event of an accepted delivery, in the order of
+	// the payload, before the forge gets its answer.
+	OnEvent func(watch.EventObj)
+
+	// OnError receives the reason a delivery was refused.
+	OnError func(*http.Request, error)
+}
+
+// HandlerObj is an http.Handler receiving release and tag webhooks of
+// GitHub, GitLab, Gitea / Forgejo / Gogs and Bitbucket. The events use the
+// model of watch.WatcherObj, so one consumer serves both.
+type HandlerObj struct {
+	opts OptionsObj
+}
+
+// //
+
+// forgeObj reads the deliveries of one forge family.
+type forgeObj struct {
+	family string
+
+	// event returns the event name of a delivery and the flavour of the
+	// forge, or "" when the delivery is not from this forge.
+	event func(h http.Header) (event, kind string)
+
+	parse  func(event string, body []byte) (*deliveryObj, error)
+	verify func(h http.Header, body []byte, secret string) bool
+}
+
+// deliveryObj is a decoded delivery whose signature is not checked yet.
+type deliveryObj struct {
+	repo    string
+	changes []changeObj
+}
+
+// changeObj is one event of a delivery. release is the payload part the
+// provider's WebhookRelease reads; nil for bare tag events.
+type changeObj struct {
+	typ     watch.EventType
+	tag     string
+	release json.RawMessage
+}
+
+// webhookProviderInterface is a provider able to build handles from
+// deliveries.
+type webhookProviderInterface interface {
+	lightweigit.ProviderInterface
+	lightweigit.ProviderWebhookInterface
+}
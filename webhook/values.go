@@ -0,0 +1,17 @@
+package webhook
+
+import (
+	"errors"
+)
+
+// // // // // // // // // // // // // // // //
+
+// defaultMaxBody is GitHub's own cap on a delivery.
+const defaultMaxBody = 25 << 20
+
+var (
+	ErrUnknownForge = errors.New("not a webhook delivery of a known forge")
+	ErrSignature    = errors.New("webhook signature mismatch")
+	ErrBadPayload   = errors.New("malformed webhook payload")
+	ErrBodyTooLarge = errors.New("webhook body too large")
+)